@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/http"
+	"project_sdu/model"
+	"project_sdu/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccessLogAPI interface {
+	GetAll(c *gin.Context)
+}
+
+type accessLogAPI struct {
+	accessLogService service.AccessLogService
+}
+
+func NewAccessLogAPI(accessLogService service.AccessLogService) AccessLogAPI {
+	return &accessLogAPI{accessLogService}
+}
+
+// unmaskRequested tells whether the caller asked for sensitive fields with
+// ?unmask=true. Without the permission it answers 403 and returns ok false.
+func unmaskRequested(c *gin.Context) (unmask bool, ok bool) {
+	if c.Query("unmask") != "true" {
+		return false, true
+	}
+
+	role, _ := c.Get("role")
+	if r, _ := role.(model.UserRole); !r.Can(model.PermissionViewSensitiveData) {
+		c.JSON(http.StatusForbidden, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusForbidden,
+			Message: "You are not allowed to view unmasked data",
+		})
+		return false, false
+	}
+	return true, true
+}
+
+// recordUnmaskedAccess logs an unmasked view or export. On failure it
+// answers 500, so unmasked data is never handed out without a log entry.
+func recordUnmaskedAccess(c *gin.Context, accessLogService service.AccessLogService, action string, resource string, resourceID int) bool {
+	err := accessLogService.Record(&model.SensitiveDataAccessLog{
+		UserID:     c.GetInt("id"),
+		Resource:   resource,
+		Action:     action,
+		ResourceID: resourceID,
+		IPAddress:  c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to record access to sensitive data",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return false
+	}
+	return true
+}
+
+// ====================
+// GET ALL ACCESS LOGS
+// ====================
+func (a *accessLogAPI) GetAll(c *gin.Context) {
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+	resourceID, _ := strconv.Atoi(c.Query("resource_id"))
+
+	entries, meta, err := a.accessLogService.GetAll(page, c.Query("resource"), resourceID)
+	if err != nil {
+		listFailed(c, err, "Failed to fetch access logs")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Access logs fetched successfully",
+		Data:    model.NewAccessLogResponses(entries),
+		Meta:    meta,
+	})
+}
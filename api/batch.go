@@ -1,11 +1,13 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"project_sdu/model"
+	"project_sdu/repository"
 	"project_sdu/service"
+	"project_sdu/validation"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,7 +15,12 @@ import (
 type BatchAPI interface {
 	Create(c *gin.Context)
 	Update(c *gin.Context)
+	Patch(c *gin.Context)
 	Delete(c *gin.Context)
+	PreviewDelete(c *gin.Context)
+	GetTrash(c *gin.Context)
+	Restore(c *gin.Context)
+	Purge(c *gin.Context)
 	GetByID(c *gin.Context)
 	GetAll(c *gin.Context)
 	GetActiveBatch(c *gin.Context)
@@ -31,60 +38,173 @@ func NewBatchAPI(batchService service.BatchService) *batchAPI {
 // CREATE
 // ====================
 func (b *batchAPI) Create(c *gin.Context) {
-	var batch model.Batch
-	if err := c.ShouldBindJSON(&batch); err != nil {
+	var req model.BatchRequest
+	if !bindJSON(c, &req, validation.LangEN) {
+		return
+	}
+
+	batch := req.ToModel()
+	if err := b.batchService.Create(batch); err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to create batch",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusCreated,
+		Message: "Batch created successfully",
+		Data:    model.NewBatchResponse(batch),
+	})
+}
+
+// ====================
+// UPDATE
+// ====================
+func (b *batchAPI) Update(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors:  map[string]string{"body": "Invalid JSON format"},
+			Message: "Invalid ID",
 		})
 		return
 	}
 
-	// Validation minimal
-	if batch.Name == "" {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	var req model.BatchRequest
+	if !bindJSON(c, &req, validation.LangEN) {
+		return
+	}
+
+	batch := req.ToModel()
+	batch.Version = version
+	if err := b.batchService.Update(id, batch); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			b.conflict(c, id)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to update batch",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	setETag(c, batch.Version)
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Batch updated successfully",
+		Data:    model.NewBatchResponse(batch),
+	})
+}
+
+// ====================
+// PATCH
+// ====================
+func (b *batchAPI) Patch(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors:  map[string]string{"name": "name is required"},
+			Message: "Invalid ID",
 		})
 		return
 	}
 
-	if batch.Jalur == "" {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	patch, ok := bindPatch(c, validation.LangEN)
+	if !ok {
+		return
+	}
+
+	batch, err := b.batchService.Patch(id, version, patch)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		b.conflict(c, id)
+		return
+	}
+	if err != nil {
+		patchFailed(c, err, validation.LangEN, "Batch not found", "Failed to update batch")
+		return
+	}
+
+	setETag(c, batch.Version)
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Batch updated successfully",
+		Data:    model.NewBatchResponse(batch),
+	})
+}
+
+// ====================
+// DELETE
+// ====================
+func (b *batchAPI) Delete(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors:  map[string]string{"jalur": "jalur is required"},
+			Message: "Invalid ID",
 		})
 		return
 	}
 
-	if err := b.batchService.Create(&batch); err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+	reassignTo, ok := reassignTarget(c)
+	if !ok {
+		return
+	}
+
+	impact, err := b.batchService.Delete(id, reassignTo)
+	if errors.Is(err, service.ErrBatchHasStudents) {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Success: false,
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to create batch",
-			Errors:  map[string]string{"server": err.Error()},
+			Status:  http.StatusBadRequest,
+			Message: "Batch cannot be deleted because it has associated students",
+			Errors:  map[string]string{"reassign_to": "pass the batch its students move to"},
+			Data:    impact,
 		})
 		return
 	}
+	if err != nil {
+		deleteFailed(c, err, "Batch not found", "Failed to delete batch")
+		return
+	}
 
-	c.JSON(http.StatusCreated, model.SuccessResponse{
+	c.JSON(http.StatusOK, model.SuccessResponse{
 		Success: true,
-		Status:  http.StatusCreated,
-		Message: "Batch created successfully",
-		Data:    batch,
+		Status:  http.StatusOK,
+		Message: "Batch deleted successfully",
+		Data:    impact,
 	})
 }
 
 // ====================
-// UPDATE
+// DELETE PREVIEW
 // ====================
-func (b *batchAPI) Update(c *gin.Context) {
+func (b *batchAPI) PreviewDelete(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
@@ -96,39 +216,73 @@ func (b *batchAPI) Update(c *gin.Context) {
 		return
 	}
 
-	var batch model.Batch
-	if err := c.ShouldBindJSON(&batch); err != nil {
+	reassignTo, ok := reassignTarget(c)
+	if !ok {
+		return
+	}
+
+	impact, err := b.batchService.PreviewDelete(id, reassignTo)
+	if err != nil {
+		deleteFailed(c, err, "Batch not found", "Failed to preview batch deletion")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Batch deletion previewed successfully",
+		Data:    impact,
+	})
+}
+
+// reassignTarget reads the optional reassign_to query parameter, the batch
+// that students of a deleted batch move to.
+func reassignTarget(c *gin.Context) (*int, bool) {
+	param := c.Query("reassign_to")
+	if param == "" {
+		return nil, true
+	}
+
+	id, err := strconv.Atoi(param)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusBadRequest,
-			Message: "Invalid JSON format",
-			Errors:  map[string]string{"body": err.Error()},
+			Message: "Invalid reassign_to",
 		})
+		return nil, false
+	}
+	return &id, true
+}
+
+// ====================
+// GET TRASHED BATCHES
+// ====================
+func (b *batchAPI) GetTrash(c *gin.Context) {
+	page, ok := pageRequest(c)
+	if !ok {
 		return
 	}
 
-	if err := b.batchService.Update(id, &batch); err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to update batch",
-			Errors:  map[string]string{"server": err.Error()},
-		})
+	batches, meta, err := b.batchService.GetTrash(page)
+	if err != nil {
+		listFailed(c, err, "Failed to retrieve trashed batches")
 		return
 	}
 
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Success: true,
 		Status:  http.StatusOK,
-		Message: "Batch updated successfully",
-		Data:    batch,
+		Message: "Trashed batches retrieved successfully",
+		Data:    model.NewBatchResponses(batches),
+		Meta:    meta,
 	})
 }
 
 // ====================
-// DELETE
+// RESTORE BATCH
 // ====================
-func (b *batchAPI) Delete(c *gin.Context) {
+func (b *batchAPI) Restore(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
@@ -140,29 +294,45 @@ func (b *batchAPI) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := b.batchService.Delete(id); err != nil {
-		if strings.Contains(err.Error(), "violates foreign key constraint") {
-			c.JSON(http.StatusBadRequest, model.ErrorResponse{
-				Success: false,
-				Status:  http.StatusBadRequest,
-				Message: "Batch cannot be deleted because it has associated students",
-			})
-			return
-		}
+	batch, err := b.batchService.Restore(id)
+	if err != nil {
+		trashFailed(c, err, "Batch not found in trash", "Failed to restore batch")
+		return
+	}
 
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+	setETag(c, batch.Version)
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Batch restored successfully",
+		Data:    model.NewBatchResponse(batch),
+	})
+}
+
+// ====================
+// PURGE BATCH
+// ====================
+func (b *batchAPI) Purge(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Success: false,
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to delete batch",
-			Errors:  map[string]string{"server": err.Error()},
+			Status:  http.StatusBadRequest,
+			Message: "Invalid ID",
 		})
 		return
 	}
 
+	if err := b.batchService.Purge(id); err != nil {
+		trashFailed(c, err, "Batch not found in trash", "Failed to permanently delete batch")
+		return
+	}
+
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Success: true,
 		Status:  http.StatusOK,
-		Message: "Batch deleted successfully",
+		Message: "Batch permanently deleted",
 	})
 }
 
@@ -192,11 +362,12 @@ func (b *batchAPI) GetByID(c *gin.Context) {
 		return
 	}
 
+	setETag(c, batch.Version)
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Batch retrieved successfully",
-		Data:    batch,
+		Data:    model.NewBatchResponse(batch),
 	})
 }
 
@@ -204,21 +375,15 @@ func (b *batchAPI) GetByID(c *gin.Context) {
 // GET ALL
 // ====================
 func (b *batchAPI) GetAll(c *gin.Context) {
-	limitParam := c.DefaultQuery("limit", "10")
-	pageParam := c.DefaultQuery("page", "1")
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
 	q := c.Query("q")
 
-	limit, _ := strconv.Atoi(limitParam)
-	page, _ := strconv.Atoi(pageParam)
-
-	data, err := b.batchService.GetAll(limit, page, q)
+	data, meta, err := b.batchService.GetAll(page, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to retrieve batches",
-			Errors:  map[string]string{"server": err.Error()},
-		})
+		listFailed(c, err, "Failed to retrieve batches")
 		return
 	}
 
@@ -226,11 +391,8 @@ func (b *batchAPI) GetAll(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Batch list retrieved successfully",
-		Data:    data,
-		Meta: gin.H{
-			"page":  page,
-			"limit": limit,
-		},
+		Data:    model.NewBatchResponses(data),
+		Meta:    meta,
 	})
 }
 
@@ -264,6 +426,22 @@ func (b *batchAPI) GetActiveBatch(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Batch retrieved successfully",
-		Data:    batch,
+		Data:    model.NewBatchResponse(batch),
 	})
 }
+
+// conflict answers a stale If-Match with the batch as it is now.
+func (b *batchAPI) conflict(c *gin.Context, id int) {
+	current, err := b.batchService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve batch",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	versionConflict(c, current.Version, model.NewBatchResponse(current))
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"project_sdu/model"
+	"project_sdu/service"
+	"project_sdu/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// requestLang is the language for validation messages: the Accept-Language
+// header, or fallback when it names no supported language. Admin endpoints
+// fall back to English, the public PPDB ones to Indonesian.
+func requestLang(c *gin.Context, fallback validation.Lang) validation.Lang {
+	return validation.ParseLang(c.GetHeader("Accept-Language"), fallback)
+}
+
+// bindJSON binds and validates the request body. On failure it responds with
+// 400 and localized field errors, and returns false.
+func bindJSON(c *gin.Context, req interface{}, fallback validation.Lang) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		lang := requestLang(c, fallback)
+		validationFailed(c, validation.BindingErrors(err, lang), lang)
+		return false
+	}
+	return true
+}
+
+// bindJSONList binds a JSON array and validates every element. Errors are
+// keyed as [index].field, which gin's own slice validation does not report.
+func bindJSONList[T any](c *gin.Context, fallback validation.Lang) ([]T, bool) {
+	lang := requestLang(c, fallback)
+
+	var items []T
+	if err := json.NewDecoder(c.Request.Body).Decode(&items); err != nil {
+		validationFailed(c, validation.BindingErrors(err, lang), lang)
+		return nil, false
+	}
+
+	errs := make(map[string]string)
+	for i := range items {
+		if err := binding.Validator.ValidateStruct(&items[i]); err != nil {
+			validation.CollectBindingErrors(err, fmt.Sprintf("[%d].", i), lang, errs)
+		}
+	}
+	if len(errs) > 0 {
+		validationFailed(c, errs, lang)
+		return nil, false
+	}
+	return items, true
+}
+
+// bindPatch reads a JSON merge patch body. The body must be an object; a
+// null member means the field is cleared.
+func bindPatch(c *gin.Context, fallback validation.Lang) (service.Patch, bool) {
+	var patch service.Patch
+	if err := json.NewDecoder(c.Request.Body).Decode(&patch); err != nil || patch == nil {
+		lang := requestLang(c, fallback)
+		validationFailed(c, map[string]string{"body": validation.Text(lang, "invalid_json")}, lang)
+		return nil, false
+	}
+	return patch, true
+}
+
+// patchFailed responds to an error returned by a service Patch method: 400
+// for rejected fields or an invalid merged result, 404 for a missing row.
+func patchFailed(c *gin.Context, err error, fallback validation.Lang, notFound string, failed string) {
+	lang := requestLang(c, fallback)
+
+	var fieldErrs service.FieldErrors
+	switch {
+	case errors.As(err, &fieldErrs):
+		validationFailed(c, validation.Localize(fieldErrs, lang), lang)
+
+	case validation.IsBindingError(err):
+		validationFailed(c, validation.BindingErrors(err, lang), lang)
+
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusNotFound,
+			Message: notFound,
+		})
+
+	default:
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: failed,
+			Errors:  map[string]string{"server": err.Error()},
+		})
+	}
+}
+
+// validationFailed responds with 400 and the given field errors.
+func validationFailed(c *gin.Context, errs map[string]string, lang validation.Lang) {
+	c.JSON(http.StatusBadRequest, model.ErrorResponse{
+		Success: false,
+		Status:  http.StatusBadRequest,
+		Message: validation.Text(lang, "validation_failed"),
+		Errors:  errs,
+	})
+}
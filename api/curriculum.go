@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"project_sdu/model"
 	"project_sdu/service"
+	"project_sdu/validation"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -12,6 +13,7 @@ import (
 type CurriculumAPI interface {
 	Create(c *gin.Context)
 	Update(c *gin.Context)
+	Patch(c *gin.Context)
 	Delete(c *gin.Context)
 	GetByID(c *gin.Context)
 	GetAll(c *gin.Context)
@@ -30,28 +32,13 @@ func NewCurriculumAPI(curriculumService service.CurriculumService) *curriculumAP
 // CREATE
 // ====================
 func (e *curriculumAPI) Create(c *gin.Context) {
-	var ex model.Curriculum
-	if err := c.ShouldBindJSON(&ex); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors:  map[string]string{"body": "Invalid JSON format"},
-		})
+	var req model.CurriculumRequest
+	if !bindJSON(c, &req, validation.LangEN) {
 		return
 	}
 
-	if ex.Name == "" {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors:  map[string]string{"name": "name is required"},
-		})
-		return
-	}
-
-	if err := e.curriculumService.Create(&ex); err != nil {
+	ex := req.ToModel()
+	if err := e.curriculumService.Create(ex); err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -65,7 +52,7 @@ func (e *curriculumAPI) Create(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusCreated,
 		Message: "curriculum created successfully",
-		Data:    ex,
+		Data:    model.NewCurriculumResponse(ex),
 	})
 }
 
@@ -84,18 +71,13 @@ func (e *curriculumAPI) Update(c *gin.Context) {
 		return
 	}
 
-	var ex model.Curriculum
-	if err := c.ShouldBindJSON(&ex); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Invalid JSON format",
-			Errors:  map[string]string{"body": err.Error()},
-		})
+	var req model.CurriculumRequest
+	if !bindJSON(c, &req, validation.LangEN) {
 		return
 	}
 
-	if err := e.curriculumService.Update(id, &ex); err != nil {
+	ex := req.ToModel()
+	if err := e.curriculumService.Update(id, ex); err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -109,7 +91,41 @@ func (e *curriculumAPI) Update(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "curriculum updated successfully",
-		Data:    ex,
+		Data:    model.NewCurriculumResponse(ex),
+	})
+}
+
+// ====================
+// PATCH
+// ====================
+func (e *curriculumAPI) Patch(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	patch, ok := bindPatch(c, validation.LangEN)
+	if !ok {
+		return
+	}
+
+	ex, err := e.curriculumService.Patch(id, patch)
+	if err != nil {
+		patchFailed(c, err, validation.LangEN, "curriculum not found", "Failed to update curriculum")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "curriculum updated successfully",
+		Data:    model.NewCurriculumResponse(ex),
 	})
 }
 
@@ -175,7 +191,7 @@ func (e *curriculumAPI) GetByID(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "curriculum retrieved successfully",
-		Data:    ex,
+		Data:    model.NewCurriculumResponse(ex),
 	})
 }
 
@@ -183,36 +199,28 @@ func (e *curriculumAPI) GetByID(c *gin.Context) {
 // GET ALL
 // ====================
 func (e *curriculumAPI) GetAll(c *gin.Context) {
-	limitParam := c.DefaultQuery("limit", "10")
-	pageParam := c.DefaultQuery("page", "1")
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
 	category := c.Query("category")
 
-	limit, _ := strconv.Atoi(limitParam)
-	page, _ := strconv.Atoi(pageParam)
-
 	var data []model.Curriculum
+	var meta *model.PageMeta
 	var err error
 
 	if category != "" {
-		data, err = e.curriculumService.GetByCategory(category, limit, page)
+		data, meta, err = e.curriculumService.GetByCategory(category, page)
 	} else {
-		data, err = e.curriculumService.GetAll(limit, page)
+		data, meta, err = e.curriculumService.GetAll(page)
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to retrieve curriculum",
-			Errors:  map[string]string{"server": err.Error()},
-		})
+		listFailed(c, err, "Failed to retrieve curriculum")
 		return
 	}
 
-	responseMeta := gin.H{
-		"page":  page,
-		"limit": limit,
-	}
+	responseMeta := pageMeta(meta, nil)
 
 	if category != "" {
 		responseMeta["category"] = category
@@ -222,7 +230,7 @@ func (e *curriculumAPI) GetAll(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "curriculum retrieved successfully",
-		Data:    data,
+		Data:    model.NewCurriculumResponses(data),
 		Meta:    responseMeta,
 	})
 }
@@ -242,20 +250,14 @@ func (e *curriculumAPI) GetByCategory(c *gin.Context) {
 		return
 	}
 
-	limitParam := c.DefaultQuery("limit", "10")
-	pageParam := c.DefaultQuery("page", "1")
-
-	limit, _ := strconv.Atoi(limitParam)
-	page, _ := strconv.Atoi(pageParam)
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
 
-	data, err := e.curriculumService.GetByCategory(category, limit, page)
+	data, meta, err := e.curriculumService.GetByCategory(category, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to retrieve curriculum by category",
-			Errors:  map[string]string{"server": err.Error()},
-		})
+		listFailed(c, err, "Failed to retrieve curriculum by category")
 		return
 	}
 
@@ -263,11 +265,9 @@ func (e *curriculumAPI) GetByCategory(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "curriculum by category retrieved successfully",
-		Data:    data,
-		Meta: gin.H{
-			"page":     page,
-			"limit":    limit,
+		Data:    model.NewCurriculumResponses(data),
+		Meta: pageMeta(meta, gin.H{
 			"category": category,
-		},
+		}),
 	})
 }
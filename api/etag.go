@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"project_sdu/model"
+	"project_sdu/repository"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag sends the row version as the ETag, to be echoed in If-Match.
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatchVersion reads the version the client last saw from If-Match. It
+// responds 428 when the header is missing, 400 when it is not an ETag sent
+// by this API, and returns false in both cases.
+func ifMatchVersion(c *gin.Context) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusPreconditionRequired,
+			Message: "If-Match header is required",
+			Errors:  map[string]string{"if_match": "send the ETag of the record you are editing"},
+		})
+		return 0, false
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.Atoi(tag)
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid If-Match header",
+			Errors:  map[string]string{"if_match": "expected an ETag such as \"3\""},
+		})
+		return 0, false
+	}
+
+	return version, true
+}
+
+// versionConflict responds 412 with the current state of the record, so the
+// client can reapply its edit and retry with the new ETag.
+func versionConflict(c *gin.Context, version int, current interface{}) {
+	setETag(c, version)
+	c.JSON(http.StatusPreconditionFailed, model.ErrorResponse{
+		Success: false,
+		Status:  http.StatusPreconditionFailed,
+		Message: "Record was modified by someone else",
+		Errors:  map[string]string{"if_match": repository.ErrVersionConflict.Error()},
+		Data:    current,
+	})
+}
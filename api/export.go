@@ -0,0 +1,173 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"project_sdu/model"
+	"project_sdu/service"
+	"project_sdu/spreadsheet"
+	"project_sdu/validation"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportAPI interface {
+	ExportStudents(c *gin.Context)
+	ExportDapodik(c *gin.Context)
+	DapodikReport(c *gin.Context)
+}
+
+type exportAPI struct {
+	exportService    service.ExportService
+	accessLogService service.AccessLogService
+}
+
+func NewExportAPI(exportService service.ExportService, accessLogService service.AccessLogService) ExportAPI {
+	return &exportAPI{exportService, accessLogService}
+}
+
+// ====================
+// EXPORT STUDENTS (CSV / XLSX)
+// ====================
+func (a *exportAPI) ExportStudents(c *gin.Context) {
+	// The same filters and sort as GET /student/get-all
+	filter, errs := model.ParseStudentFilter(c.Request.URL.Query())
+	if errs != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid filter",
+			Errors:  errs,
+		})
+		return
+	}
+
+	format, contentType, ok := spreadsheetFormat(c)
+	if !ok {
+		return
+	}
+
+	// e.g. columns=full_name,gender,parent.father_name,batch.name
+	columns, err := service.ParseExportColumns(c.Query("columns"))
+	var fieldErrs service.FieldErrors
+	if errors.As(err, &fieldErrs) {
+		lang := requestLang(c, validation.LangEN)
+		validationFailed(c, validation.Localize(fieldErrs, lang), lang)
+		return
+	}
+
+	// Masked like the list unless a user allowed to see sensitive data asks
+	// for ?unmask=true; the export is logged without a single student ID
+	unmask, ok := unmaskRequested(c)
+	if !ok {
+		return
+	}
+	if unmask && !recordUnmaskedAccess(c, a.accessLogService, "export", "students", 0) {
+		return
+	}
+
+	attachment(c, contentType, fmt.Sprintf("pendaftar-%s.%s", time.Now().Format("20060102"), format))
+	if err := a.exportService.ExportStudents(c.Writer, format, filter, columns, unmask); err != nil {
+		abortDownload(c, err)
+	}
+}
+
+// ====================
+// EXPORT DAPODIK
+// ====================
+func (a *exportAPI) ExportDapodik(c *gin.Context) {
+	// Only accepted students are exported, e.g. ?batch=3 narrows them down
+	filter, errs := model.ParseStudentFilter(c.Request.URL.Query())
+	if errs != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid filter",
+			Errors:  errs,
+		})
+		return
+	}
+
+	format, contentType, ok := spreadsheetFormat(c)
+	if !ok {
+		return
+	}
+
+	// Dapodik needs the full NIK and NISN, so the export is never masked
+	if !recordUnmaskedAccess(c, a.accessLogService, "export", "students", 0) {
+		return
+	}
+
+	attachment(c, contentType, fmt.Sprintf("dapodik-peserta-didik-%s.%s", time.Now().Format("20060102"), format))
+	if err := a.exportService.ExportDapodik(c.Writer, format, filter); err != nil {
+		abortDownload(c, err)
+	}
+}
+
+// ====================
+// DAPODIK MISSING FIELDS REPORT
+// ====================
+func (a *exportAPI) DapodikReport(c *gin.Context) {
+	filter, errs := model.ParseStudentFilter(c.Request.URL.Query())
+	if errs != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid filter",
+			Errors:  errs,
+		})
+		return
+	}
+
+	report, err := a.exportService.DapodikReport(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to check students for Dapodik",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Dapodik report generated successfully",
+		Data:    report,
+	})
+}
+
+// spreadsheetContentTypes are the content types of downloaded files by format.
+var spreadsheetContentTypes = map[string]string{
+	spreadsheet.FormatCSV:  "text/csv; charset=utf-8",
+	spreadsheet.FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// spreadsheetFormat reads ?format=csv|xlsx, XLSX by default. On an unknown
+// format it responds with 400 and returns false.
+func spreadsheetFormat(c *gin.Context) (string, string, bool) {
+	format := c.DefaultQuery("format", spreadsheet.FormatXLSX)
+	contentType, ok := spreadsheetContentTypes[format]
+	if !ok {
+		validationFailed(c, map[string]string{"format": service.ErrFileFormat.Error()}, requestLang(c, validation.LangEN))
+		return "", "", false
+	}
+	return format, contentType, true
+}
+
+// abortDownload ends a download that failed after the file started. The
+// status cannot change anymore, so the connection is dropped to make the
+// client see the file as incomplete rather than receive a truncated one.
+func abortDownload(c *gin.Context, err error) {
+	c.Error(err)
+	panic(http.ErrAbortHandler)
+}
+
+// attachment sets the headers of a downloaded file.
+func attachment(c *gin.Context, contentType string, fileName string) {
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"project_sdu/model"
 	"project_sdu/service"
+	"project_sdu/validation"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -14,6 +15,7 @@ type FacilityAPI interface {
 	GetFacilityByID(c *gin.Context)
 	GetAllFacilities(c *gin.Context)
 	UpdateFacility(c *gin.Context)
+	PatchFacility(c *gin.Context)
 	DeleteFacility(c *gin.Context)
 }
 
@@ -29,28 +31,13 @@ func NewFacilityAPI(facilityService service.FacilityService) *facilityAPI {
 // CREATE FACILITY
 // ====================
 func (f *facilityAPI) CreateFacility(c *gin.Context) {
-	var facility model.Facility
-	if err := c.ShouldBindJSON(&facility); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Invalid JSON format",
-			Errors:  map[string]string{"body": err.Error()},
-		})
-		return
-	}
-
-	if facility.Name == "" {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors:  map[string]string{"name": "Name is required"},
-		})
+	var req model.FacilityRequest
+	if !bindJSON(c, &req, validation.LangEN) {
 		return
 	}
 
-	if err := f.facilityService.Create(&facility); err != nil {
+	facility := req.ToModel()
+	if err := f.facilityService.Create(facility); err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -64,7 +51,7 @@ func (f *facilityAPI) CreateFacility(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusCreated,
 		Message: "Facility created successfully",
-		Data:    facility,
+		Data:    model.NewFacilityResponse(facility),
 	})
 }
 
@@ -97,7 +84,7 @@ func (f *facilityAPI) GetFacilityByID(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Facility retrieved successfully",
-		Data:    facility,
+		Data:    model.NewFacilityResponse(facility),
 	})
 }
 
@@ -105,20 +92,14 @@ func (f *facilityAPI) GetFacilityByID(c *gin.Context) {
 // GET ALL FACILITIES
 // ====================
 func (f *facilityAPI) GetAllFacilities(c *gin.Context) {
-	limitParam := c.DefaultQuery("limit", "10")
-	pageParam := c.DefaultQuery("page", "1")
-
-	limit, _ := strconv.Atoi(limitParam)
-	page, _ := strconv.Atoi(pageParam)
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
 
-	facilities, err := f.facilityService.GetAll(limit, page)
+	facilities, meta, err := f.facilityService.GetAll(page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to retrieve facilities",
-			Errors:  map[string]string{"server": err.Error()},
-		})
+		listFailed(c, err, "Failed to retrieve facilities")
 		return
 	}
 
@@ -126,7 +107,8 @@ func (f *facilityAPI) GetAllFacilities(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Facilities retrieved successfully",
-		Data:    facilities,
+		Data:    model.NewFacilityResponses(facilities),
+		Meta:    meta,
 	})
 }
 
@@ -144,18 +126,13 @@ func (f *facilityAPI) UpdateFacility(c *gin.Context) {
 		return
 	}
 
-	var facility model.Facility
-	if err := c.ShouldBindJSON(&facility); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Invalid JSON format",
-			Errors:  map[string]string{"body": err.Error()},
-		})
+	var req model.FacilityRequest
+	if !bindJSON(c, &req, validation.LangEN) {
 		return
 	}
 
-	if err := f.facilityService.Update(id, &facility); err != nil {
+	facility := req.ToModel()
+	if err := f.facilityService.Update(id, facility); err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -169,7 +146,41 @@ func (f *facilityAPI) UpdateFacility(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Facility updated successfully",
-		Data:    facility,
+		Data:    model.NewFacilityResponse(facility),
+	})
+}
+
+// ====================
+// PATCH FACILITY
+// ====================
+func (f *facilityAPI) PatchFacility(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid facility ID",
+		})
+		return
+	}
+
+	patch, ok := bindPatch(c, validation.LangEN)
+	if !ok {
+		return
+	}
+
+	facility, err := f.facilityService.Patch(id, patch)
+	if err != nil {
+		patchFailed(c, err, validation.LangEN, "Facility not found", "Failed to update facility")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Facility updated successfully",
+		Data:    model.NewFacilityResponse(facility),
 	})
 }
 
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"project_sdu/model"
 	"project_sdu/service"
+	"project_sdu/validation"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -13,6 +14,7 @@ type FaqAPI interface {
 	Create(c *gin.Context)
 	GetAll(c *gin.Context)
 	Update(c *gin.Context)
+	Patch(c *gin.Context)
 	Delete(c *gin.Context)
 	GetByID(c *gin.Context)
 }
@@ -26,18 +28,14 @@ func NewFaqAPI(faqService service.FaqService) FaqAPI {
 }
 
 func (a *faqAPI) Create(c *gin.Context) {
-	var faq model.Faq
-	if err := c.ShouldBindJSON(&faq); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Invalid JSON",
-			Errors:  map[string]string{"body": err.Error()},
-		})
+	var req model.FaqRequest
+	if !bindJSON(c, &req, validation.LangEN) {
 		return
 	}
 
-	if err := a.faqService.Create(&faq); err != nil {
+	faq := req.ToModel()
+
+	if err := a.faqService.Create(faq); err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -51,7 +49,7 @@ func (a *faqAPI) Create(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusCreated,
 		Message: "Faq created successfully",
-		Data:    faq,
+		Data:    model.NewFaqResponse(faq),
 	})
 }
 
@@ -71,7 +69,7 @@ func (a *faqAPI) GetAll(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Faqs fetched successfully",
-		Data:    faqs,
+		Data:    model.NewFaqResponses(faqs),
 	})
 }
 
@@ -87,18 +85,14 @@ func (a *faqAPI) Update(c *gin.Context) {
 		return
 	}
 
-	var faq model.Faq
-	if err := c.ShouldBindJSON(&faq); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Invalid JSON",
-			Errors:  map[string]string{"body": err.Error()},
-		})
+	var req model.FaqRequest
+	if !bindJSON(c, &req, validation.LangEN) {
 		return
 	}
 
-	if err := a.faqService.Update(id, &faq); err != nil {
+	faq := req.ToModel()
+
+	if err := a.faqService.Update(id, faq); err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -112,7 +106,38 @@ func (a *faqAPI) Update(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Faq updated successfully",
-		Data:    faq,
+		Data:    model.NewFaqResponse(faq),
+	})
+}
+
+func (a *faqAPI) Patch(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	patch, ok := bindPatch(c, validation.LangEN)
+	if !ok {
+		return
+	}
+
+	faq, err := a.faqService.Patch(id, patch)
+	if err != nil {
+		patchFailed(c, err, validation.LangEN, "Faq not found", "Failed to update faq")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Faq updated successfully",
+		Data:    model.NewFaqResponse(faq),
 	})
 }
 
@@ -172,6 +197,6 @@ func (a *faqAPI) GetByID(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Faq fetched successfully",
-		Data:    faq,
+		Data:    model.NewFaqResponse(faq),
 	})
 }
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"project_sdu/model"
+	"project_sdu/service"
+	"project_sdu/validation"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type FilterPresetAPI interface {
+	GetAll(c *gin.Context)
+	Save(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type filterPresetAPI struct {
+	filterPresetService service.FilterPresetService
+}
+
+func NewFilterPresetAPI(filterPresetService service.FilterPresetService) FilterPresetAPI {
+	return &filterPresetAPI{filterPresetService}
+}
+
+// ====================
+// GET MY FILTER PRESETS
+// ====================
+func (a *filterPresetAPI) GetAll(c *gin.Context) {
+	presets, err := a.filterPresetService.GetAll(c.GetInt("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to fetch filter presets",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Filter presets fetched successfully",
+		Data:    model.NewFilterPresetResponses(presets),
+	})
+}
+
+// ====================
+// SAVE FILTER PRESET
+// ====================
+func (a *filterPresetAPI) Save(c *gin.Context) {
+	var req model.FilterPresetRequest
+	if !bindJSON(c, &req, validation.LangEN) {
+		return
+	}
+
+	// Saving under an existing name replaces that preset
+	preset := req.ToModel(c.GetInt("id"))
+
+	if err := a.filterPresetService.Save(preset); err != nil {
+		var fieldErrs service.FieldErrors
+		if errors.As(err, &fieldErrs) {
+			lang := requestLang(c, validation.LangEN)
+			validationFailed(c, validation.Localize(fieldErrs, lang), lang)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to save filter preset",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Filter preset saved successfully",
+		Data:    model.NewFilterPresetResponse(preset),
+	})
+}
+
+// ====================
+// DELETE FILTER PRESET
+// ====================
+func (a *filterPresetAPI) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	if err := a.filterPresetService.Delete(id, c.GetInt("id")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Success: false,
+				Status:  http.StatusNotFound,
+				Message: "Filter preset not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to delete filter preset",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Filter preset deleted successfully",
+	})
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"project_sdu/model"
+	"project_sdu/service"
+	"project_sdu/validation"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxImportFileSize caps an uploaded import file at 10 MB.
+const maxImportFileSize = 10 << 20
+
+type ImportAPI interface {
+	Template(c *gin.Context)
+	Start(c *gin.Context)
+	GetJobs(c *gin.Context)
+	GetJob(c *gin.Context)
+}
+
+type importAPI struct {
+	importService service.ImportService
+}
+
+func NewImportAPI(importService service.ImportService) ImportAPI {
+	return &importAPI{importService}
+}
+
+// ====================
+// DOWNLOAD IMPORT TEMPLATE
+// ====================
+func (a *importAPI) Template(c *gin.Context) {
+	format, contentType, ok := spreadsheetFormat(c)
+	if !ok {
+		return
+	}
+
+	attachment(c, contentType, "import-pendaftar."+format)
+	if err := a.importService.Template(format, c.Writer); err != nil {
+		c.Error(err)
+	}
+}
+
+// ====================
+// START IMPORT
+// ====================
+func (a *importAPI) Start(c *gin.Context) {
+	lang := requestLang(c, validation.LangEN)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		validationFailed(c, map[string]string{"file": "a CSV or XLSX file of at most 10 MB is required"}, lang)
+		return
+	}
+	defer file.Close()
+
+	errs := map[string]string{}
+
+	// The format follows the file extension unless given
+	format := c.PostForm("format")
+	if format == "" {
+		format = strings.ToLower(strings.TrimPrefix(filepath.Ext(header.Filename), "."))
+	}
+
+	// mapping is a JSON object from file header to import column, e.g.
+	// {"Nama Lengkap": "full_name"}
+	var mapping map[string]string
+	if raw := c.PostForm("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			errs["mapping"] = "mapping must be a JSON object of file header to import column"
+		}
+	}
+
+	dryRun := false
+	if raw := c.PostForm("dry_run"); raw != "" {
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			errs["dry_run"] = "dry_run must be true or false"
+		}
+	}
+	if len(errs) > 0 {
+		validationFailed(c, errs, lang)
+		return
+	}
+
+	job := &model.ImportJob{
+		UserID:   c.GetInt("id"),
+		FileName: header.Filename,
+		Format:   format,
+		Mode:     c.PostForm("mode"),
+		DryRun:   dryRun,
+	}
+
+	if err := a.importService.Start(job, file, header.Size, mapping, lang); err != nil {
+		var fieldErrs service.FieldErrors
+		if errors.As(err, &fieldErrs) {
+			validationFailed(c, validation.Localize(fieldErrs, lang), lang)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to start import",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	// The rows are processed in the background, poll the job for the result
+	c.JSON(http.StatusAccepted, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusAccepted,
+		Message: "Import started",
+		Data:    job,
+	})
+}
+
+// ====================
+// GET ALL IMPORT JOBS
+// ====================
+func (a *importAPI) GetJobs(c *gin.Context) {
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	jobs, meta, err := a.importService.GetJobs(page)
+	if err != nil {
+		listFailed(c, err, "Failed to fetch import jobs")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Import jobs fetched successfully",
+		Data:    jobs,
+		Meta:    meta,
+	})
+}
+
+// ====================
+// GET IMPORT JOB BY ID
+// ====================
+func (a *importAPI) GetJob(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	job, err := a.importService.GetJob(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Success: false,
+				Status:  http.StatusNotFound,
+				Message: "Import job not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to fetch import job",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Import job fetched successfully",
+		Data:    job,
+	})
+}
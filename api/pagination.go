@@ -0,0 +1,88 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"project_sdu/model"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pageRequest reads limit, page and cursor for a paged listing. On invalid
+// values it responds 400 and returns false.
+func pageRequest(c *gin.Context) (model.PageRequest, bool) {
+	page := c.Query("page")
+
+	// Older clients page by offset; it is kept for them when page is absent
+	if offset := c.Query("offset"); page == "" && offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: "Invalid pagination",
+				Errors:  map[string]string{"offset": "offset must be zero or a positive number"},
+			})
+			return model.PageRequest{}, false
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		if limit < 1 {
+			limit = model.DefaultPageLimit
+		}
+		page = strconv.Itoa(n/min(limit, model.MaxPageLimit) + 1)
+	}
+
+	req, errs := model.NewPageRequest(c.Query("limit"), page, c.Query("cursor"))
+	if errs != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid pagination",
+			Errors:  errs,
+		})
+		return req, false
+	}
+	return req, true
+}
+
+// listFailed responds to an error from a paged listing: 400 for a cursor
+// that does not belong to the listing, 500 otherwise.
+func listFailed(c *gin.Context, err error, failed string) {
+	if errors.Is(err, model.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid pagination",
+			Errors:  map[string]string{"cursor": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+		Success: false,
+		Status:  http.StatusInternalServerError,
+		Message: failed,
+		Errors:  map[string]string{"server": err.Error()},
+	})
+}
+
+// pageMeta adds the filters a listing was called with to its page meta.
+func pageMeta(meta *model.PageMeta, filters gin.H) gin.H {
+	h := gin.H{
+		"limit":       meta.Limit,
+		"total":       meta.Total,
+		"total_pages": meta.TotalPages,
+		"has_next":    meta.HasNext,
+	}
+	if meta.Page > 0 {
+		h["page"] = meta.Page
+	}
+	if meta.NextCursor != "" {
+		h["next_cursor"] = meta.NextCursor
+	}
+	for k, v := range filters {
+		h[k] = v
+	}
+	return h
+}
@@ -1,9 +1,12 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"project_sdu/model"
+	"project_sdu/repository"
 	"project_sdu/service"
+	"project_sdu/validation"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -14,7 +17,14 @@ type ParentAPI interface {
 	GetParentByID(c *gin.Context)
 	GetAllParents(c *gin.Context)
 	UpdateParent(c *gin.Context)
+	PatchParent(c *gin.Context)
 	DeleteParent(c *gin.Context)
+	PreviewDeleteParent(c *gin.Context)
+	GetTrashedParents(c *gin.Context)
+	RestoreParent(c *gin.Context)
+	PurgeParent(c *gin.Context)
+	GetDuplicates(c *gin.Context)
+	MergeParents(c *gin.Context)
 }
 
 type parentAPI struct {
@@ -29,36 +39,13 @@ func NewParentAPI(parentService service.ParentService) *parentAPI {
 // CREATE PARENT
 // ====================
 func (p *parentAPI) CreateParent(c *gin.Context) {
-	var parent model.Parent
-	if err := c.ShouldBindJSON(&parent); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors: map[string]string{
-				"body": "Invalid JSON format",
-			},
-		})
+	var req model.CreateParentRequest
+	if !bindJSON(c, &req, validation.LangEN) {
 		return
 	}
 
-	// Minimal validation
-	errors := make(map[string]string)
-	if *parent.FatherName == "" || *parent.MotherName == ""{
-		errors["name"] = "parents name is required"
-	}
-
-	if len(errors) > 0 {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors:  errors,
-		})
-		return
-	}
-
-	if err := p.parentService.CreateParent(&parent); err != nil {
+	parent := req.ToModel()
+	if err := p.parentService.CreateParent(parent); err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -72,7 +59,7 @@ func (p *parentAPI) CreateParent(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusCreated,
 		Message: "Parent created successfully",
-		Data:    parent,
+		Data:    model.NewParentResponse(parent),
 	})
 }
 
@@ -102,11 +89,12 @@ func (p *parentAPI) GetParentByID(c *gin.Context) {
 		return
 	}
 
+	setETag(c, parent.Version)
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Parent retrieved successfully",
-		Data:    parent,
+		Data:    model.NewParentResponse(parent),
 	})
 }
 
@@ -114,20 +102,14 @@ func (p *parentAPI) GetParentByID(c *gin.Context) {
 // GET ALL PARENTS
 // ====================
 func (p *parentAPI) GetAllParents(c *gin.Context) {
-	limitParam := c.DefaultQuery("limit", "10")
-	offsetParam := c.DefaultQuery("offset", "0")
-
-	limit, _ := strconv.Atoi(limitParam)
-	offset, _ := strconv.Atoi(offsetParam)
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
 
-	parents, err := p.parentService.GetAllParents(limit, offset)
+	parents, meta, err := p.parentService.GetAllParents(page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to retrieve parents",
-			Errors:  map[string]string{"server": err.Error()},
-		})
+		listFailed(c, err, "Failed to retrieve parents")
 		return
 	}
 
@@ -135,7 +117,8 @@ func (p *parentAPI) GetAllParents(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Parents retrieved successfully",
-		Data:    parents,
+		Data:    model.NewParentResponses(parents),
+		Meta:    meta,
 	})
 }
 
@@ -154,18 +137,24 @@ func (p *parentAPI) UpdateParent(c *gin.Context) {
 		return
 	}
 
-	var parent model.Parent
-	if err := c.ShouldBindJSON(&parent); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Invalid JSON format",
-			Errors:  map[string]string{"body": err.Error()},
-		})
+	version, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
-	if err := p.parentService.UpdateParent(id, &parent); err != nil {
+	var req model.ParentRequest
+	if !bindJSON(c, &req, validation.LangEN) {
+		return
+	}
+
+	parent := req.ToModel()
+	parent.Version = version
+	if err := p.parentService.UpdateParent(id, parent); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			p.conflict(c, id)
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -175,11 +164,56 @@ func (p *parentAPI) UpdateParent(c *gin.Context) {
 		return
 	}
 
+	setETag(c, parent.Version)
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Parent updated successfully",
-		Data:    parent,
+		Data:    model.NewParentResponse(parent),
+	})
+}
+
+// ====================
+// PATCH PARENT
+// ====================
+func (p *parentAPI) PatchParent(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid parent ID",
+		})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	patch, ok := bindPatch(c, validation.LangEN)
+	if !ok {
+		return
+	}
+
+	parent, err := p.parentService.PatchParent(id, version, patch)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		p.conflict(c, id)
+		return
+	}
+	if err != nil {
+		patchFailed(c, err, validation.LangEN, "Parent not found", "Failed to update parent")
+		return
+	}
+
+	setETag(c, parent.Version)
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Parent updated successfully",
+		Data:    model.NewParentResponse(parent),
 	})
 }
 
@@ -199,10 +233,146 @@ func (p *parentAPI) DeleteParent(c *gin.Context) {
 	}
 
 	if err := p.parentService.DeleteParent(id); err != nil {
+		if errors.Is(err, service.ErrParentHasStudents) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: "Parent cannot be deleted because it has associated students",
+			})
+			return
+		}
+
+		deleteFailed(c, err, "Parent not found", "Failed to delete parent")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Parent deleted successfully",
+	})
+}
+
+// ====================
+// DELETE PARENT PREVIEW
+// ====================
+func (p *parentAPI) PreviewDeleteParent(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid parent ID",
+		})
+		return
+	}
+
+	impact, err := p.parentService.PreviewDeleteParent(id)
+	if err != nil {
+		deleteFailed(c, err, "Parent not found", "Failed to preview parent deletion")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Parent deletion previewed successfully",
+		Data:    impact,
+	})
+}
+
+// ====================
+// GET TRASHED PARENTS
+// ====================
+func (p *parentAPI) GetTrashedParents(c *gin.Context) {
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	parents, meta, err := p.parentService.GetTrashedParents(page)
+	if err != nil {
+		listFailed(c, err, "Failed to retrieve trashed parents")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Trashed parents retrieved successfully",
+		Data:    model.NewParentResponses(parents),
+		Meta:    meta,
+	})
+}
+
+// ====================
+// RESTORE PARENT
+// ====================
+func (p *parentAPI) RestoreParent(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid parent ID",
+		})
+		return
+	}
+
+	parent, err := p.parentService.RestoreParent(id)
+	if err != nil {
+		trashFailed(c, err, "Parent not found in trash", "Failed to restore parent")
+		return
+	}
+
+	setETag(c, parent.Version)
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Parent restored successfully",
+		Data:    model.NewParentResponse(parent),
+	})
+}
+
+// ====================
+// PURGE PARENT
+// ====================
+func (p *parentAPI) PurgeParent(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid parent ID",
+		})
+		return
+	}
+
+	if err := p.parentService.PurgeParent(id); err != nil {
+		trashFailed(c, err, "Parent not found in trash", "Failed to permanently delete parent")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Parent permanently deleted",
+	})
+}
+
+// ====================
+// GET DUPLICATE PARENTS
+// ====================
+func (p *parentAPI) GetDuplicates(c *gin.Context) {
+	groups, err := p.parentService.FindDuplicates()
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
-			Message: "Failed to delete parent",
+			Message: "Failed to find duplicate parents",
 			Errors:  map[string]string{"server": err.Error()},
 		})
 		return
@@ -211,6 +381,67 @@ func (p *parentAPI) DeleteParent(c *gin.Context) {
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Success: true,
 		Status:  http.StatusOK,
-		Message: "Parent deleted successfully",
+		Message: "Duplicate parents retrieved successfully",
+		Data:    model.MaskParentDuplicateGroups(groups),
 	})
 }
+
+// ====================
+// MERGE PARENTS
+// ====================
+func (p *parentAPI) MergeParents(c *gin.Context) {
+	var req model.ParentMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Errors:  map[string]string{"body": "target_id and source_ids are required"},
+		})
+		return
+	}
+
+	parent, err := p.parentService.MergeParents(req.TargetID, req.SourceIDs)
+	if err != nil {
+		if err == service.ErrMergeSameParent || err == service.ErrMergeNoSources {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: "Validation failed",
+				Errors:  map[string]string{"source_ids": err.Error()},
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to merge parents",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Parents merged successfully",
+		Data:    model.NewParentResponse(parent),
+	})
+}
+
+// conflict answers a stale If-Match with the parent as it is now.
+func (p *parentAPI) conflict(c *gin.Context, id int) {
+	current, err := p.parentService.GetParentByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve parent",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	versionConflict(c, current.Version, model.NewParentResponse(current))
+}
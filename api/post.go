@@ -1,9 +1,12 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"project_sdu/model"
+	"project_sdu/repository"
 	"project_sdu/service"
+	"project_sdu/validation"
 	"regexp"
 	"strconv"
 	"strings"
@@ -28,7 +31,11 @@ type PostAPI interface {
 	GetAllPosts(c *gin.Context)
 	GetPublishedPosts(c *gin.Context)
 	UpdatePost(c *gin.Context)
+	PatchPost(c *gin.Context)
 	DeletePost(c *gin.Context)
+	GetTrashedPosts(c *gin.Context)
+	RestorePost(c *gin.Context)
+	PurgePost(c *gin.Context)
 }
 
 type postAPI struct {
@@ -43,48 +50,21 @@ func NewPostAPI(postService service.PostService) *postAPI {
 // CREATE POST
 // ====================
 func (p *postAPI) CreatePost(c *gin.Context) {
-	var post model.Post
-	if err := c.ShouldBindJSON(&post); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors:  map[string]string{"body": "Invalid JSON format"},
-		})
+	var req model.PostRequest
+	if !bindJSON(c, &req, validation.LangEN) {
 		return
 	}
 
-	// Minimal validation
-	errors := make(map[string]string)
-	if post.Title == "" {
-		errors["title"] = "title is required"
-	}
-	if post.Content == "" {
-		errors["content"] = "content is required"
-	}
-
 	// Always (re-)generate slug from Title.
-	if post.Title != "" {
-		post.Slug = slugify(post.Title)
-	} else {
-		post.Slug = ""
-	}
-
+	post := req.ToModel()
+	post.Slug = slugify(post.Title)
 	if post.Slug == "" {
-		errors["slug"] = "slug is required"
-	}
-
-	if len(errors) > 0 {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors:  errors,
-		})
+		lang := requestLang(c, validation.LangEN)
+		validationFailed(c, map[string]string{"title": validation.Text(lang, "slug_empty")}, lang)
 		return
 	}
 
-	if err := p.postService.CreatePost(&post); err != nil {
+	if err := p.postService.CreatePost(post); err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -98,7 +78,7 @@ func (p *postAPI) CreatePost(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusCreated,
 		Message: "Post created successfully",
-		Data:    post,
+		Data:    model.NewPostResponse(post),
 	})
 }
 
@@ -128,11 +108,12 @@ func (p *postAPI) GetPostByID(c *gin.Context) {
 		return
 	}
 
+	setETag(c, post.Version)
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Post retrieved successfully",
-		Data:    post,
+		Data:    model.NewPostResponse(post),
 	})
 }
 
@@ -161,11 +142,12 @@ func (p *postAPI) GetPostBySlug(c *gin.Context) {
 		return
 	}
 
+	setETag(c, post.Version)
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Post retrieved successfully",
-		Data:    post,
+		Data:    model.NewPostResponse(post),
 	})
 }
 
@@ -173,21 +155,15 @@ func (p *postAPI) GetPostBySlug(c *gin.Context) {
 // GET ALL POSTS
 // ====================
 func (p *postAPI) GetAllPosts(c *gin.Context) {
-	limitParam := c.DefaultQuery("limit", "10")
-	pageParam := c.DefaultQuery("page", "1")
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
 	q := c.Query("q")
 
-	limit, _ := strconv.Atoi(limitParam)
-	page, _ := strconv.Atoi(pageParam)
-
-	posts, err := p.postService.GetAllPosts(limit, page, q)
+	posts, meta, err := p.postService.GetAllPosts(page, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to retrieve posts",
-			Errors:  map[string]string{"server": err.Error()},
-		})
+		listFailed(c, err, "Failed to retrieve posts")
 		return
 	}
 
@@ -195,11 +171,8 @@ func (p *postAPI) GetAllPosts(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Posts retrieved successfully",
-		Data:    posts,
-		Meta: gin.H{
-			"page":  page,
-			"limit": limit,
-		},
+		Data:    model.NewPostResponses(posts),
+		Meta:    meta,
 	})
 }
 
@@ -207,20 +180,14 @@ func (p *postAPI) GetAllPosts(c *gin.Context) {
 // GET ALL PUBLISHED POSTS
 // ====================
 func (p *postAPI) GetPublishedPosts(c *gin.Context) {
-	limitParam := c.DefaultQuery("limit", "10")
-	offsetParam := c.DefaultQuery("offset", "0")
-
-	limit, _ := strconv.Atoi(limitParam)
-	offset, _ := strconv.Atoi(offsetParam)
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
 
-	posts, err := p.postService.GetPublishedPosts(limit, offset)
+	posts, meta, err := p.postService.GetPublishedPosts(page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to retrieve published posts",
-			Errors:  map[string]string{"server": err.Error()},
-		})
+		listFailed(c, err, "Failed to retrieve published posts")
 		return
 	}
 
@@ -228,7 +195,8 @@ func (p *postAPI) GetPublishedPosts(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Published posts retrieved successfully",
-		Data:    posts,
+		Data:    model.NewPostResponses(posts),
+		Meta:    meta,
 	})
 }
 
@@ -257,18 +225,24 @@ func (p *postAPI) UpdatePost(c *gin.Context) {
 		return
 	}
 
-	var post model.Post
-	if err := c.ShouldBindJSON(&post); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Invalid JSON format",
-			Errors:  map[string]string{"body": err.Error()},
-		})
+	version, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
-	if err := p.postService.UpdatePost(slug, &post); err != nil {
+	var req model.PostRequest
+	if !bindJSON(c, &req, validation.LangEN) {
+		return
+	}
+
+	post := req.ToModel()
+	post.Version = version
+	if err := p.postService.UpdatePost(slug, post); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			p.conflict(c, slug)
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -278,11 +252,55 @@ func (p *postAPI) UpdatePost(c *gin.Context) {
 		return
 	}
 
+	setETag(c, post.Version)
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Post updated successfully",
+		Data:    model.NewPostResponse(post),
+	})
+}
+
+// ====================
+// PATCH POST
+// ====================
+func (p *postAPI) PatchPost(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Slug is required",
+		})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	patch, ok := bindPatch(c, validation.LangEN)
+	if !ok {
+		return
+	}
+
+	post, err := p.postService.PatchPost(slug, version, patch)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		p.conflict(c, slug)
+		return
+	}
+	if err != nil {
+		patchFailed(c, err, validation.LangEN, "Post not found", "Failed to update post")
+		return
+	}
+
+	setETag(c, post.Version)
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Post updated successfully",
-		Data:    post,
+		Data:    model.NewPostResponse(post),
 	})
 }
 
@@ -326,3 +344,98 @@ func (p *postAPI) DeletePost(c *gin.Context) {
 		Message: "Post deleted successfully",
 	})
 }
+
+// ====================
+// GET TRASHED POSTS
+// ====================
+func (p *postAPI) GetTrashedPosts(c *gin.Context) {
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	posts, meta, err := p.postService.GetTrashedPosts(page)
+	if err != nil {
+		listFailed(c, err, "Failed to retrieve trashed posts")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Trashed posts retrieved successfully",
+		Data:    model.NewPostResponses(posts),
+		Meta:    meta,
+	})
+}
+
+// ====================
+// RESTORE POST
+// ====================
+func (p *postAPI) RestorePost(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Slug is required",
+		})
+		return
+	}
+
+	post, err := p.postService.RestorePost(slug)
+	if err != nil {
+		trashFailed(c, err, "Post not found in trash", "Failed to restore post")
+		return
+	}
+
+	setETag(c, post.Version)
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Post restored successfully",
+		Data:    model.NewPostResponse(post),
+	})
+}
+
+// ====================
+// PURGE POST
+// ====================
+func (p *postAPI) PurgePost(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Slug is required",
+		})
+		return
+	}
+
+	if err := p.postService.PurgePost(slug); err != nil {
+		trashFailed(c, err, "Post not found in trash", "Failed to permanently delete post")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Post permanently deleted",
+	})
+}
+
+// conflict answers a stale If-Match with the post as it is now.
+func (p *postAPI) conflict(c *gin.Context, slug string) {
+	current, err := p.postService.GetPostBySlug(slug)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve post",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	versionConflict(c, current.Version, model.NewPostResponse(current))
+}
@@ -1,96 +1,202 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"project_sdu/model"
-	"project_sdu/repository"
 	"project_sdu/service"
+	"project_sdu/validation"
 
 	"github.com/gin-gonic/gin"
 )
 
 type PPDBAPI interface {
 	Register(c *gin.Context)
+	CreateDraft(c *gin.Context)
+	GetDraft(c *gin.Context)
+	SaveDraftStep(c *gin.Context)
+	SubmitDraft(c *gin.Context)
 }
 
 type ppdbAPI struct {
 	studentService service.StudentService
+	draftService   service.DraftService
 }
 
-func NewPPDBAPI(studentService service.StudentService) *ppdbAPI {
-	return &ppdbAPI{studentService}
+func NewPPDBAPI(studentService service.StudentService, draftService service.DraftService) *ppdbAPI {
+	return &ppdbAPI{studentService, draftService}
 }
 
 // ====================
 // REGISTER (PUBLIC)
 // ====================
 func (p *ppdbAPI) Register(c *gin.Context) {
-	var student model.Student
-	if err := c.ShouldBindJSON(&student); err != nil {
+	var req model.PPDBRequest
+	if !bindJSON(c, &req, validation.LangID) {
+		return
+	}
+	lang := requestLang(c, validation.LangID)
+
+	student := req.ToModel()
+	if errs := validation.StudentIdentity(student); len(errs) > 0 {
+		validationFailed(c, validation.Localize(errs, lang), lang)
+		return
+	}
+
+	if err := p.studentService.RegisterPPDB(student); err != nil {
+		registerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusCreated,
+		Message: "Pendaftaran berhasil! Data Anda telah kami terima.",
+		Data:    model.NewStudentResponse(student),
+	})
+}
+
+// registerError responds to a failed RegisterPPDB call.
+func registerError(c *gin.Context, err error) {
+	lang := requestLang(c, validation.LangID)
+
+	var ageErr *service.AgeLimitError
+	if errors.As(err, &ageErr) {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusBadRequest,
-			Message: "Format data tidak valid",
-			Errors: map[string]string{
-				"body": "Invalid JSON format",
-			},
+			Message: validation.Text(lang, "age_limit"),
+			Errors:  map[string]string{"tanggal_lahir": validation.Message(ageErr, lang)},
 		})
 		return
 	}
 
-	// Minimal validation
-	errorsMap := make(map[string]string)
-	if student.FullName == "" {
-		errorsMap["full_name"] = "Nama lengkap wajib diisi"
-	}
-	if student.Gender == "" {
-		errorsMap["gender"] = "Jenis kelamin wajib diisi"
+	if fieldErrs, ok := service.StudentErrorFields(err); ok {
+		validationFailed(c, validation.Localize(fieldErrs, lang), lang)
+		return
 	}
-	// Add more as needed...
 
-	if len(errorsMap) > 0 {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+	c.JSON(http.StatusBadRequest, model.ErrorResponse{ // StatusBadRequest often better for business logic errors like "date invalid"
+		Success: false,
+		Status:  http.StatusBadRequest,
+		Message: err.Error(),
+	})
+}
+
+// ====================
+// DRAFT (PUBLIC)
+// ====================
+func (p *ppdbAPI) CreateDraft(c *gin.Context) {
+	draft, err := p.draftService.Create()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Validasi gagal",
-			Errors:  errorsMap,
+			Status:  http.StatusInternalServerError,
+			Message: "Gagal membuat draf pendaftaran",
 		})
 		return
 	}
 
-	if err := p.studentService.RegisterPPDB(&student); err != nil {
-		switch err {
-		case repository.ErrNIKExists:
-			c.JSON(http.StatusBadRequest, model.ErrorResponse{
-				Success: false,
-				Status:  http.StatusBadRequest,
-				Message: "NIK sudah terdaftar",
-				Errors:  map[string]string{"nik": "NIK already exists"},
-			})
-			return
-
-		case repository.ErrNISNExists:
-			c.JSON(http.StatusBadRequest, model.ErrorResponse{
-				Success: false,
-				Status:  http.StatusBadRequest,
-				Message: "NISN sudah terdaftar",
-				Errors:  map[string]string{"nisn": "NISN already exists"},
-			})
-			return
-		}
+	c.JSON(http.StatusCreated, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusCreated,
+		Message: "Draf pendaftaran dibuat. Simpan token untuk melanjutkan pengisian.",
+		Data:    model.NewDraftResponse(draft),
+	})
+}
+
+func (p *ppdbAPI) GetDraft(c *gin.Context) {
+	draft, err := p.draftService.Get(c.Param("token"))
+	if err != nil {
+		draftError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Draf pendaftaran ditemukan",
+		Data:    model.NewDraftResponse(draft),
+	})
+}
 
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{ // StatusBadRequest often better for business logic errors like "date invalid"
+func (p *ppdbAPI) SaveDraftStep(c *gin.Context) {
+	data, ok := model.NewDraftStepData(c.Param("step"))
+	if !ok {
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
 			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: err.Error(),
+			Status:  http.StatusNotFound,
+			Message: "Langkah pendaftaran tidak dikenal",
 		})
 		return
 	}
 
+	if !bindJSON(c, data, validation.LangID) {
+		return
+	}
+
+	draft, err := p.draftService.SaveStep(c.Param("token"), data)
+	if err != nil {
+		draftError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Data berhasil disimpan",
+		Data:    model.NewDraftResponse(draft),
+	})
+}
+
+func (p *ppdbAPI) SubmitDraft(c *gin.Context) {
+	draft, err := p.draftService.Submit(c.Param("token"))
+	if err != nil {
+		switch err {
+		case service.ErrDraftNotFound, service.ErrDraftExpired, service.ErrDraftSubmitted:
+			draftError(c, err)
+		default:
+			registerError(c, err)
+		}
+		return
+	}
+
 	c.JSON(http.StatusCreated, model.SuccessResponse{
 		Success: true,
 		Status:  http.StatusCreated,
 		Message: "Pendaftaran berhasil! Data Anda telah kami terima.",
-		Data:    student,
+		Data:    model.NewDraftResponse(draft),
+	})
+}
+
+// draftError responds to a failed draft lookup or step save.
+func draftError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	message := err.Error()
+	var errs map[string]string
+
+	switch err {
+	case service.ErrDraftNotFound:
+		status = http.StatusNotFound
+	case service.ErrDraftExpired:
+		status = http.StatusGone
+	case service.ErrDraftSubmitted:
+		status = http.StatusConflict
+	default:
+		if fieldErrs, ok := err.(service.FieldErrors); ok {
+			lang := requestLang(c, validation.LangID)
+			message = validation.Text(lang, "validation_failed")
+			errs = validation.Localize(fieldErrs, lang)
+		} else {
+			status = http.StatusInternalServerError
+			message = "Gagal menyimpan draf pendaftaran"
+		}
+	}
+
+	c.JSON(status, model.ErrorResponse{
+		Success: false,
+		Status:  status,
+		Message: message,
+		Errors:  errs,
 	})
 }
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"project_sdu/model"
+	"project_sdu/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RegionAPI interface {
+	GetProvinces(c *gin.Context)
+	GetChildren(c *gin.Context)
+	GetByCode(c *gin.Context)
+}
+
+type regionAPI struct {
+	regionService service.RegionService
+}
+
+func NewRegionAPI(regionService service.RegionService) RegionAPI {
+	return &regionAPI{regionService}
+}
+
+// ====================
+// GET PROVINCES
+// ====================
+func (r *regionAPI) GetProvinces(c *gin.Context) {
+	regions, err := r.regionService.GetProvinces(c.Query("q"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve provinces",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Provinces retrieved successfully",
+		Data:    regions,
+	})
+}
+
+// ====================
+// GET CHILDREN (CASCADING DROPDOWN)
+// ====================
+func (r *regionAPI) GetChildren(c *gin.Context) {
+	code := c.Param("code")
+
+	regions, err := r.regionService.GetChildren(code, c.Query("q"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve regions",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Regions retrieved successfully",
+		Data:    regions,
+		Meta:    gin.H{"parent_code": code},
+	})
+}
+
+// ====================
+// GET BY CODE
+// ====================
+func (r *regionAPI) GetByCode(c *gin.Context) {
+	region, err := r.regionService.GetByCode(c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusNotFound,
+			Message: "Region not found",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Region retrieved successfully",
+		Data:    region,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"project_sdu/model"
 	"project_sdu/service"
+	"project_sdu/validation"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -13,6 +14,7 @@ type RequirementAPI interface {
 	Create(c *gin.Context)
 	GetAll(c *gin.Context)
 	Update(c *gin.Context)
+	Patch(c *gin.Context)
 	Delete(c *gin.Context)
 	GetByID(c *gin.Context)
 }
@@ -26,18 +28,14 @@ func NewRequirementAPI(requirementService service.RequirementService) Requiremen
 }
 
 func (a *requirementAPI) Create(c *gin.Context) {
-	var requirement model.Requirement
-	if err := c.ShouldBindJSON(&requirement); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Invalid JSON",
-			Errors:  map[string]string{"body": err.Error()},
-		})
+	var req model.RequirementRequest
+	if !bindJSON(c, &req, validation.LangEN) {
 		return
 	}
 
-	if err := a.requirementService.Create(&requirement); err != nil {
+	requirement := req.ToModel()
+
+	if err := a.requirementService.Create(requirement); err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -51,7 +49,7 @@ func (a *requirementAPI) Create(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusCreated,
 		Message: "Requirement created successfully",
-		Data:    requirement,
+		Data:    model.NewRequirementResponse(requirement),
 	})
 }
 
@@ -71,7 +69,7 @@ func (a *requirementAPI) GetAll(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Requirements fetched successfully",
-		Data:    requirements,
+		Data:    model.NewRequirementResponses(requirements),
 	})
 }
 
@@ -87,18 +85,14 @@ func (a *requirementAPI) Update(c *gin.Context) {
 		return
 	}
 
-	var requirement model.Requirement
-	if err := c.ShouldBindJSON(&requirement); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Invalid JSON",
-			Errors:  map[string]string{"body": err.Error()},
-		})
+	var req model.RequirementRequest
+	if !bindJSON(c, &req, validation.LangEN) {
 		return
 	}
 
-	if err := a.requirementService.Update(id, &requirement); err != nil {
+	requirement := req.ToModel()
+
+	if err := a.requirementService.Update(id, requirement); err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -112,7 +106,38 @@ func (a *requirementAPI) Update(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Requirement updated successfully",
-		Data:    requirement,
+		Data:    model.NewRequirementResponse(requirement),
+	})
+}
+
+func (a *requirementAPI) Patch(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	patch, ok := bindPatch(c, validation.LangEN)
+	if !ok {
+		return
+	}
+
+	requirement, err := a.requirementService.Patch(id, patch)
+	if err != nil {
+		patchFailed(c, err, validation.LangEN, "Requirement not found", "Failed to update requirement")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Requirement updated successfully",
+		Data:    model.NewRequirementResponse(requirement),
 	})
 }
 
@@ -172,6 +197,6 @@ func (a *requirementAPI) GetByID(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Requirement fetched successfully",
-		Data:    requirement,
+		Data:    model.NewRequirementResponse(requirement),
 	})
 }
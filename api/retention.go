@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"project_sdu/model"
+	"project_sdu/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RetentionAPI interface {
+	Preview(c *gin.Context)
+	Apply(c *gin.Context)
+	GetLog(c *gin.Context)
+}
+
+type retentionAPI struct {
+	retentionService service.RetentionService
+}
+
+func NewRetentionAPI(retentionService service.RetentionService) RetentionAPI {
+	return &retentionAPI{retentionService}
+}
+
+// ====================
+// PREVIEW RETENTION
+// ====================
+func (a *retentionAPI) Preview(c *gin.Context) {
+	// ?at=2026-01-31 looks ahead; by default it is what a run now would purge
+	at := time.Now()
+	if param := c.Query("at"); param != "" {
+		date, err := time.ParseInLocation("2006-01-02", param, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: "at must be a date like 2026-01-31",
+			})
+			return
+		}
+		at = date
+	}
+
+	preview, err := a.retentionService.Preview(at)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to preview retention",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Retention preview generated successfully",
+		Data:    preview,
+	})
+}
+
+// ====================
+// APPLY RETENTION
+// ====================
+func (a *retentionAPI) Apply(c *gin.Context) {
+	result, err := a.retentionService.Apply()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to apply retention",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Retention applied successfully",
+		Data:    result,
+	})
+}
+
+// ====================
+// RETENTION PURGE LOG
+// ====================
+func (a *retentionAPI) GetLog(c *gin.Context) {
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	entries, meta, err := a.retentionService.GetLog(page)
+	if err != nil {
+		listFailed(c, err, "Failed to fetch retention log")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Retention log fetched successfully",
+		Data:    model.NewRetentionPurgeLogResponses(entries),
+		Meta:    meta,
+	})
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"project_sdu/model"
+	"project_sdu/service"
+	"project_sdu/validation"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchAPI interface {
+	Search(c *gin.Context)
+}
+
+type searchAPI struct {
+	searchService service.SearchService
+}
+
+func NewSearchAPI(searchService service.SearchService) SearchAPI {
+	return &searchAPI{searchService}
+}
+
+// ====================
+// GLOBAL SEARCH
+// ====================
+func (a *searchAPI) Search(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	result, err := a.searchService.Search(c.Query("q"), limit)
+	if err != nil {
+		var fieldErrs service.FieldErrors
+		if errors.As(err, &fieldErrs) {
+			lang := requestLang(c, validation.LangEN)
+			validationFailed(c, validation.Localize(fieldErrs, lang), lang)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to search",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Search completed successfully",
+		Data:    result,
+	})
+}
@@ -1,13 +1,17 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"project_sdu/model"
 	"project_sdu/repository"
 	"project_sdu/service"
+	"project_sdu/validation"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type StudentAPI interface {
@@ -15,74 +19,63 @@ type StudentAPI interface {
 	GetStudentByID(c *gin.Context)
 	GetAllStudents(c *gin.Context)
 	UpdateStudent(c *gin.Context)
+	PatchStudent(c *gin.Context)
 	DeleteStudent(c *gin.Context)
+	PreviewDeleteStudent(c *gin.Context)
+	GetTrashedStudents(c *gin.Context)
+	RestoreStudent(c *gin.Context)
+	PurgeStudent(c *gin.Context)
 	CreateManyStudents(c *gin.Context)
+	EnrollStudents(c *gin.Context)
+	GetEnrolledStudents(c *gin.Context)
+	GetAgeOverrides(c *gin.Context)
+	GetZonasiRanking(c *gin.Context)
+	GetDuplicates(c *gin.Context)
+	MergeStudents(c *gin.Context)
+	ExportStudent(c *gin.Context)
+	AnonymizeStudent(c *gin.Context)
 }
 
 type studentAPI struct {
-	studentService service.StudentService
+	studentService   service.StudentService
+	privacyService   service.PrivacyService
+	accessLogService service.AccessLogService
 }
 
-func NewStudentAPI(studentService service.StudentService) *studentAPI {
-	return &studentAPI{studentService}
+func NewStudentAPI(studentService service.StudentService, privacyService service.PrivacyService, accessLogService service.AccessLogService) *studentAPI {
+	return &studentAPI{studentService, privacyService, accessLogService}
 }
 
 // ====================
 // CREATE STUDENT
 // ====================
 func (s *studentAPI) CreateStudent(c *gin.Context) {
-	var student model.Student
-	if err := c.ShouldBindJSON(&student); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors: map[string]string{
-				"body": "Invalid JSON format",
-			},
-		})
+	var req model.StudentRequest
+	if !bindJSON(c, &req, validation.LangEN) {
 		return
 	}
+	lang := requestLang(c, validation.LangEN)
 
-	// Minimal validation
-	errors := make(map[string]string)
-	if student.FullName == "" {
-		errors["full_name"] = "Full name is required"
-	}
-	if student.Gender == "" {
-		errors["gender"] = "Gender is required"
-	}
-	if *student.Agama == "" {
-		errors["agama"] = "Agama is required"
-	}
-	if len(errors) > 0 {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors:  errors,
-		})
+	student := req.ToModel()
+	if errs := validation.StudentIdentity(student); len(errs) > 0 {
+		validationFailed(c, validation.Localize(errs, lang), lang)
 		return
 	}
 
-	if err := s.studentService.CreateStudent(&student); err != nil {
-		switch err {
-		case repository.ErrNIKExists:
+	if err := s.studentService.CreateStudent(student); err != nil {
+		var ageErr *service.AgeLimitError
+		if errors.As(err, &ageErr) {
 			c.JSON(http.StatusBadRequest, model.ErrorResponse{
 				Success: false,
 				Status:  http.StatusBadRequest,
-				Message: "Validation failed",
-				Errors:  map[string]string{"nik": repository.ErrNIKExists.Error()},
+				Message: validation.Text(lang, "age_override"),
+				Errors:  map[string]string{"tanggal_lahir": validation.Message(ageErr, lang)},
 			})
 			return
+		}
 
-		case repository.ErrNISNExists:
-			c.JSON(http.StatusBadRequest, model.ErrorResponse{
-				Success: false,
-				Status:  http.StatusBadRequest,
-				Message: "Validation failed",
-				Errors:  map[string]string{"nisn": repository.ErrNISNExists.Error()},
-			})
+		if fieldErrs, ok := service.StudentErrorFields(err); ok {
+			validationFailed(c, validation.Localize(fieldErrs, lang), lang)
 			return
 		}
 
@@ -99,7 +92,7 @@ func (s *studentAPI) CreateStudent(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusCreated,
 		Message: "Student created successfully",
-		Data:    student,
+		Data:    model.NewStudentResponse(student),
 	})
 }
 
@@ -107,20 +100,58 @@ func (s *studentAPI) CreateStudent(c *gin.Context) {
 // BULK ADD STUDENT
 // ====================
 func (s *studentAPI) CreateManyStudents(c *gin.Context) {
-	var students []model.Student
-	if err := c.ShouldBindJSON(&students); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	reqs, ok := bindJSONList[model.StudentRequest](c, validation.LangEN)
+	if !ok {
 		return
 	}
+	lang := requestLang(c, validation.LangEN)
 
-	for _, student := range students {
-		if err := s.studentService.CreateStudent(&student); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+	students := make([]*model.Student, len(reqs))
+	errs := make(map[string]string)
+	for i := range reqs {
+		students[i] = reqs[i].ToModel()
+		for field, msg := range validation.Localize(validation.StudentIdentity(students[i]), lang) {
+			errs[fmt.Sprintf("[%d].%s", i, field)] = msg
 		}
 	}
+	if len(errs) > 0 {
+		validationFailed(c, errs, lang)
+		return
+	}
+
+	// All students are stored or, when one fails, none
+	if err := s.studentService.CreateStudents(students); err != nil {
+		var bulkErr *service.BulkCreateError
+		if errors.As(err, &bulkErr) {
+			if fieldErrs, ok := service.StudentErrorFields(bulkErr.Err); ok {
+				for field, msg := range validation.Localize(fieldErrs, lang) {
+					errs[fmt.Sprintf("[%d].%s", bulkErr.Index, field)] = msg
+				}
+				validationFailed(c, errs, lang)
+				return
+			}
+		}
 
-	c.JSON(http.StatusOK, gin.H{"message": "All students created successfully"})
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to create students",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	data := make([]model.StudentResponse, len(students))
+	for i, student := range students {
+		data[i] = model.NewStudentResponse(student)
+	}
+
+	c.JSON(http.StatusCreated, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusCreated,
+		Message: "All students created successfully",
+		Data:    data,
+	})
 }
 
 // ====================
@@ -138,6 +169,13 @@ func (s *studentAPI) GetStudentByID(c *gin.Context) {
 		return
 	}
 
+	// Masked like the lists unless a user allowed to see sensitive data
+	// asks for ?unmask=true; that view is logged
+	unmask, ok := unmaskRequested(c)
+	if !ok {
+		return
+	}
+
 	student, err := s.studentService.GetStudentByID(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, model.ErrorResponse{
@@ -149,11 +187,20 @@ func (s *studentAPI) GetStudentByID(c *gin.Context) {
 		return
 	}
 
+	data := model.NewStudentResponse(student)
+	if unmask {
+		if !recordUnmaskedAccess(c, s.accessLogService, "view", "student", student.ID) {
+			return
+		}
+		data = model.NewUnmaskedStudentResponse(student)
+	}
+
+	setETag(c, student.Version)
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Student retrieved successfully",
-		Data:    student,
+		Data:    data,
 	})
 }
 
@@ -161,87 +208,527 @@ func (s *studentAPI) GetStudentByID(c *gin.Context) {
 // GET ALL STUDENTS
 // ====================
 func (s *studentAPI) GetAllStudents(c *gin.Context) {
-	limitParam := c.DefaultQuery("limit", "10")
-	pageParam := c.DefaultQuery("page", "1")
-	batchparam := c.Query("batch")
-	q := c.Query("q")
-	isAcceptedParam := c.Query("is_accepted") // <-- TAMBAHAN
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	// Filters and sort, e.g. gender=FEMALE&documents_complete=false&sort=-created_at,full_name
+	filter, errs := model.ParseStudentFilter(c.Request.URL.Query())
+	if errs != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid filter",
+			Errors:  errs,
+		})
+		return
+	}
 
-	limit, _ := strconv.Atoi(limitParam)
-	page, _ := strconv.Atoi(pageParam)
-	batch, _ := strconv.Atoi(batchparam)
+	students, meta, err := s.studentService.GetAllStudents(page, filter)
+	if err != nil {
+		listFailed(c, err, "Failed to retrieve students")
+		return
+	}
 
-	// Handle filter is_accepted
-	var isAccepted *bool = nil
-	if isAcceptedParam != "" {
-		parsed, err := strconv.ParseBool(isAcceptedParam)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, model.ErrorResponse{
-				Success: false,
-				Status:  http.StatusBadRequest,
-				Message: "Invalid value for is_accepted (use true or false)",
-			})
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Students retrieved successfully",
+		Data:    model.NewStudentResponses(students),
+		Meta: pageMeta(meta, gin.H{
+			"batch":           filter.BatchID,
+			"is_accepted":     filter.IsAccepted,
+			"max_distance_km": filter.MaxDistanceKm,
+			"sort":            c.DefaultQuery("sort", "full_name"),
+		}),
+	})
+}
+
+// ====================
+// UPDATE STUDENT
+// ====================
+func (s *studentAPI) UpdateStudent(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid student ID",
+		})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateStudentRequest
+	if !bindJSON(c, &req, validation.LangEN) {
+		return
+	}
+
+	student := req.ToModel()
+	student.Version = version
+	if student.Parent != nil && req.ParentVersion != nil {
+		student.Parent.Version = *req.ParentVersion
+	}
+	if err := s.studentService.UpdateStudent(id, student); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			s.conflict(c, id)
 			return
 		}
-		isAccepted = &parsed
+
+		if fieldErrs, ok := service.StudentErrorFields(err); ok {
+			lang := requestLang(c, validation.LangEN)
+			validationFailed(c, validation.Localize(fieldErrs, lang), lang)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to update student",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	setETag(c, student.Version)
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Student updated successfully",
+		Data:    model.NewStudentResponse(student),
+	})
+}
+
+// ====================
+// PATCH STUDENT
+// ====================
+func (s *studentAPI) PatchStudent(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid student ID",
+		})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	patch, ok := bindPatch(c, validation.LangEN)
+	if !ok {
+		return
+	}
+
+	student, err := s.studentService.PatchStudent(id, version, patch)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		s.conflict(c, id)
+		return
+	}
+	if fieldErrs, ok := service.StudentErrorFields(err); ok {
+		err = fieldErrs
+	}
+	if err != nil {
+		patchFailed(c, err, validation.LangEN, "Student not found", "Failed to update student")
+		return
+	}
+
+	setETag(c, student.Version)
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Student updated successfully",
+		Data:    model.NewStudentResponse(student),
+	})
+}
+
+// ====================
+// DELETE STUDENT
+// ====================
+func (s *studentAPI) DeleteStudent(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid student ID",
+		})
+		return
+	}
+
+	impact, err := s.studentService.DeleteStudent(id)
+	if err != nil {
+		deleteFailed(c, err, "Student not found", "Failed to delete student")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Student deleted successfully",
+		Data:    impact,
+	})
+}
+
+// ====================
+// DELETE STUDENT PREVIEW
+// ====================
+func (s *studentAPI) PreviewDeleteStudent(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid student ID",
+		})
+		return
+	}
+
+	impact, err := s.studentService.PreviewDeleteStudent(id)
+	if err != nil {
+		deleteFailed(c, err, "Student not found", "Failed to preview student deletion")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Student deletion previewed successfully",
+		Data:    impact,
+	})
+}
+
+// ====================
+// GET TRASHED STUDENTS
+// ====================
+func (s *studentAPI) GetTrashedStudents(c *gin.Context) {
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	students, meta, err := s.studentService.GetTrashedStudents(page)
+	if err != nil {
+		listFailed(c, err, "Failed to retrieve trashed students")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Trashed students retrieved successfully",
+		Data:    model.NewStudentResponses(students),
+		Meta:    meta,
+	})
+}
+
+// ====================
+// RESTORE STUDENT
+// ====================
+func (s *studentAPI) RestoreStudent(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid student ID",
+		})
+		return
 	}
 
-	students, err := s.studentService.GetAllStudents(limit, page, q, &batch, isAccepted)
+	student, err := s.studentService.RestoreStudent(id)
 	if err != nil {
+		trashFailed(c, err, "Student not found in trash", "Failed to restore student")
+		return
+	}
+
+	setETag(c, student.Version)
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Student restored successfully",
+		Data:    model.NewStudentResponse(student),
+	})
+}
+
+// ====================
+// PURGE STUDENT
+// ====================
+func (s *studentAPI) PurgeStudent(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid student ID",
+		})
+		return
+	}
+
+	if err := s.studentService.PurgeStudent(id); err != nil {
+		trashFailed(c, err, "Student not found in trash", "Failed to permanently delete student")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Student permanently deleted",
+	})
+}
+
+// ====================
+// ENROLL STUDENTS (DAFTAR ULANG)
+// ====================
+func (s *studentAPI) EnrollStudents(c *gin.Context) {
+	var req model.EnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Errors:  map[string]string{"body": "Invalid JSON format"},
+		})
+		return
+	}
+
+	if len(req.StudentIDs) == 0 {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Errors:  map[string]string{"student_ids": "At least one student ID is required"},
+		})
+		return
+	}
+
+	lang := requestLang(c, validation.LangEN)
+	result, err := s.studentService.EnrollStudents(req.StudentIDs, req.TahunMasuk)
+	if err != nil {
+		if err == service.ErrInvalidTahunMasuk {
+			validationFailed(c, map[string]string{"tahun_masuk": validation.Message(err, lang)}, lang)
+			return
+		}
+		if fieldErrs, ok := err.(service.FieldErrors); ok {
+			validationFailed(c, validation.Localize(fieldErrs, lang), lang)
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
-			Message: "Failed to retrieve students",
+			Message: "Failed to enroll students",
 			Errors:  map[string]string{"server": err.Error()},
 		})
 		return
 	}
 
+	res := model.EnrollResult{Enrolled: result.Enrolled, Failed: map[int]string{}}
+	for id, err := range result.Failed {
+		res.Failed[id] = validation.Message(err, lang)
+	}
+
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Success: true,
 		Status:  http.StatusOK,
-		Message: "Students retrieved successfully",
-		Data:    students,
+		Message: "Students enrolled successfully",
+		Data:    res,
+	})
+}
+
+// ====================
+// GET ENROLLED STUDENTS
+// ====================
+func (s *studentAPI) GetEnrolledStudents(c *gin.Context) {
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+	q := c.Query("q")
+	tahunMasuk := c.Query("tahun_masuk")
+
+	students, meta, err := s.studentService.GetEnrolledStudents(page, q, tahunMasuk)
+	if err != nil {
+		listFailed(c, err, "Failed to retrieve enrolled students")
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Enrolled students retrieved successfully",
+		Data:    model.NewStudentResponses(students),
+		Meta: pageMeta(meta, gin.H{
+			"tahun_masuk": tahunMasuk,
+		}),
+	})
+}
+
+// ====================
+// GET AGE OVERRIDES
+// ====================
+func (s *studentAPI) GetAgeOverrides(c *gin.Context) {
+	batch, _ := strconv.Atoi(c.Query("batch"))
+
+	reports, err := s.studentService.GetAgeOverrides(&batch)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve age overrides",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Age overrides retrieved successfully",
+		Data:    reports,
 		Meta: gin.H{
-			"limit":       limit,
-			"page":        page,
-			"batch":       batch,
-			"is_accepted": isAccepted,
+			"batch": batch,
+			"total": len(reports),
 		},
 	})
 }
 
 // ====================
-// UPDATE STUDENT
+// GET ZONASI RANKING
 // ====================
-func (s *studentAPI) UpdateStudent(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
+func (s *studentAPI) GetZonasiRanking(c *gin.Context) {
+	batch, err := strconv.Atoi(c.Query("batch"))
+	if err != nil || batch == 0 {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Errors:  map[string]string{"batch": "batch is required"},
+		})
+		return
+	}
+
+	ranks, err := s.studentService.RankByDistance(batch)
 	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to rank students",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Zonasi ranking retrieved successfully",
+		Data:    ranks,
+		Meta: gin.H{
+			"batch": batch,
+			"total": len(ranks),
+		},
+	})
+}
+
+// ====================
+// GET SUSPECTED DUPLICATES
+// ====================
+func (s *studentAPI) GetDuplicates(c *gin.Context) {
+	batch, err := strconv.Atoi(c.Query("batch"))
+	if err != nil || batch == 0 {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusBadRequest,
-			Message: "Invalid student ID",
+			Message: "Validation failed",
+			Errors:  map[string]string{"batch": "batch is required"},
 		})
 		return
 	}
 
-	var student model.Student
-	if err := c.ShouldBindJSON(&student); err != nil {
+	threshold := service.DefaultDuplicateThreshold
+	if thresholdParam := c.Query("threshold"); thresholdParam != "" {
+		parsed, err := strconv.ParseFloat(thresholdParam, 64)
+		if err != nil || parsed <= 0 || parsed > 1 {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: "Invalid value for threshold (use a number between 0 and 1)",
+			})
+			return
+		}
+		threshold = parsed
+	}
+
+	candidates, err := s.studentService.FindDuplicateStudents(batch, threshold)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to find duplicate students",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Suspected duplicates retrieved successfully",
+		Data:    candidates,
+		Meta: gin.H{
+			"batch":     batch,
+			"threshold": threshold,
+			"total":     len(candidates),
+		},
+	})
+}
+
+// ====================
+// MERGE STUDENTS
+// ====================
+func (s *studentAPI) MergeStudents(c *gin.Context) {
+	var req model.StudentMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusBadRequest,
-			Message: "Invalid JSON format",
-			Errors:  map[string]string{"body": err.Error()},
+			Message: "Validation failed",
+			Errors:  map[string]string{"body": "student_ids is required"},
 		})
 		return
 	}
 
-	if err := s.studentService.UpdateStudent(id, &student); err != nil {
+	student, err := s.studentService.MergeStudents(req.StudentIDs, req.KeepID)
+	if err != nil {
+		if err == service.ErrMergeTooFewStudents || err == service.ErrMergeKeepNotListed {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: "Validation failed",
+				Errors:  map[string]string{"student_ids": err.Error()},
+			})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
-			Message: "Failed to update student",
+			Message: "Failed to merge students",
 			Errors:  map[string]string{"server": err.Error()},
 		})
 		return
@@ -250,17 +737,32 @@ func (s *studentAPI) UpdateStudent(c *gin.Context) {
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Success: true,
 		Status:  http.StatusOK,
-		Message: "Student updated successfully",
-		Data:    student,
+		Message: "Students merged successfully",
+		Data:    model.NewStudentResponse(student),
 	})
 }
 
+// conflict answers a stale If-Match with the student as it is now.
+func (s *studentAPI) conflict(c *gin.Context, id int) {
+	current, err := s.studentService.GetStudentByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve student",
+			Errors:  map[string]string{"server": err.Error()},
+		})
+		return
+	}
+
+	versionConflict(c, current.Version, model.NewStudentResponse(current))
+}
+
 // ====================
-// DELETE STUDENT
+// EXPORT STUDENT (UU PDP)
 // ====================
-func (s *studentAPI) DeleteStudent(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
+func (s *studentAPI) ExportStudent(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Success: false,
@@ -270,19 +772,90 @@ func (s *studentAPI) DeleteStudent(c *gin.Context) {
 		return
 	}
 
-	if err := s.studentService.DeleteStudent(id); err != nil {
+	export, err := s.privacyService.ExportStudent(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Success: false,
+				Status:  http.StatusNotFound,
+				Message: "Student not found",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
-			Message: "Failed to delete student",
+			Message: "Failed to export student",
 			Errors:  map[string]string{"server": err.Error()},
 		})
 		return
 	}
 
+	if !recordUnmaskedAccess(c, s.accessLogService, "export", "student", id) {
+		return
+	}
+
+	if c.Query("format") == "zip" {
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="student-%d.zip"`, id))
+		if err := service.WriteSubjectAccessZip(c.Writer, export); err != nil {
+			c.Error(err)
+		}
+		return
+	}
+
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Success: true,
 		Status:  http.StatusOK,
-		Message: "Student deleted successfully",
+		Message: "Student exported successfully",
+		Data:    export,
+	})
+}
+
+// ====================
+// ANONYMIZE STUDENT (UU PDP)
+// ====================
+func (s *studentAPI) AnonymizeStudent(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid student ID",
+		})
+		return
+	}
+
+	student, err := s.privacyService.AnonymizeStudent(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Success: false,
+				Status:  http.StatusNotFound,
+				Message: "Student not found",
+			})
+		case errors.Is(err, service.ErrStudentAnonymized):
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Success: false,
+				Status:  http.StatusConflict,
+				Message: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to anonymize student",
+				Errors:  map[string]string{"server": err.Error()},
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Student anonymized successfully",
+		Data:    model.NewStudentResponse(student),
 	})
 }
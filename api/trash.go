@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"project_sdu/model"
+	"project_sdu/repository"
+	"project_sdu/service"
+	"project_sdu/validation"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// trashFailed responds to an error from a restore or purge: 404 when the
+// record is not in the trash, 409 when trashed students still point to it
+// or a student's batch is still in the trash.
+func trashFailed(c *gin.Context, err error, notFound string, failed string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusNotFound,
+			Message: notFound,
+		})
+
+	case errors.Is(err, repository.ErrStillReferenced):
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusConflict,
+			Message: failed,
+			Errors:  map[string]string{"students": err.Error()},
+		})
+
+	case errors.Is(err, service.ErrStudentBatchTrashed):
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusConflict,
+			Message: failed,
+			Errors:  map[string]string{"batch_id": err.Error()},
+		})
+
+	default:
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: failed,
+			Errors:  map[string]string{"server": err.Error()},
+		})
+	}
+}
+
+// deleteFailed responds to an error from a delete or delete preview: 400 for
+// invalid options, 404 for a missing record and 500 otherwise.
+func deleteFailed(c *gin.Context, err error, notFound string, failed string) {
+	var fieldErrs service.FieldErrors
+	switch {
+	case errors.As(err, &fieldErrs):
+		validationFailed(c, validation.Localize(fieldErrs, validation.LangEN), validation.LangEN)
+
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusNotFound,
+			Message: notFound,
+		})
+
+	default:
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: failed,
+			Errors:  map[string]string{"server": err.Error()},
+		})
+	}
+}
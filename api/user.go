@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"project_sdu/model"
 	"project_sdu/service"
+	"project_sdu/validation"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -32,38 +33,7 @@ func NewUserAPI(userService service.UserService) *userAPI {
 // ====================
 func (u *userAPI) Register(c *gin.Context) {
 	var req model.UserRegister
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors: map[string]string{
-				"body": "Invalid JSON format",
-			},
-		})
-		return
-	}
-
-	// Minimal Validation
-	errors := make(map[string]string)
-	if req.Fullname == "" {
-		errors["fullname"] = "Fullname is required"
-	}
-	if req.Email == "" {
-		errors["email"] = "Email is required"
-	}
-	if req.Password == "" {
-		errors["password"] = "Password is required"
-	}
-
-	if len(errors) > 0 {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors:  errors,
-		})
+	if !bindJSON(c, &req, validation.LangEN) {
 		return
 	}
 
@@ -114,39 +84,12 @@ func (u *userAPI) Register(c *gin.Context) {
 // LOGIN
 // ====================
 func (u *userAPI) Login(c *gin.Context) {
-	var req model.User
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors: map[string]string{
-				"body": "Invalid JSON format",
-			},
-		})
+	var req model.UserLogin
+	if !bindJSON(c, &req, validation.LangEN) {
 		return
 	}
 
-	// Minimal validation
-	errors := make(map[string]string)
-	if req.Email == "" {
-		errors["email"] = "Email is required"
-	}
-	if req.Password == "" {
-		errors["password"] = "Password is required"
-	}
-	if len(errors) > 0 {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Success: false,
-			Status:  http.StatusBadRequest,
-			Message: "Validation failed",
-			Errors:  errors,
-		})
-		return
-	}
-
-	token, user, err := u.userService.Login(req)
+	token, user, err := u.userService.Login(model.User{Email: req.Email, Password: req.Password})
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
 			Success: false,
@@ -166,11 +109,7 @@ func (u *userAPI) Login(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Login successful",
-		Data: gin.H{
-			"user_id":  user.ID,
-			"email":    user.Email,
-			"fullname": user.Fullname,
-		},
+		Data:    model.NewUserResponse(&user),
 	})
 }
 
@@ -244,11 +183,7 @@ func (u *userAPI) GetUserProfile(c *gin.Context) {
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Retrived user profile succesfully",
-		Data: gin.H{
-			"user_id":  claims.UserID,
-			"email":    user.Email,
-			"fullname": user.Fullname,
-		},
+		Data:    model.NewUserResponse(&user),
 	})
 
 }
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"project_sdu/db"
+	"project_sdu/model"
+	repo "project_sdu/repository"
+	"project_sdu/service"
+
+	"gorm.io/gorm"
+)
+
+// RunCommand runs a maintenance command instead of the HTTP server, e.g.
+// `go run . load-regions wilayah.csv`.
+func RunCommand(conn *gorm.DB, args []string) error {
+	switch args[0] {
+	case "load-regions":
+		return loadRegions(conn, args[1:])
+	case "recompute-distances":
+		return recomputeDistances(conn)
+	case "orphan-parents":
+		return reportOrphanParents(conn)
+	case "purge-drafts":
+		return purgeDrafts(conn)
+	case "purge-trash":
+		return purgeTrash(conn)
+	case "set-role":
+		return setRole(conn, args[1:])
+	case "rotate-keys":
+		return rotateKeys(conn)
+	case "apply-retention":
+		return applyRetention(conn)
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// loadRegions upserts a Kemendagri region CSV (code,name[,postal_code]).
+func loadRegions(conn *gorm.DB, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: load-regions <file.csv>")
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	regions, err := db.ParseRegionsCSV(file)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.NewRegionRepository(conn).Upsert(regions); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ %d regions loaded\n", len(regions))
+	return nil
+}
+
+// recomputeDistances refreshes distance_km for every applicant with home
+// coordinates, e.g. after SCHOOL_LATITUDE/SCHOOL_LONGITUDE changed.
+func recomputeDistances(conn *gorm.DB) error {
+	schoolLat, schoolLon, err := service.SchoolLocation()
+	if err != nil {
+		return err
+	}
+
+	studentRepo := repo.NewStudentRepo(conn)
+	students, err := studentRepo.GetWithCoordinates()
+	if err != nil {
+		return err
+	}
+
+	for _, student := range students {
+		distance := math.Round(service.HaversineKm(*student.Latitude, *student.Longitude, schoolLat, schoolLon)*1000) / 1000
+		if err := studentRepo.UpdateDistance(student.ID, &distance); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("✅ distance recomputed for %d students\n", len(students))
+	return nil
+}
+
+// reportOrphanParents lists parent rows without a student, left behind by
+// registrations that failed before student creation ran in a transaction.
+func reportOrphanParents(conn *gorm.DB) error {
+	parents, err := repo.NewParentRepo(conn).GetOrphans()
+	if err != nil {
+		return err
+	}
+
+	if len(parents) == 0 {
+		fmt.Println("✅ no orphan parents found")
+		return nil
+	}
+
+	value := func(s *string) string {
+		if s == nil {
+			return "-"
+		}
+		return *s
+	}
+
+	fmt.Printf("⚠️  %d orphan parents found\n", len(parents))
+	fmt.Printf("%-6s %-20s %-25s %-25s %s\n", "ID", "CREATED AT", "FATHER", "MOTHER", "EMAIL")
+	for _, p := range parents {
+		fmt.Printf("%-6d %-20s %-25s %-25s %s\n",
+			p.ID, p.CreatedAt.Format("2006-01-02 15:04:05"), value(p.FatherName), value(p.MotherName), value(p.ParentEmail))
+	}
+	return nil
+}
+
+// purgeDrafts deletes expired PPDB drafts, submitted or not.
+func purgeDrafts(conn *gorm.DB) error {
+	deleted, err := repo.NewDraftRepository(conn).DeleteExpired(time.Now())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ %d expired drafts deleted\n", deleted)
+	return nil
+}
+
+// purgeTrash permanently deletes records trashed longer than
+// TRASH_RETENTION_DAYS. The server also does this once a day.
+func purgeTrash(conn *gorm.DB) error {
+	trash := service.NewTrashService(repo.NewStudentRepo(conn), repo.NewParentRepo(conn), repo.NewBatchRepository(conn), repo.NewPostRepo(conn))
+	result, err := trash.PurgeExpired()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Trash purged: %d students, %d parents, %d batches, %d posts\n",
+		result.Students, result.Parents, result.Batches, result.Posts)
+	return nil
+}
+
+// setRole changes the role of an admin account, e.g. to grant a super-admin
+// the right to restore and purge trashed records.
+func setRole(conn *gorm.DB, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: set-role <email> <SUPER_ADMIN|ADMIN>")
+	}
+
+	role := model.UserRole(args[1])
+	if !role.IsValid() {
+		return fmt.Errorf("unknown role %q", args[1])
+	}
+
+	if err := repo.NewUserRepo(conn).UpdateRole(args[0], role); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ %s is now %s\n", args[0], role)
+	return nil
+}
+
+// rotateKeys re-encrypts every student, parent and PPDB draft with the first
+// key of FIELD_ENCRYPTION_KEYS and recomputes the blind indexes. Run it after
+// adding a new key; older keys can be removed once it has finished.
+func rotateKeys(conn *gorm.DB) error {
+	students, err := repo.NewStudentRepo(conn).Reencrypt()
+	if err != nil {
+		return err
+	}
+	parents, err := repo.NewParentRepo(conn).Reencrypt()
+	if err != nil {
+		return err
+	}
+	drafts, err := repo.NewDraftRepository(conn).Reencrypt()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ %d students, %d parents and %d drafts re-encrypted\n", students, parents, drafts)
+	return nil
+}
+
+// applyRetention purges applicant data past RETENTION_RULES. The server also
+// does this once a day.
+func applyRetention(conn *gorm.DB) error {
+	studentRepo := repo.NewStudentRepo(conn)
+	uow := repo.NewUnitOfWork(conn)
+	privacy := service.NewPrivacyService(studentRepo, repo.NewAccessLogRepository(conn), uow)
+	retention := service.NewRetentionService(studentRepo, repo.NewDraftRepository(conn), repo.NewRetentionLogRepository(conn), privacy, uow)
+
+	result, err := retention.Apply()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Retention applied: %d purged\n", result.Purged)
+	for id, reason := range result.Failed {
+		fmt.Printf("⚠️  student %d: %s\n", id, reason)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+
+	"project_sdu/model"
+	"project_sdu/repository"
+)
+
+// MigrateTanggalLahir converts students.tanggal_lahir from free-form text to a
+// date column. The old text column is kept as tanggal_lahir_legacy so values
+// that could not be parsed can still be fixed by hand.
+func MigrateTanggalLahir(db *gorm.DB) error {
+	var dataType string
+	err := db.Raw(`
+		SELECT data_type FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'students' AND column_name = 'tanggal_lahir'`).
+		Scan(&dataType).Error
+	if err != nil {
+		return err
+	}
+
+	// Fresh database or already migrated
+	if dataType == "" || dataType == "date" {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`ALTER TABLE students RENAME COLUMN tanggal_lahir TO tanggal_lahir_legacy`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`ALTER TABLE students ADD COLUMN tanggal_lahir date`).Error; err != nil {
+			return err
+		}
+
+		var rows []struct {
+			ID    int
+			Value string
+		}
+		if err := tx.Raw(`SELECT id, tanggal_lahir_legacy AS value FROM students WHERE tanggal_lahir_legacy IS NOT NULL AND tanggal_lahir_legacy <> ''`).
+			Scan(&rows).Error; err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, row := range rows {
+			date, err := model.ParseDate(row.Value)
+			if err != nil {
+				failed++
+				continue
+			}
+			if err := tx.Exec(`UPDATE students SET tanggal_lahir = ? WHERE id = ?`, date, row.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		log.Printf("✅ tanggal_lahir migrated: %d converted, %d left in tanggal_lahir_legacy", len(rows)-failed, failed)
+		return nil
+	})
+}
+
+// DropStudentParentUnique removes the one-parent-per-student constraint so
+// siblings can share a parent. Newer gorm versions drop uni_students_parent_id
+// themselves during AutoMigrate; tables created by older versions carry the
+// Postgres default name instead.
+func DropStudentParentUnique(db *gorm.DB) error {
+	if !db.Migrator().HasTable("students") {
+		return nil
+	}
+	return db.Exec(`ALTER TABLE students DROP CONSTRAINT IF EXISTS students_parent_id_key`).Error
+}
+
+// FillBlindIndexes encrypts and indexes the students and parents written
+// before encryption. It runs before DropPlaintextIndexes, as until then the
+// old indexes are all that keep the NIK and NISN of those rows unique.
+func FillBlindIndexes(db *gorm.DB) error {
+	students, err := repository.NewStudentRepo(db).FillBlindIndexes()
+	if err != nil {
+		return fmt.Errorf("blind indexes of students: %w", err)
+	}
+	parents, err := repository.NewParentRepo(db).FillBlindIndexes()
+	if err != nil {
+		return fmt.Errorf("blind indexes of parents: %w", err)
+	}
+
+	if students > 0 || parents > 0 {
+		log.Printf("✅ blind indexes filled: %d students, %d parents", students, parents)
+	}
+	return nil
+}
+
+// MigrateDraftPayload turns ppdb_drafts.payload from a jsonb column into a
+// text column holding the encrypted payload, and encrypts the drafts that are
+// still in plaintext. It runs before AutoMigrate.
+func MigrateDraftPayload(db *gorm.DB) error {
+	var dataType string
+	err := db.Raw(`
+		SELECT data_type FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'ppdb_drafts' AND column_name = 'payload'`).
+		Scan(&dataType).Error
+	if err != nil {
+		return err
+	}
+
+	// Fresh database
+	if dataType == "" {
+		return nil
+	}
+
+	if dataType == "jsonb" {
+		if err := db.Exec(`ALTER TABLE ppdb_drafts ALTER COLUMN payload TYPE text USING payload::text`).Error; err != nil {
+			return err
+		}
+	}
+
+	drafts, err := repository.NewDraftRepository(db).EncryptPlaintext()
+	if err != nil {
+		return fmt.Errorf("encrypting drafts: %w", err)
+	}
+	if drafts > 0 {
+		log.Printf("✅ draft payloads encrypted: %d drafts", drafts)
+	}
+	return nil
+}
+
+// DropPlaintextIndexes removes the indexes on columns that are now
+// encrypted. Ciphertext is random, so they no longer find or prevent
+// anything; the blind index columns are indexed instead.
+func DropPlaintextIndexes(db *gorm.DB) error {
+	return db.Exec(`DROP INDEX IF EXISTS idx_students_nik, idx_students_nisn,
+		idx_parents_no_hp_ortu_wali, idx_parents_father_nik, idx_parents_mother_nik, idx_parents_wali_nik`).Error
+}
+
+// CreateSearchIndexes adds the full-text search vectors of students, parents
+// and posts, and trigram indexes for typo tolerant name search. Vectors use
+// the simple configuration, as Indonesian names should not be stemmed.
+// Encrypted fields cannot be indexed; NIK and NISN are found by blind index.
+func CreateSearchIndexes(db *gorm.DB) error {
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+
+		`ALTER TABLE students ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(full_name, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(asal_sekolah, '')), 'B') ||
+			setweight(to_tsvector('simple', coalesce(tempat_lahir, '') || ' ' || coalesce(kecamatan, '') || ' ' || coalesce(kabupaten, '')), 'C')
+		) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_students_search_vector ON students USING gin (search_vector)`,
+		`CREATE INDEX IF NOT EXISTS idx_students_full_name_trgm ON students USING gin (full_name gin_trgm_ops)`,
+
+		`ALTER TABLE parents ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			to_tsvector('simple', coalesce(father_name, '') || ' ' || coalesce(mother_name, '') || ' ' || coalesce(wali_name, ''))
+		) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_parents_search_vector ON parents USING gin (search_vector)`,
+		`CREATE INDEX IF NOT EXISTS idx_parents_names_trgm ON parents USING gin (
+			(coalesce(father_name, '') || ' ' || coalesce(mother_name, '') || ' ' || coalesce(wali_name, '')) gin_trgm_ops)`,
+
+		`ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(description, '')), 'B') ||
+			setweight(to_tsvector('simple', coalesce(content, '')), 'C')
+		) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_posts_search_vector ON posts USING gin (search_vector)`,
+		`CREATE INDEX IF NOT EXISTS idx_posts_title_trgm ON posts USING gin (title gin_trgm_ops)`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"project_sdu/model"
+)
+
+// BundledRegions is the region dataset shipped with the binary: all provinces
+// plus the regencies and districts around the school. Load the complete
+// Kemendagri dataset with `go run . load-regions <file.csv>`.
+//
+//go:embed data/regions.csv
+var BundledRegions string
+
+// ParseRegionsCSV reads rows of code,name[,postal_code] where code is a dotted
+// Kemendagri code (52, 52.03, 52.03.07, 52.03.07.2001). Level and parent are
+// derived from the code. A header row starting with "code" is skipped.
+func ParseRegionsCSV(r io.Reader) ([]model.Region, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var regions []model.Region
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line++
+
+		if len(record) < 2 || (line == 1 && strings.EqualFold(record[0], "code")) {
+			continue
+		}
+
+		code := strings.TrimSpace(record[0])
+		level, parent, ok := model.RegionLevelOf(code)
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid region code %q", line, code)
+		}
+
+		region := model.Region{
+			Code:  code,
+			Name:  strings.TrimSpace(record[1]),
+			Level: level,
+		}
+		if parent != "" {
+			region.ParentCode = &parent
+		}
+		if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+			postalCode := strings.TrimSpace(record[2])
+			region.PostalCode = &postalCode
+		}
+
+		regions = append(regions, region)
+	}
+
+	return regions, nil
+}
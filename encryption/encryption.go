@@ -0,0 +1,174 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// prefix marks an encrypted value. Values without it are plaintext written
+// before encryption was enabled; they are read as they are and encrypted by
+// the next write or by `go run . rotate-keys`.
+const prefix = "enc:"
+
+var ErrNoKeys = errors.New("FIELD_ENCRYPTION_KEYS and BLIND_INDEX_KEY must be set")
+
+// Keyring holds the AES-256 keys for field encryption. The first key
+// encrypts new values; the others only decrypt values written before a key
+// rotation.
+type Keyring struct {
+	primary  string
+	ciphers  map[string]cipher.AEAD
+	blindKey []byte
+}
+
+var keyring *Keyring
+
+// Load reads the keyring from FIELD_ENCRYPTION_KEYS ("id:base64key,...",
+// newest first) and BLIND_INDEX_KEY (base64). Keys are 32 random bytes, e.g.
+// from `openssl rand -base64 32`.
+func Load() error {
+	k, err := ParseKeyring(os.Getenv("FIELD_ENCRYPTION_KEYS"), os.Getenv("BLIND_INDEX_KEY"))
+	if err != nil {
+		return err
+	}
+	keyring = k
+	return nil
+}
+
+func ParseKeyring(keys string, blindKey string) (*Keyring, error) {
+	if strings.TrimSpace(keys) == "" || strings.TrimSpace(blindKey) == "" {
+		return nil, ErrNoKeys
+	}
+
+	k := &Keyring{ciphers: map[string]cipher.AEAD{}}
+	for _, entry := range strings.Split(keys, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("FIELD_ENCRYPTION_KEYS: expected id:base64key, got %q", entry)
+		}
+		if _, exists := k.ciphers[id]; exists {
+			return nil, fmt.Errorf("FIELD_ENCRYPTION_KEYS: duplicate key id %q", id)
+		}
+
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("FIELD_ENCRYPTION_KEYS: key %q: %w", id, err)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		if k.primary == "" {
+			k.primary = id
+		}
+		k.ciphers[id] = gcm
+	}
+
+	key, err := decodeKey(blindKey)
+	if err != nil {
+		return nil, fmt.Errorf("BLIND_INDEX_KEY: %w", err)
+	}
+	k.blindKey = key
+
+	return k, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// Encrypt seals the value with the primary key as enc:<key id>:<base64>.
+func Encrypt(plaintext string) (string, error) {
+	if keyring == nil {
+		return "", ErrNoKeys
+	}
+
+	gcm := keyring.ciphers[keyring.primary]
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), []byte(keyring.primary))
+	return prefix + keyring.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value written by Encrypt with whichever key sealed it.
+// Plaintext values are returned unchanged.
+func Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, nil
+	}
+	if keyring == nil {
+		return "", ErrNoKeys
+	}
+
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", errors.New("malformed encrypted value")
+	}
+	gcm, ok := keyring.ciphers[id]
+	if !ok {
+		return "", fmt.Errorf("value encrypted with unknown key %q", id)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(id))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// EncryptPtr is Encrypt for optional fields; nil stays nil.
+func EncryptPtr(value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	sealed, err := Encrypt(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
+
+// BlindIndex returns a keyed hash of the value, so encrypted columns can
+// still be matched on equality. Blank values have no index.
+func BlindIndex(value *string) *string {
+	if value == nil || strings.TrimSpace(*value) == "" || keyring == nil {
+		return nil
+	}
+
+	mac := hmac.New(sha256.New, keyring.blindKey)
+	mac.Write([]byte(strings.TrimSpace(*value)))
+	hash := hex.EncodeToString(mac.Sum(nil))
+	return &hash
+}
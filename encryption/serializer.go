@@ -0,0 +1,64 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// Serializer encrypts a string or *string field on write and decrypts it on
+// read. Tag fields with `gorm:"serializer:encrypted"`.
+//
+// gorm only runs serializers for struct writes; map updates must seal the
+// values themselves.
+type Serializer struct{}
+
+func init() {
+	schema.RegisterSerializer("encrypted", Serializer{})
+}
+
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType)
+
+	if dbValue != nil {
+		var value string
+		switch v := dbValue.(type) {
+		case []byte:
+			value = string(v)
+		case string:
+			value = v
+		default:
+			return fmt.Errorf("failed to decrypt %s: unsupported value %#v", field.Name, dbValue)
+		}
+
+		plaintext, err := Decrypt(value)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", field.Name, err)
+		}
+
+		if field.FieldType.Kind() == reflect.Ptr {
+			fieldValue.Elem().Set(reflect.ValueOf(&plaintext))
+		} else {
+			fieldValue.Elem().SetString(plaintext)
+		}
+	}
+
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	switch v := fieldValue.(type) {
+	case string:
+		return Encrypt(v)
+	case *string:
+		if v == nil {
+			return nil, nil
+		}
+		return Encrypt(*v)
+	default:
+		return nil, fmt.Errorf("failed to encrypt %s: unsupported type %T", field.Name, fieldValue)
+	}
+}
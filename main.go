@@ -3,15 +3,19 @@ package main
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"project_sdu/api"
 	"project_sdu/db"
+	"project_sdu/encryption"
 	"project_sdu/middleware"
 	"project_sdu/model"
 	repo "project_sdu/repository"
 	"project_sdu/service"
+	"project_sdu/validation"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -34,6 +38,13 @@ type APIHandler struct {
 	PPDBAPIHandler       api.PPDBAPI
 	RequirementAPIHandler api.RequirementAPI
 	FaqAPIHandler        api.FaqAPI
+	RegionAPIHandler     api.RegionAPI
+	AccessLogAPIHandler  api.AccessLogAPI
+	RetentionAPIHandler  api.RetentionAPI
+	FilterPresetAPIHandler api.FilterPresetAPI
+	SearchAPIHandler     api.SearchAPI
+	ImportAPIHandler     api.ImportAPI
+	ExportAPIHandler     api.ExportAPI
 }
 
 func main() {
@@ -53,14 +64,22 @@ func main() {
 			param.ErrorMessage,
 		)
 	}))
-	router.Use(gin.Recovery())
+	router.Use(gin.CustomRecovery(func(c *gin.Context, err any) {
+		// Handlers panic with http.ErrAbortHandler to cut off a download
+		// that failed halfway, so net/http drops the connection instead of
+		// ending the response as if the file were complete
+		if err == http.ErrAbortHandler {
+			panic(err)
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
 
 	// --- CORS SETUP HERE ---
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{os.Getenv("CORS_ALLOWED_ORIGINS")},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "If-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -72,6 +91,11 @@ func main() {
 		panic("DATABASE_URL tidak ditemukan.")
 	}
 
+	// Keys for the encrypted student and parent fields
+	if err := encryption.Load(); err != nil {
+		panic(err)
+	}
+
 	// Connect to DB
 	database := db.NewDB()
 	conn, err := database.ConnectURL(databaseURL)
@@ -80,15 +104,47 @@ func main() {
 	}
 
 	// Migration
+	if err := db.MigrateTanggalLahir(conn); err != nil {
+		panic(err)
+	}
+	if err := db.DropStudentParentUnique(conn); err != nil {
+		panic(err)
+	}
+	if err := db.MigrateDraftPayload(conn); err != nil {
+		panic(err)
+	}
 	conn.AutoMigrate(
 		&model.User{}, &model.Student{}, &model.Parent{}, &model.Post{}, &model.Curriculum{}, &model.Facility{}, &model.Batch{}, &model.Requirement{}, &model.Faq{},
+		&model.NisSequence{}, &model.Region{}, &model.PPDBDraft{}, &model.SensitiveDataAccessLog{}, &model.RetentionPurgeLog{},
+		&model.FilterPreset{}, &model.ImportJob{},
 	)
+	if err := db.FillBlindIndexes(conn); err != nil {
+		panic(err)
+	}
+	if err := db.DropPlaintextIndexes(conn); err != nil {
+		panic(err)
+	}
+	if err := db.CreateSearchIndexes(conn); err != nil {
+		panic(err)
+	}
 	
 	// Seed
 	SeedRequirements(conn)
 	SeedFaqs(conn)
+	SeedRegions(conn)
+
+	// Maintenance commands, e.g. `go run . load-regions wilayah.csv`
+	if len(os.Args) > 1 {
+		if err := RunCommand(conn, os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
 	// Route
+	if err := validation.RegisterBindings(); err != nil {
+		panic(err)
+	}
 	router = RunServer(router, conn)
 
 	// Get Port
@@ -116,29 +172,60 @@ func RunServer(r *gin.Engine, conn *gorm.DB) *gin.Engine {
 	batchRepo := repo.NewBatchRepository(dbConn)
 	requirementRepo := repo.NewRequirementRepository(dbConn)
 	faqRepo := repo.NewFaqRepository(dbConn)
+	regionRepo := repo.NewRegionRepository(dbConn)
+	draftRepo := repo.NewDraftRepository(dbConn)
+	accessLogRepo := repo.NewAccessLogRepository(dbConn)
+	retentionLogRepo := repo.NewRetentionLogRepository(dbConn)
+	filterPresetRepo := repo.NewFilterPresetRepository(dbConn)
+	searchRepo := repo.NewSearchRepository(dbConn)
+	importJobRepo := repo.NewImportJobRepository(dbConn)
+	uow := repo.NewUnitOfWork(dbConn)
 
 	userService := service.NewUserService(userRepo)
-	studentService := service.NewStudentService(studentRepo, parentRepo, batchRepo)
-	parentService := service.NewParentService(parentRepo)
+	studentService := service.NewStudentService(studentRepo, parentRepo, batchRepo, regionRepo, uow)
+	parentService := service.NewParentService(parentRepo, uow)
 	postService := service.NewPostService(postRepo)
 	curriculumService := service.NewCurriculumService(curriculumRepo)
 	facilityService := service.NewfacilityService(facilityRepo)
-	batchService := service.NewBatchService(batchRepo)
+	batchService := service.NewBatchService(batchRepo, studentRepo, uow)
 	dashboardService := service.NewDashboardService(studentRepo, postRepo, batchRepo)
 	requirementService := service.NewRequirementService(requirementRepo)
 	faqService := service.NewFaqService(faqRepo)
+	regionService := service.NewRegionService(regionRepo)
+	draftService := service.NewDraftService(draftRepo, regionRepo, studentService)
+	trashService := service.NewTrashService(studentRepo, parentRepo, batchRepo, postRepo)
+	accessLogService := service.NewAccessLogService(accessLogRepo)
+	privacyService := service.NewPrivacyService(studentRepo, accessLogRepo, uow)
+	retentionService := service.NewRetentionService(studentRepo, draftRepo, retentionLogRepo, privacyService, uow)
+	filterPresetService := service.NewFilterPresetService(filterPresetRepo)
+	searchService := service.NewSearchService(searchRepo)
+	importService := service.NewImportService(importJobRepo, studentService)
+	exportService := service.NewExportService(studentRepo)
+
+	go purgeTrashDaily(trashService)
+	go applyRetentionDaily(retentionService)
+	if err := importService.FailInterrupted(); err != nil {
+		log.Println("Failed to fail interrupted import jobs:", err)
+	}
 
 	userAPIHandler := api.NewUserAPI(userService)
-	studentAPIHandler := api.NewStudentAPI(studentService)
+	studentAPIHandler := api.NewStudentAPI(studentService, privacyService, accessLogService)
 	parentAPIHandler := api.NewParentAPI(parentService)
 	postAPIHandler := api.NewPostAPI(postService)
 	curriculumAPIHandler := api.NewCurriculumAPI(curriculumService)
 	facilityAPIHandler := api.NewFacilityAPI(facilityService)
 	batchAPIHandler := api.NewBatchAPI(batchService)
 	dashboardAPIHanlder := api.NewDashboardAPI(dashboardService)
-	ppdbAPIHandler := api.NewPPDBAPI(studentService)
+	ppdbAPIHandler := api.NewPPDBAPI(studentService, draftService)
 	requirementAPIHandler := api.NewRequirementAPI(requirementService)
 	faqAPIHandler := api.NewFaqAPI(faqService)
+	regionAPIHandler := api.NewRegionAPI(regionService)
+	accessLogAPIHandler := api.NewAccessLogAPI(accessLogService)
+	retentionAPIHandler := api.NewRetentionAPI(retentionService)
+	filterPresetAPIHandler := api.NewFilterPresetAPI(filterPresetService)
+	searchAPIHandler := api.NewSearchAPI(searchService)
+	importAPIHandler := api.NewImportAPI(importService)
+	exportAPIHandler := api.NewExportAPI(exportService, accessLogService)
 
 	apiHandler := APIHandler{
 		UserAPIHandler:       userAPIHandler,
@@ -152,6 +239,13 @@ func RunServer(r *gin.Engine, conn *gorm.DB) *gin.Engine {
 		PPDBAPIHandler:       ppdbAPIHandler,
 		RequirementAPIHandler: requirementAPIHandler,
 		FaqAPIHandler:        faqAPIHandler,
+		RegionAPIHandler:     regionAPIHandler,
+		AccessLogAPIHandler:  accessLogAPIHandler,
+		RetentionAPIHandler:  retentionAPIHandler,
+		FilterPresetAPIHandler: filterPresetAPIHandler,
+		SearchAPIHandler:     searchAPIHandler,
+		ImportAPIHandler:     importAPIHandler,
+		ExportAPIHandler:     exportAPIHandler,
 	}
 
 	// ROUTES //
@@ -163,7 +257,7 @@ func RunServer(r *gin.Engine, conn *gorm.DB) *gin.Engine {
 		user.POST("/login", apiHandler.UserAPIHandler.Login)
 		user.POST("/logout", apiHandler.UserAPIHandler.Logout)
 
-		user.Use(middleware.Auth())
+		user.Use(middleware.Auth(userRepo))
 		user.GET("/profile", apiHandler.UserAPIHandler.GetUserProfile)
 	}
 
@@ -171,18 +265,55 @@ func RunServer(r *gin.Engine, conn *gorm.DB) *gin.Engine {
 	ppdb := r.Group("/ppdb")
 	{
 		ppdb.POST("/add", apiHandler.PPDBAPIHandler.Register)
+
+		// Multi-step form, resumable with the draft token
+		ppdb.POST("/draft", apiHandler.PPDBAPIHandler.CreateDraft)
+		ppdb.GET("/draft/:token", apiHandler.PPDBAPIHandler.GetDraft)
+		ppdb.PUT("/draft/:token/:step", apiHandler.PPDBAPIHandler.SaveDraftStep)
+		ppdb.POST("/draft/:token/submit", apiHandler.PPDBAPIHandler.SubmitDraft)
 	}
 
 	// Student routes
 	student := r.Group("/student")
 	{
-		student.Use(middleware.Auth())
+		student.Use(middleware.Auth(userRepo))
 		student.POST("/add", apiHandler.StudentAPIHandler.CreateStudent)
 		student.POST("/bulk-add", apiHandler.StudentAPIHandler.CreateManyStudents)
 		student.GET("/get/:id", apiHandler.StudentAPIHandler.GetStudentByID)
 		student.GET("/get-all", apiHandler.StudentAPIHandler.GetAllStudents)
 		student.PUT("/update/:id", apiHandler.StudentAPIHandler.UpdateStudent)
+		student.PATCH("/update/:id", apiHandler.StudentAPIHandler.PatchStudent)
 		student.DELETE("/delete/:id", apiHandler.StudentAPIHandler.DeleteStudent)
+		student.GET("/delete-preview/:id", apiHandler.StudentAPIHandler.PreviewDeleteStudent)
+		student.GET("/trash", apiHandler.StudentAPIHandler.GetTrashedStudents)
+		student.POST("/restore/:id", middleware.RequireRole(model.RoleSuperAdmin), apiHandler.StudentAPIHandler.RestoreStudent)
+		student.DELETE("/purge/:id", middleware.RequireRole(model.RoleSuperAdmin), apiHandler.StudentAPIHandler.PurgeStudent)
+		student.POST("/enroll", apiHandler.StudentAPIHandler.EnrollStudents)
+		student.GET("/enrolled/get-all", apiHandler.StudentAPIHandler.GetEnrolledStudents)
+		student.GET("/age-overrides", apiHandler.StudentAPIHandler.GetAgeOverrides)
+		student.GET("/ranking/zonasi", apiHandler.StudentAPIHandler.GetZonasiRanking)
+		student.GET("/duplicates", apiHandler.StudentAPIHandler.GetDuplicates)
+		student.GET("/export/:id", middleware.RequireRole(model.RoleSuperAdmin), apiHandler.StudentAPIHandler.ExportStudent)
+		student.POST("/anonymize/:id", middleware.RequireRole(model.RoleSuperAdmin), apiHandler.StudentAPIHandler.AnonymizeStudent)
+		student.POST("/merge", apiHandler.StudentAPIHandler.MergeStudents)
+
+		// Saved applicant list filters of the logged-in admin
+		student.GET("/filter-presets", apiHandler.FilterPresetAPIHandler.GetAll)
+		student.POST("/filter-presets", apiHandler.FilterPresetAPIHandler.Save)
+		student.DELETE("/filter-presets/:id", apiHandler.FilterPresetAPIHandler.Delete)
+
+		// Import of applicants from CSV or XLSX, processed in the background
+		student.GET("/import/template", apiHandler.ImportAPIHandler.Template)
+		student.POST("/import", apiHandler.ImportAPIHandler.Start)
+		student.GET("/import/jobs", apiHandler.ImportAPIHandler.GetJobs)
+		student.GET("/import/jobs/:id", apiHandler.ImportAPIHandler.GetJob)
+
+		// Export of the applicant list, with the filters of /get-all
+		student.GET("/export", apiHandler.ExportAPIHandler.ExportStudents)
+
+		// Accepted students in the layout of the Dapodik import
+		student.GET("/export/dapodik", middleware.RequireRole(model.RoleSuperAdmin), apiHandler.ExportAPIHandler.ExportDapodik)
+		student.GET("/export/dapodik/report", apiHandler.ExportAPIHandler.DapodikReport)
 
 
 
@@ -191,12 +322,19 @@ func RunServer(r *gin.Engine, conn *gorm.DB) *gin.Engine {
 	// Parent routes
 	parent := r.Group("/parent")
 	{
-		parent.Use(middleware.Auth())
+		parent.Use(middleware.Auth(userRepo))
 		parent.POST("/add", apiHandler.ParentAPIHandler.CreateParent)
 		parent.GET("/get-all", apiHandler.ParentAPIHandler.GetAllParents)
 		parent.GET("/get/:id", apiHandler.ParentAPIHandler.GetParentByID)
 		parent.PUT("/update/:id", apiHandler.ParentAPIHandler.UpdateParent)
+		parent.PATCH("/update/:id", apiHandler.ParentAPIHandler.PatchParent)
 		parent.DELETE("/delete/:id", apiHandler.ParentAPIHandler.DeleteParent)
+		parent.GET("/delete-preview/:id", apiHandler.ParentAPIHandler.PreviewDeleteParent)
+		parent.GET("/trash", apiHandler.ParentAPIHandler.GetTrashedParents)
+		parent.POST("/restore/:id", middleware.RequireRole(model.RoleSuperAdmin), apiHandler.ParentAPIHandler.RestoreParent)
+		parent.DELETE("/purge/:id", middleware.RequireRole(model.RoleSuperAdmin), apiHandler.ParentAPIHandler.PurgeParent)
+		parent.GET("/duplicates", apiHandler.ParentAPIHandler.GetDuplicates)
+		parent.POST("/merge", apiHandler.ParentAPIHandler.MergeParents)
 	}
 
 	// Post routes
@@ -205,10 +343,14 @@ func RunServer(r *gin.Engine, conn *gorm.DB) *gin.Engine {
 		post.GET("/get/:slug", apiHandler.PostAPIHandler.GetPostBySlug)
 		post.GET("/get-all", apiHandler.PostAPIHandler.GetAllPosts)
 
-		post.Use(middleware.Auth())
+		post.Use(middleware.Auth(userRepo))
 		post.POST("/add", apiHandler.PostAPIHandler.CreatePost)
 		post.PUT("/update/:slug", apiHandler.PostAPIHandler.UpdatePost)
+		post.PATCH("/update/:slug", apiHandler.PostAPIHandler.PatchPost)
 		post.DELETE("/delete/:slug", apiHandler.PostAPIHandler.DeletePost)
+		post.GET("/trash", apiHandler.PostAPIHandler.GetTrashedPosts)
+		post.POST("/restore/:slug", middleware.RequireRole(model.RoleSuperAdmin), apiHandler.PostAPIHandler.RestorePost)
+		post.DELETE("/purge/:slug", middleware.RequireRole(model.RoleSuperAdmin), apiHandler.PostAPIHandler.PurgePost)
 	}
 
 	// Curriculum routes
@@ -218,9 +360,10 @@ func RunServer(r *gin.Engine, conn *gorm.DB) *gin.Engine {
 		extracurricular.GET("/get/:id", apiHandler.CurriculumAPIHandler.GetByID)
 		extracurricular.GET("/category/:category", apiHandler.CurriculumAPIHandler.GetByCategory)
 
-		extracurricular.Use(middleware.Auth())
+		extracurricular.Use(middleware.Auth(userRepo))
 		extracurricular.POST("/add", apiHandler.CurriculumAPIHandler.Create)
 		extracurricular.PUT("/update/:id", apiHandler.CurriculumAPIHandler.Update)
+		extracurricular.PATCH("/update/:id", apiHandler.CurriculumAPIHandler.Patch)
 		extracurricular.DELETE("/delete/:id", apiHandler.CurriculumAPIHandler.Delete)
 	}
 
@@ -230,9 +373,10 @@ func RunServer(r *gin.Engine, conn *gorm.DB) *gin.Engine {
 		facility.GET("/get-all", apiHandler.FacilityAPIHandler.GetAllFacilities)
 		facility.GET("/get/:id", apiHandler.FacilityAPIHandler.GetFacilityByID)
 
-		facility.Use(middleware.Auth())
+		facility.Use(middleware.Auth(userRepo))
 		facility.POST("/add", apiHandler.FacilityAPIHandler.CreateFacility)
 		facility.PUT("/update/:id", apiHandler.FacilityAPIHandler.UpdateFacility)
+		facility.PATCH("/update/:id", apiHandler.FacilityAPIHandler.PatchFacility)
 		facility.DELETE("/delete/:id", apiHandler.FacilityAPIHandler.DeleteFacility)
 	}
 
@@ -240,17 +384,22 @@ func RunServer(r *gin.Engine, conn *gorm.DB) *gin.Engine {
 	batch := r.Group("/batch")
 	{
 		batch.GET("/get-active", apiHandler.BatchAPIHandler.GetActiveBatch)
-		batch.Use(middleware.Auth())
+		batch.Use(middleware.Auth(userRepo))
 		batch.GET("/get-all", apiHandler.BatchAPIHandler.GetAll)
 		batch.GET("/get/:id", apiHandler.BatchAPIHandler.GetByID)
 		batch.POST("/add", apiHandler.BatchAPIHandler.Create)
 		batch.PUT("/update/:id", apiHandler.BatchAPIHandler.Update)
+		batch.PATCH("/update/:id", apiHandler.BatchAPIHandler.Patch)
 		batch.DELETE("/delete/:id", apiHandler.BatchAPIHandler.Delete)
+		batch.GET("/delete-preview/:id", apiHandler.BatchAPIHandler.PreviewDelete)
+		batch.GET("/trash", apiHandler.BatchAPIHandler.GetTrash)
+		batch.POST("/restore/:id", middleware.RequireRole(model.RoleSuperAdmin), apiHandler.BatchAPIHandler.Restore)
+		batch.DELETE("/purge/:id", middleware.RequireRole(model.RoleSuperAdmin), apiHandler.BatchAPIHandler.Purge)
 	}
 
 	dashboard := r.Group("/dashboard")
 	{
-		dashboard.Use(middleware.Auth())
+		dashboard.Use(middleware.Auth(userRepo))
 		dashboard.GET("/", apiHandler.DashboardAPIHanlder.GetDashboard)
 	}
 
@@ -260,9 +409,10 @@ func RunServer(r *gin.Engine, conn *gorm.DB) *gin.Engine {
 		requirement.GET("/get-all", apiHandler.RequirementAPIHandler.GetAll)
 		requirement.GET("/get/:id", apiHandler.RequirementAPIHandler.GetByID)
 
-		requirement.Use(middleware.Auth())
+		requirement.Use(middleware.Auth(userRepo))
 		requirement.POST("/add", apiHandler.RequirementAPIHandler.Create)
 		requirement.PUT("/update/:id", apiHandler.RequirementAPIHandler.Update)
+		requirement.PATCH("/update/:id", apiHandler.RequirementAPIHandler.Patch)
 		requirement.DELETE("/delete/:id", apiHandler.RequirementAPIHandler.Delete)
 	}
 
@@ -272,12 +422,44 @@ func RunServer(r *gin.Engine, conn *gorm.DB) *gin.Engine {
 		faq.GET("/get-all", apiHandler.FaqAPIHandler.GetAll)
 		faq.GET("/get/:id", apiHandler.FaqAPIHandler.GetByID)
 
-		faq.Use(middleware.Auth())
+		faq.Use(middleware.Auth(userRepo))
 		faq.POST("/add", apiHandler.FaqAPIHandler.Create)
 		faq.PUT("/update/:id", apiHandler.FaqAPIHandler.Update)
+		faq.PATCH("/update/:id", apiHandler.FaqAPIHandler.Patch)
 		faq.DELETE("/delete/:id", apiHandler.FaqAPIHandler.Delete)
 	}
 
+	// Region routes (public, for cascading address dropdowns)
+	region := r.Group("/region")
+	{
+		region.GET("/provinces", apiHandler.RegionAPIHandler.GetProvinces)
+		region.GET("/children/:code", apiHandler.RegionAPIHandler.GetChildren)
+		region.GET("/get/:code", apiHandler.RegionAPIHandler.GetByCode)
+	}
+
+	// Log of unmasked views of sensitive student data
+	accessLog := r.Group("/access-log")
+	{
+		accessLog.Use(middleware.Auth(userRepo), middleware.RequireRole(model.RoleSuperAdmin))
+		accessLog.GET("/get-all", apiHandler.AccessLogAPIHandler.GetAll)
+	}
+
+	// Retention of applicant data after the batch ended
+	retention := r.Group("/retention")
+	{
+		retention.Use(middleware.Auth(userRepo), middleware.RequireRole(model.RoleSuperAdmin))
+		retention.GET("/preview", apiHandler.RetentionAPIHandler.Preview)
+		retention.POST("/apply", apiHandler.RetentionAPIHandler.Apply)
+		retention.GET("/log", apiHandler.RetentionAPIHandler.GetLog)
+	}
+
+	// Admin search across students, parents and posts
+	search := r.Group("/search")
+	{
+		search.Use(middleware.Auth(userRepo))
+		search.GET("", apiHandler.SearchAPIHandler.Search)
+	}
+
 	return r
 }
 
@@ -320,3 +502,52 @@ func SeedFaqs(db *gorm.DB) {
 	}
 	fmt.Println("✅ Default faqs seeded")
 }
+
+func SeedRegions(conn *gorm.DB) {
+	var count int64
+	conn.Model(&model.Region{}).Count(&count)
+	if count > 0 {
+		return
+	}
+
+	regions, err := db.ParseRegionsCSV(strings.NewReader(db.BundledRegions))
+	if err != nil {
+		log.Println("Failed to parse bundled regions:", err)
+		return
+	}
+
+	if err := repo.NewRegionRepository(conn).Upsert(regions); err != nil {
+		log.Println("Failed to seed regions:", err)
+		return
+	}
+	fmt.Println("✅ Default regions seeded")
+}
+
+// purgeTrashDaily permanently deletes records trashed longer than
+// TRASH_RETENTION_DAYS, once at start and then every day.
+func purgeTrashDaily(trashService service.TrashService) {
+	for {
+		result, err := trashService.PurgeExpired()
+		if err != nil {
+			log.Println("Failed to purge trash:", err)
+		} else if result.Students+result.Parents+result.Batches+result.Posts > 0 {
+			log.Printf("Trash purged: %d students, %d parents, %d batches, %d posts\n",
+				result.Students, result.Parents, result.Batches, result.Posts)
+		}
+		time.Sleep(24 * time.Hour)
+	}
+}
+
+// applyRetentionDaily purges applicant data past RETENTION_RULES, once at
+// start and then every day.
+func applyRetentionDaily(retentionService service.RetentionService) {
+	for {
+		result, err := retentionService.Apply()
+		if err != nil {
+			log.Println("Failed to apply retention:", err)
+		} else if result.Purged > 0 || len(result.Failed) > 0 {
+			log.Printf("Retention applied: %d purged, %d failed\n", result.Purged, len(result.Failed))
+		}
+		time.Sleep(24 * time.Hour)
+	}
+}
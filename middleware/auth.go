@@ -1,14 +1,19 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"project_sdu/model"
+	"project_sdu/repository"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
+	"gorm.io/gorm"
 )
 
-func Auth() gin.HandlerFunc {
+// Auth checks the session cookie and loads the user's current role from the
+// users table, so a role change applies to tokens that were already issued.
+func Auth(users repository.UserRepository) gin.HandlerFunc {
 	return gin.HandlerFunc(func(ctx *gin.Context) {
 		cookie, err := ctx.Cookie("session_token")
 		if err != nil {
@@ -45,8 +50,37 @@ func Auth() gin.HandlerFunc {
 			return
 		}
 
+		user, err := users.GetUserByID(claims.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			} else {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			}
+			ctx.Abort()
+			return
+		}
+
 		ctx.Set("id", claims.UserID)
+		ctx.Set("role", user.Role)
 
 		ctx.Next()
 	})
 }
+
+// RequireRole lets through only users who currently have one of roles. It
+// must run after Auth.
+func RequireRole(roles ...model.UserRole) gin.HandlerFunc {
+	return gin.HandlerFunc(func(ctx *gin.Context) {
+		role, _ := ctx.Get("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				ctx.Next()
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		ctx.Abort()
+	})
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+var ErrInvalidDate = errors.New("format tanggal tidak valid, gunakan YYYY-MM-DD atau DD-MM-YYYY")
+
+// dateLayouts are the input formats accepted for dates typed in by families.
+var dateLayouts = []string{
+	DateLayout,
+	"02-01-2006",
+	"2-1-2006",
+	"02/01/2006",
+	"2/1/2006",
+	"2 January 2006",
+	time.RFC3339,
+}
+
+var indonesianMonths = strings.NewReplacer(
+	"Januari", "January", "Februari", "February", "Maret", "March",
+	"Mei", "May", "Juni", "June", "Juli", "July", "Agustus", "August",
+	"Oktober", "October", "Desember", "December",
+)
+
+// Date is a calendar date without time of day, stored as a Postgres date.
+type Date struct {
+	time.Time
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate parses a date in any of the accepted input formats, including
+// Indonesian month names such as "17 Agustus 2010".
+func ParseDate(value string) (Date, error) {
+	value = indonesianMonths.Replace(strings.TrimSpace(value))
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return NewDate(t.Year(), t.Month(), t.Day()), nil
+		}
+	}
+	return Date{}, ErrInvalidDate
+}
+
+// AgeAt returns the age in completed years on the given reference date.
+func (d Date) AgeAt(ref time.Time) int {
+	age := ref.Year() - d.Year()
+	if ref.Month() < d.Month() || (ref.Month() == d.Month() && ref.Day() < d.Day()) {
+		age--
+	}
+	return age
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + d.Format(DateLayout) + `"`), nil
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "" || value == "null" {
+		*d = Date{}
+		return nil
+	}
+
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.Format(DateLayout), nil
+}
+
+func (d *Date) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d = Date{}
+	case time.Time:
+		*d = NewDate(v.Year(), v.Month(), v.Day())
+	case string:
+		parsed, err := ParseDate(v)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+	default:
+		return fmt.Errorf("cannot scan %T into Date", value)
+	}
+	return nil
+}
+
+func (Date) GormDataType() string {
+	return "date"
+}
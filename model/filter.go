@@ -0,0 +1,198 @@
+package model
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ======================
+// STUDENT FILTER
+// ======================
+
+// StudentSortFields are the fields the applicant list can be sorted by.
+var StudentSortFields = []string{
+	"full_name", "created_at", "tanggal_lahir", "asal_sekolah", "distance_km", "kabupaten", "kecamatan",
+}
+
+// DefaultStudentSort orders applicants by name.
+var DefaultStudentSort = []SortField{{Field: "full_name"}}
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// StudentFilter narrows the applicant list. Nil and blank fields do not
+// filter.
+type StudentFilter struct {
+	Q             string
+	BatchID       *int
+	IsAccepted    *bool
+	MaxDistanceKm *float64
+
+	Gender        *Gender
+	Agama         *Religion
+	AsalSekolah   string
+	KabupatenKode string
+	KecamatanKode string
+	Jalur         string // of the applicant's batch
+
+	// Registration date range, both days included
+	RegisteredFrom *Date
+	RegisteredTo   *Date
+
+	// Whether photo, KK, akta kelahiran and ijazah/SKL are all uploaded
+	DocumentsComplete *bool
+
+	// Age today in completed years, both bounds included
+	MinAge *int
+	MaxAge *int
+
+	Sort []SortField
+}
+
+// ParseStudentFilter reads the applicant list filters from query values,
+// e.g. gender=FEMALE&kabupaten_kode=52.03&sort=-created_at,full_name. It
+// returns the errors by parameter, or nil.
+func ParseStudentFilter(query url.Values) (StudentFilter, map[string]string) {
+	filter := StudentFilter{
+		Q:             strings.TrimSpace(query.Get("q")),
+		AsalSekolah:   strings.TrimSpace(query.Get("asal_sekolah")),
+		KabupatenKode: strings.TrimSpace(query.Get("kabupaten_kode")),
+		KecamatanKode: strings.TrimSpace(query.Get("kecamatan_kode")),
+		Jalur:         strings.TrimSpace(query.Get("jalur")),
+		Sort:          DefaultStudentSort,
+	}
+	errs := map[string]string{}
+
+	if value := query.Get("batch"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			errs["batch"] = "batch must be a batch id"
+		} else if n != 0 {
+			filter.BatchID = &n
+		}
+	}
+
+	filter.IsAccepted = parseBoolParam(query, "is_accepted", errs)
+	filter.DocumentsComplete = parseBoolParam(query, "documents_complete", errs)
+
+	if value := query.Get("max_distance_km"); value != "" {
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || n < 0 {
+			errs["max_distance_km"] = "max_distance_km must be a positive number"
+		} else {
+			filter.MaxDistanceKm = &n
+		}
+	}
+
+	if value := query.Get("gender"); value != "" {
+		gender := Gender(strings.ToUpper(value))
+		if !gender.IsValid() {
+			errs["gender"] = "gender must be MALE or FEMALE"
+		} else {
+			filter.Gender = &gender
+		}
+	}
+
+	if value := query.Get("agama"); value != "" {
+		agama := Religion(strings.ToUpper(value))
+		if !agama.IsValid() {
+			errs["agama"] = "agama must be ISLAM, CHRISTIAN, CATHOLIC, HINDU, BUDDHA or KONGHUCU"
+		} else {
+			filter.Agama = &agama
+		}
+	}
+
+	filter.RegisteredFrom = parseDateParam(query, "registered_from", errs)
+	filter.RegisteredTo = parseDateParam(query, "registered_to", errs)
+	if filter.RegisteredFrom != nil && filter.RegisteredTo != nil && filter.RegisteredTo.Before(filter.RegisteredFrom.Time) {
+		errs["registered_to"] = "registered_to must not be before registered_from"
+	}
+
+	filter.MinAge = parseAgeParam(query, "min_age", errs)
+	filter.MaxAge = parseAgeParam(query, "max_age", errs)
+	if filter.MinAge != nil && filter.MaxAge != nil && *filter.MaxAge < *filter.MinAge {
+		errs["max_age"] = "max_age must not be below min_age"
+	}
+
+	if value := query.Get("sort"); value != "" {
+		sort, err := parseSort(value, StudentSortFields)
+		if err != "" {
+			errs["sort"] = err
+		} else {
+			filter.Sort = sort
+		}
+	}
+
+	if len(errs) > 0 {
+		return filter, errs
+	}
+	return filter, nil
+}
+
+func parseBoolParam(query url.Values, name string, errs map[string]string) *bool {
+	value := query.Get(name)
+	if value == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		errs[name] = name + " must be true or false"
+		return nil
+	}
+	return &b
+}
+
+func parseDateParam(query url.Values, name string, errs map[string]string) *Date {
+	value := query.Get(name)
+	if value == "" {
+		return nil
+	}
+	d, err := ParseDate(value)
+	if err != nil {
+		errs[name] = name + " must be a date (YYYY-MM-DD)"
+		return nil
+	}
+	return &d
+}
+
+func parseAgeParam(query url.Values, name string, errs map[string]string) *int {
+	value := query.Get(name)
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		errs[name] = name + " must be zero or a positive number"
+		return nil
+	}
+	return &n
+}
+
+// parseSort reads a comma-separated list of fields, each descending when
+// prefixed with "-", e.g. "-created_at,full_name".
+func parseSort(value string, allowed []string) ([]SortField, string) {
+	var sort []SortField
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+
+		valid := false
+		for _, name := range allowed {
+			valid = valid || name == field.Field
+		}
+		if !valid {
+			return nil, "sort by one of " + strings.Join(allowed, ", ")
+		}
+		if seen[field.Field] {
+			return nil, field.Field + " is sorted by more than once"
+		}
+		seen[field.Field] = true
+
+		sort = append(sort, field)
+	}
+	return sort, ""
+}
@@ -1,8 +1,11 @@
 package model
 
 import (
+	"project_sdu/encryption"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -14,14 +17,65 @@ type User struct {
 	ID        int       `gorm:"primaryKey" json:"id"`
 	Fullname  string    `json:"fullname" gorm:"type:varchar(255);"`
 	Email     string    `json:"email" gorm:"type:varchar(255);not null"`
-	Password  string    `json:"password" gorm:"type:varchar(255);not null"`
+	Password  string    `json:"-" gorm:"type:varchar(255);not null"` // bcrypt hash, never serialized
+	Role      UserRole  `json:"role" gorm:"type:varchar(20);not null;default:ADMIN"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type UserRole string
+
+const (
+	RoleSuperAdmin UserRole = "SUPER_ADMIN"
+	RoleAdmin      UserRole = "ADMIN"
+)
+
+func (r UserRole) IsValid() bool {
+	switch r {
+	case RoleSuperAdmin, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// Permission is something only some roles may do.
+type Permission string
+
+const (
+	// PermissionViewSensitiveData allows viewing a student with NIK, NISN,
+	// phone numbers, health data and family income unmasked.
+	PermissionViewSensitiveData Permission = "VIEW_SENSITIVE_DATA"
+)
+
+var rolePermissions = map[UserRole][]Permission{
+	RoleSuperAdmin: {PermissionViewSensitiveData},
+}
+
+func (r UserRole) Can(p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// SensitiveDataAccessLog records every time someone viewed a record with its
+// sensitive fields unmasked.
+type SensitiveDataAccessLog struct {
+	ID         int       `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+	UserID     int       `gorm:"index" json:"user_id"`
+	Resource   string    `gorm:"type:varchar(50);index:idx_access_logs_resource" json:"resource"` // e.g. "student"
+	Action     string    `gorm:"type:varchar(20)" json:"action"`                                  // "view" or "export"
+	ResourceID int       `gorm:"index:idx_access_logs_resource" json:"resource_id"`
+	IPAddress  string    `gorm:"type:varchar(64)" json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+}
+
 type UserRegister struct {
-	Fullname string `json:"fullname" binding:"required"`
-	Email    string `json:"email" binding:"required"`
+	Fullname string `json:"fullname" binding:"required,notblank"`
+	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
 }
 
@@ -89,6 +143,7 @@ type ErrorResponse struct {
 	Status  int               `json:"status"`
 	Message string            `json:"message"`
 	Errors  map[string]string `json:"errors,omitempty"`
+	Data    interface{}       `json:"data,omitempty"`
 }
 
 // ======================
@@ -96,33 +151,79 @@ type ErrorResponse struct {
 // ======================
 
 type Parent struct {
-	ID        int       `gorm:"primaryKey" json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int            `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	Version   int            `gorm:"not null;default:1" json:"version"`
 
 	FatherName      *string `json:"father_name"`
 	FatherEducation *string `json:"father_education"`
 	FatherJob       *string `json:"father_job"`
-	FatherIncome    *string `json:"father_income"`
+	FatherIncome    *string `gorm:"serializer:encrypted" json:"father_income"`
 
 	MotherName      *string `json:"mother_name"`
 	MotherEducation *string `json:"mother_education"`
 	MotherJob       *string `json:"mother_job"`
-	MotherIncome    *string `json:"mother_income"`
+	MotherIncome    *string `gorm:"serializer:encrypted" json:"mother_income"`
 
-	ParentEmail *string `json:"parent_email"`
+	ParentEmail *string `gorm:"index" json:"parent_email"`
 
 	WaliName       *string `json:"wali_name"`
 	AlamatOrtuWali *string `json:"alamat_ortu_wali"`
-	NoHpOrtuWali   *string `json:"no_hp_ortu_wali"`
+	NoHpOrtuWali   *string `gorm:"serializer:encrypted" json:"no_hp_ortu_wali"`
+
+	// Used together with phone and email to recognise a returning family
+	FatherNik *string `gorm:"serializer:encrypted" json:"father_nik"`
+	MotherNik *string `gorm:"serializer:encrypted" json:"mother_nik"`
+	WaliNik   *string `gorm:"serializer:encrypted" json:"wali_nik"`
+
+	// Blind indexes of the encrypted phone and NIKs, for matching families
+	NoHpOrtuWaliHash *string `gorm:"index" json:"-"`
+	FatherNikHash    *string `gorm:"index" json:"-"`
+	MotherNikHash    *string `gorm:"index" json:"-"`
+	WaliNikHash      *string `gorm:"index" json:"-"`
+
+	// Set when the personal fields were scrubbed for a UU PDP erasure request
+	AnonymizedAt *time.Time `json:"anonymized_at"`
 
-	Student *Student `json:"student"`
+	Students []Student `json:"students,omitempty"`
+}
+
+// SetBlindIndexes derives the blind indexes from the plaintext phone and
+// NIKs. Repositories call it before every struct write.
+func (p *Parent) SetBlindIndexes() {
+	p.NoHpOrtuWaliHash = encryption.BlindIndex(p.NoHpOrtuWali)
+	p.FatherNikHash = encryption.BlindIndex(p.FatherNik)
+	p.MotherNikHash = encryption.BlindIndex(p.MotherNik)
+	p.WaliNikHash = encryption.BlindIndex(p.WaliNik)
+}
+
+// ParentDuplicateGroup is a set of parent rows sharing a phone, email or NIK.
+type ParentDuplicateGroup struct {
+	Field     string `json:"field"`
+	Value     string `json:"value"`
+	ParentIDs []int  `json:"parent_ids"`
+}
+
+type ParentMergeRequest struct {
+	TargetID  int   `json:"target_id" binding:"required"`
+	SourceIDs []int `json:"source_ids" binding:"required"`
 }
 
 // ======================
 // STUDENT
 // ======================
 
+// enumLabel is the label of an enum value, or the value itself when it has
+// no label.
+func enumLabel[T ~string](labels map[T]string, value T) string {
+	if label, ok := labels[value]; ok {
+		return label
+	}
+	return string(value)
+}
+
 type Gender string
 
 const (
@@ -130,6 +231,24 @@ const (
 	Female Gender = "FEMALE"
 )
 
+func (g Gender) IsValid() bool {
+	switch g {
+	case Male, Female:
+		return true
+	}
+	return false
+}
+
+var genderLabels = map[Gender]string{
+	Male:   "Laki-laki",
+	Female: "Perempuan",
+}
+
+// Label is the Indonesian name of the value, as shown in exports.
+func (g Gender) Label() string {
+	return enumLabel(genderLabels, g)
+}
+
 type BloodType string
 
 const (
@@ -140,6 +259,26 @@ const (
 	BloodUnknown BloodType = "UNKNOWN"
 )
 
+func (b BloodType) IsValid() bool {
+	switch b {
+	case BloodA, BloodB, BloodAB, BloodO, BloodUnknown:
+		return true
+	}
+	return false
+}
+
+var bloodTypeLabels = map[BloodType]string{
+	BloodA:       "A",
+	BloodB:       "B",
+	BloodAB:      "AB",
+	BloodO:       "O",
+	BloodUnknown: "Tidak tahu",
+}
+
+func (b BloodType) Label() string {
+	return enumLabel(bloodTypeLabels, b)
+}
+
 type TinggalBersama string
 
 const (
@@ -152,6 +291,28 @@ const (
 	Lainnya        TinggalBersama = "LAINNYA"
 )
 
+func (t TinggalBersama) IsValid() bool {
+	switch t {
+	case OrangTua, KakekNenek, PamanBibi, SaudaraKandung, Kerabat, PantiPontRen, Lainnya:
+		return true
+	}
+	return false
+}
+
+var tinggalBersamaLabels = map[TinggalBersama]string{
+	OrangTua:       "Orang tua",
+	KakekNenek:     "Kakek/nenek",
+	PamanBibi:      "Paman/bibi",
+	SaudaraKandung: "Saudara kandung",
+	Kerabat:        "Kerabat",
+	PantiPontRen:   "Panti asuhan/pondok pesantren",
+	Lainnya:        "Lainnya",
+}
+
+func (t TinggalBersama) Label() string {
+	return enumLabel(tinggalBersamaLabels, t)
+}
+
 type StatusKeluarga string
 
 const (
@@ -160,6 +321,24 @@ const (
 	AnakAngkat  StatusKeluarga = "ANAK_ANGKAT"
 )
 
+func (s StatusKeluarga) IsValid() bool {
+	switch s {
+	case AnakKandung, AnakTiri, AnakAngkat:
+		return true
+	}
+	return false
+}
+
+var statusKeluargaLabels = map[StatusKeluarga]string{
+	AnakKandung: "Anak kandung",
+	AnakTiri:    "Anak tiri",
+	AnakAngkat:  "Anak angkat",
+}
+
+func (s StatusKeluarga) Label() string {
+	return enumLabel(statusKeluargaLabels, s)
+}
+
 type KeadaanOrtu string
 
 const (
@@ -169,6 +348,25 @@ const (
 	YatimPiatu KeadaanOrtu = "YATIM_PIATU"
 )
 
+func (k KeadaanOrtu) IsValid() bool {
+	switch k {
+	case Lengkap, Yatim, Piatu, YatimPiatu:
+		return true
+	}
+	return false
+}
+
+var keadaanOrtuLabels = map[KeadaanOrtu]string{
+	Lengkap:    "Lengkap",
+	Yatim:      "Yatim",
+	Piatu:      "Piatu",
+	YatimPiatu: "Yatim piatu",
+}
+
+func (k KeadaanOrtu) Label() string {
+	return enumLabel(keadaanOrtuLabels, k)
+}
+
 type Religion string
 
 const (
@@ -180,18 +378,45 @@ const (
 	Konghucu  Religion = "KONGHUCU"
 )
 
+func (r Religion) IsValid() bool {
+	switch r {
+	case Islam, Christian, Catholic, Hindu, Buddha, Konghucu:
+		return true
+	}
+	return false
+}
+
+var religionLabels = map[Religion]string{
+	Islam:     "Islam",
+	Christian: "Kristen",
+	Catholic:  "Katolik",
+	Hindu:     "Hindu",
+	Buddha:    "Buddha",
+	Konghucu:  "Konghucu",
+}
+
+func (r Religion) Label() string {
+	return enumLabel(religionLabels, r)
+}
+
 type Student struct {
 	ID        int       `gorm:"primaryKey" json:"id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt moves the row to the trash instead of deleting it; trashed
+	// rows are purged for good after TRASH_RETENTION_DAYS.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	// Version is bumped on every edit and sent as the ETag, so updates
+	// based on a stale read are rejected.
+	Version int `gorm:"not null;default:1" json:"version"`
 
 	FullName              string          `json:"full_name"`
-	Nisn                  *string         `gorm:"uniqueIndex" json:"nisn"`
-	Nik                   *string         `gorm:"uniqueIndex" json:"nik"`
+	Nisn                  *string         `gorm:"serializer:encrypted" json:"nisn"`
+	Nik                   *string         `gorm:"serializer:encrypted" json:"nik"`
 	AsalSekolah           *string         `json:"asal_sekolah"`
 	Gender                Gender          `json:"gender"`
 	TempatLahir           *string         `json:"tempat_lahir"`
-	TanggalLahir          *string         `json:"tanggal_lahir"`
+	TanggalLahir          *Date           `json:"tanggal_lahir"`
 	Agama                 *Religion       `json:"agama"`
 	KeadaanOrtu           *KeadaanOrtu    `json:"keadaan_ortu"`
 	StatusKeluarga        *StatusKeluarga `json:"status_keluarga"`
@@ -208,7 +433,14 @@ type Student struct {
 	Kabupaten             *string         `json:"kabupaten"`
 	Provinsi              *string         `json:"provinsi"`
 	KodePos               *string         `json:"kode_pos"`
-	Phone                 *string         `json:"phone"`
+	ProvinsiKode          *string         `gorm:"index" json:"provinsi_kode"`
+	KabupatenKode         *string         `gorm:"index" json:"kabupaten_kode"`
+	KecamatanKode         *string         `gorm:"index" json:"kecamatan_kode"`
+	DesaKelurahanKode     *string         `gorm:"index" json:"desa_kelurahan_kode"`
+	Latitude              *float64        `json:"latitude"`
+	Longitude             *float64        `json:"longitude"`
+	DistanceKm            *float64        `gorm:"index" json:"distance_km"` // home to school, computed on save
+	Phone                 *string         `gorm:"serializer:encrypted" json:"phone"`
 	Email                 *string         `json:"email"`
 	Photo                 *string         `json:"photo"`
 	KartuKeluarga         *string         `json:"kartu_keluarga"`
@@ -216,18 +448,127 @@ type Student struct {
 	IjazahSKL             *string         `json:"ijazah_skl"`
 	IsAccepted            bool            `json:"is_accepted" gorm:"default:false"`
 
+	// Filled in at daftar ulang, when an accepted applicant becomes an enrolled pupil
+	Nis        *string    `gorm:"uniqueIndex" json:"nis"`
+	TahunMasuk *string    `json:"tahun_masuk"` // e.g. 2025/2026
+	EnrolledAt *time.Time `json:"enrolled_at"`
+
+	// Set when an admin registers an applicant above the batch age limit
+	AgeOverride       bool    `json:"age_override" gorm:"default:false"`
+	AgeOverrideReason *string `json:"age_override_reason"`
+
 	BloodType       *BloodType `json:"blood_type"`
 	BeratKg         *int       `json:"berat_kg"`
 	TinggiCm        *int       `json:"tinggi_cm"`
-	RiwayatPenyakit *string    `json:"riwayat_penyakit"`
+	RiwayatPenyakit *string    `gorm:"serializer:encrypted" json:"riwayat_penyakit"`
+
+	// Blind indexes of the encrypted NIK and NISN; their unique indexes keep
+	// both numbers unique per applicant.
+	NikHash  *string `gorm:"uniqueIndex" json:"-"`
+	NisnHash *string `gorm:"uniqueIndex" json:"-"`
+
+	// Set when the personal fields were scrubbed for a UU PDP erasure
+	// request; the row stays so dashboard counts do not change.
+	AnonymizedAt *time.Time `json:"anonymized_at"`
 
-	ParentId *int    `gorm:"unique" json:"parent_id"`
+	ParentId *int    `gorm:"index" json:"parent_id"`
 	Parent   *Parent `json:"parent"`
 
+	// Other children of the same parent, filled in on detail views
+	Siblings []Sibling `gorm:"-" json:"siblings,omitempty"`
+
 	BatchId *int   `json:"batch_id"`
 	Batch   *Batch `json:"batch"`
 }
 
+// SetBlindIndexes derives the blind indexes of the student and of a
+// parent saved along with it.
+func (s *Student) SetBlindIndexes() {
+	s.NikHash = encryption.BlindIndex(s.Nik)
+	s.NisnHash = encryption.BlindIndex(s.Nisn)
+	if s.Parent != nil {
+		s.Parent.SetBlindIndexes()
+	}
+}
+
+type Sibling struct {
+	ID           int     `json:"id"`
+	FullName     string  `json:"full_name"`
+	TanggalLahir *Date   `json:"tanggal_lahir"`
+	Nis          *string `json:"nis"`
+	IsAccepted   bool    `json:"is_accepted"`
+	BatchId      *int    `json:"batch_id"`
+	BatchName    *string `json:"batch_name"`
+}
+
+// DuplicateCandidate is a pair of applicants that are likely the same child.
+type DuplicateCandidate struct {
+	Students   []DuplicateStudent `json:"students"`
+	Score      float64            `json:"score"`
+	Components map[string]float64 `json:"components"`
+}
+
+type DuplicateStudent struct {
+	ID           int       `json:"id"`
+	FullName     string    `json:"full_name"`
+	TanggalLahir *Date     `json:"tanggal_lahir"`
+	TempatLahir  *string   `json:"tempat_lahir"`
+	AsalSekolah  *string   `json:"asal_sekolah"`
+	CreatedAt    time.Time `json:"created_at"`
+	Completeness int       `json:"completeness"` // number of filled-in fields
+}
+
+type StudentMergeRequest struct {
+	StudentIDs []int `json:"student_ids" binding:"required"`
+	KeepID     *int  `json:"keep_id"`
+}
+
+// FilterPreset is a named applicant list query saved by an admin, e.g.
+// "gender=FEMALE&documents_complete=false&sort=-created_at".
+type FilterPreset struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    int       `gorm:"not null;uniqueIndex:idx_filter_presets_user_name" json:"user_id"`
+	Name      string    `gorm:"not null;uniqueIndex:idx_filter_presets_user_name" json:"name"`
+	Query     string    `gorm:"not null" json:"query"`
+}
+
+// ======================
+// ENROLLMENT
+// ======================
+
+// NisSequence keeps the last NIS sequence number issued per academic start year.
+type NisSequence struct {
+	Year    int `gorm:"primaryKey;autoIncrement:false" json:"year"`
+	LastSeq int `gorm:"not null;default:0" json:"last_seq"`
+}
+
+type EnrollRequest struct {
+	StudentIDs []int  `json:"student_ids" binding:"required"`
+	TahunMasuk string `json:"tahun_masuk"`
+}
+
+type AgeOverrideReport struct {
+	Student       StudentResponse `json:"student"`
+	Age           *int            `json:"age"`
+	MaxAge        *int            `json:"max_age"`
+	ReferenceDate *time.Time      `json:"reference_date"`
+}
+
+type ZonasiRank struct {
+	Rank       int             `json:"rank"`
+	Student    StudentResponse `json:"student"`
+	DistanceKm float64         `json:"distance_km"`
+	Zona       *int            `json:"zona"`
+	Score      *float64        `json:"score"`
+}
+
+type EnrollResult struct {
+	Enrolled []StudentResponse `json:"enrolled"`
+	Failed   map[int]string    `json:"failed,omitempty"`
+}
+
 // ======================
 // POST (BERITA / ARTIKEL / INFORMASI)
 // ======================
@@ -240,19 +581,29 @@ const (
 	Informasi PostCategory = "INFORMASI"
 )
 
+func (p PostCategory) IsValid() bool {
+	switch p {
+	case Berita, Artikel, Informasi:
+		return true
+	}
+	return false
+}
+
 type Post struct {
-	ID          int           `gorm:"primaryKey" json:"id"`
-	Title       string        `json:"title"`
-	Slug        string        `gorm:"unique" json:"slug"`
-	Thumbnail   *string       `json:"thumbnail"`
-	Description *string       `json:"description"`
-	Content     string        `json:"content"`
-	Excerpt     *string       `json:"excerpt"`
-	Published   bool          `json:"published"`
-	PublishedAt *time.Time    `json:"published_at"`
-	CreatedAt   time.Time     `json:"created_at"`
-	UpdatedAt   time.Time     `json:"updated_at"`
-	Category    *PostCategory `json:"category"`
+	ID          int            `gorm:"primaryKey" json:"id"`
+	Title       string         `json:"title"`
+	Slug        string         `gorm:"unique" json:"slug"`
+	Thumbnail   *string        `json:"thumbnail"`
+	Description *string        `json:"description"`
+	Content     string         `json:"content"`
+	Excerpt     *string        `json:"excerpt"`
+	Published   bool           `json:"published"`
+	PublishedAt *time.Time     `json:"published_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Version     int            `gorm:"not null;default:1" json:"version"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	Category    *PostCategory  `json:"category"`
 }
 
 // ======================
@@ -280,6 +631,14 @@ const (
 	KOCuricullar    CurriculumCategory = "KO-CULLICULAR"
 )
 
+func (c CurriculumCategory) IsValid() bool {
+	switch c {
+	case Extracurricular, ProgramUnggulan, KOCuricullar:
+		return true
+	}
+	return false
+}
+
 type Curriculum struct {
 	ID          int                 `gorm:"primaryKey" json:"id"`
 	Name        string              `json:"name"`
@@ -294,18 +653,101 @@ type Curriculum struct {
 // BATCH
 // ======================
 type Batch struct {
-	ID        int        `gorm:"primaryKey" json:"id"`
-	Name      string     `json:"name"`
-	Jalur     string     `json:"jalur"` // PRESTASI / REGULER
-	IsActive  *bool      `json:"is_active" gorm:"default:false"`
-	StartDate *time.Time `json:"start_date"`
-	EndDate   *time.Time `json:"end_date"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID        int            `gorm:"primaryKey" json:"id"`
+	Name      string         `json:"name"`
+	Jalur     string         `json:"jalur"` // PRESTASI / REGULER
+	IsActive  *bool          `json:"is_active" gorm:"default:false"`
+	StartDate *time.Time     `json:"start_date"`
+	EndDate   *time.Time     `json:"end_date"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Version   int            `gorm:"not null;default:1" json:"version"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+
+	// Age eligibility: applicants may be at most MaxAge years old on
+	// AgeReferenceDate (defaults to 1 July of the start year).
+	MaxAge           *int       `json:"max_age"`
+	AgeReferenceDate *time.Time `json:"age_reference_date"`
+
+	// Zonasi rings in km, e.g. [3, 6, 10]: zone 1 is within 3 km, zone 2
+	// within 6 km and so on. Applicants beyond the last ring are zone 0.
+	ZonaRadiusKm pq.Float64Array `gorm:"type:float8[]" json:"zona_radius_km"`
 
 	Students []Student `json:"students"`
 }
 
+// AgeReference returns the date applicant ages are measured at for this batch.
+func (b *Batch) AgeReference() time.Time {
+	if b.AgeReferenceDate != nil {
+		return *b.AgeReferenceDate
+	}
+
+	year := time.Now().Year()
+	if b.StartDate != nil {
+		year = b.StartDate.Year()
+	}
+	return time.Date(year, time.July, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ======================
+// REGION (WILAYAH ADMINISTRASI)
+// ======================
+
+type RegionLevel string
+
+const (
+	LevelProvinsi  RegionLevel = "PROVINSI"
+	LevelKabupaten RegionLevel = "KABUPATEN"
+	LevelKecamatan RegionLevel = "KECAMATAN"
+	LevelDesa      RegionLevel = "DESA"
+)
+
+func (r RegionLevel) IsValid() bool {
+	switch r {
+	case LevelProvinsi, LevelKabupaten, LevelKecamatan, LevelDesa:
+		return true
+	}
+	return false
+}
+
+// Region is one entry of the Kemendagri region master, keyed by its dotted
+// official code, e.g. 52 / 52.03 / 52.03.07 / 52.03.07.2001.
+type Region struct {
+	Code       string      `gorm:"primaryKey;type:varchar(13)" json:"code"`
+	Name       string      `gorm:"not null" json:"name"`
+	Level      RegionLevel `gorm:"index" json:"level"`
+	ParentCode *string     `gorm:"index;type:varchar(13)" json:"parent_code"`
+	PostalCode *string     `json:"postal_code"`
+}
+
+// RegionLevelOf derives the level and parent code from a dotted region code.
+func RegionLevelOf(code string) (RegionLevel, string, bool) {
+	parts := strings.Split(code, ".")
+	for _, part := range parts {
+		if part == "" {
+			return "", "", false
+		}
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return "", "", false
+			}
+		}
+	}
+
+	parent := strings.Join(parts[:len(parts)-1], ".")
+	switch len(parts) {
+	case 1:
+		return LevelProvinsi, "", true
+	case 2:
+		return LevelKabupaten, parent, true
+	case 3:
+		return LevelKecamatan, parent, true
+	case 4:
+		return LevelDesa, parent, true
+	}
+	return "", "", false
+}
+
 // ======================
 // REQUIREMENT
 // ======================
@@ -326,3 +768,387 @@ type Faq struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// ======================
+// PPDB DRAFT (MULTI-STEP FORM)
+// ======================
+
+type DraftStep string
+
+const (
+	StepIdentity  DraftStep = "identity"
+	StepAddress   DraftStep = "address"
+	StepParents   DraftStep = "parents"
+	StepHealth    DraftStep = "health"
+	StepDocuments DraftStep = "documents"
+)
+
+// RequiredDraftSteps must be saved before a draft can be submitted.
+var RequiredDraftSteps = []DraftStep{StepIdentity, StepAddress, StepParents}
+
+// PPDBDraft is a partially filled PPDB form, resumable with its token.
+type PPDBDraft struct {
+	ID             int            `gorm:"primaryKey" json:"-"`
+	Token          string         `gorm:"uniqueIndex;type:varchar(64);not null" json:"token"`
+	Payload        string         `gorm:"type:text;not null;serializer:encrypted" json:"-"`
+	CompletedSteps pq.StringArray `gorm:"type:text[]" json:"completed_steps"`
+	StudentId      *int           `json:"student_id"`
+	SubmittedAt    *time.Time     `json:"submitted_at"`
+	ExpiresAt      time.Time      `json:"expires_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+
+	Data *Student `gorm:"-" json:"data"`
+}
+
+func (d *PPDBDraft) HasStep(step DraftStep) bool {
+	for _, s := range d.CompletedSteps {
+		if s == string(step) {
+			return true
+		}
+	}
+	return false
+}
+
+// DraftStepData is the body of one step of the form.
+type DraftStepData interface {
+	Step() DraftStep
+	ApplyTo(student *Student)
+}
+
+// NewDraftStepData returns an empty body for the named step.
+func NewDraftStepData(step string) (DraftStepData, bool) {
+	switch DraftStep(step) {
+	case StepIdentity:
+		return &DraftIdentity{}, true
+	case StepAddress:
+		return &DraftAddress{}, true
+	case StepParents:
+		return &DraftParents{}, true
+	case StepHealth:
+		return &DraftHealth{}, true
+	case StepDocuments:
+		return &DraftDocuments{}, true
+	}
+	return nil, false
+}
+
+type DraftIdentity struct {
+	FullName        string          `json:"full_name" binding:"required,notblank"`
+	Nisn            *string         `json:"nisn" binding:"omitempty,nisn"`
+	Nik             *string         `json:"nik" binding:"omitempty,nik"`
+	AsalSekolah     *string         `json:"asal_sekolah"`
+	Gender          Gender          `json:"gender" binding:"required,enum"`
+	TempatLahir     *string         `json:"tempat_lahir"`
+	TanggalLahir    *Date           `json:"tanggal_lahir" binding:"required"`
+	Agama           *Religion       `json:"agama" binding:"required,enum"`
+	KeadaanOrtu     *KeadaanOrtu    `json:"keadaan_ortu" binding:"omitempty,enum"`
+	StatusKeluarga  *StatusKeluarga `json:"status_keluarga" binding:"omitempty,enum"`
+	AnakKe          *int            `json:"anak_ke" binding:"omitempty,gte=1"`
+	DariBersaudara  *int            `json:"dari_bersaudara" binding:"omitempty,gte=1"`
+	Kewarganegaraan *string         `json:"kewarganegaraan"`
+	Phone           *string         `json:"phone"`
+	Email           *string         `json:"email" binding:"omitempty,email"`
+}
+
+func (DraftIdentity) Step() DraftStep { return StepIdentity }
+
+func (d DraftIdentity) ApplyTo(s *Student) {
+	s.FullName = d.FullName
+	s.Nisn = d.Nisn
+	s.Nik = d.Nik
+	s.AsalSekolah = d.AsalSekolah
+	s.Gender = d.Gender
+	s.TempatLahir = d.TempatLahir
+	s.TanggalLahir = d.TanggalLahir
+	s.Agama = d.Agama
+	s.KeadaanOrtu = d.KeadaanOrtu
+	s.StatusKeluarga = d.StatusKeluarga
+	s.AnakKe = d.AnakKe
+	s.DariBersaudara = d.DariBersaudara
+	s.Kewarganegaraan = d.Kewarganegaraan
+	s.Phone = d.Phone
+	s.Email = d.Email
+}
+
+type DraftAddress struct {
+	TinggalBersama        *TinggalBersama `json:"tinggal_bersama" binding:"omitempty,enum"`
+	TinggalBersamaLainnya *string         `json:"tinggal_bersama_lainnya"`
+	AlamatJalan           *string         `json:"alamat_jalan" binding:"required,notblank"`
+	Rt                    *string         `json:"rt"`
+	Rw                    *string         `json:"rw"`
+	DesaKelurahan         *string         `json:"desa_kelurahan"`
+	Kecamatan             *string         `json:"kecamatan"`
+	Kabupaten             *string         `json:"kabupaten"`
+	Provinsi              *string         `json:"provinsi"`
+	KodePos               *string         `json:"kode_pos"`
+	ProvinsiKode          *string         `json:"provinsi_kode"`
+	KabupatenKode         *string         `json:"kabupaten_kode"`
+	KecamatanKode         *string         `json:"kecamatan_kode"`
+	DesaKelurahanKode     *string         `json:"desa_kelurahan_kode"`
+	Latitude              *float64        `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude             *float64        `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+}
+
+func (DraftAddress) Step() DraftStep { return StepAddress }
+
+func (d DraftAddress) ApplyTo(s *Student) {
+	s.TinggalBersama = d.TinggalBersama
+	s.TinggalBersamaLainnya = d.TinggalBersamaLainnya
+	s.AlamatJalan = d.AlamatJalan
+	s.Rt = d.Rt
+	s.Rw = d.Rw
+	s.DesaKelurahan = d.DesaKelurahan
+	s.Kecamatan = d.Kecamatan
+	s.Kabupaten = d.Kabupaten
+	s.Provinsi = d.Provinsi
+	s.KodePos = d.KodePos
+	s.ProvinsiKode = d.ProvinsiKode
+	s.KabupatenKode = d.KabupatenKode
+	s.KecamatanKode = d.KecamatanKode
+	s.DesaKelurahanKode = d.DesaKelurahanKode
+	s.Latitude = d.Latitude
+	s.Longitude = d.Longitude
+}
+
+type DraftParents struct {
+	FatherName      *string `json:"father_name"`
+	FatherNik       *string `json:"father_nik" binding:"omitempty,nik"`
+	FatherEducation *string `json:"father_education"`
+	FatherJob       *string `json:"father_job"`
+	FatherIncome    *string `json:"father_income"`
+	MotherName      *string `json:"mother_name"`
+	MotherNik       *string `json:"mother_nik" binding:"omitempty,nik"`
+	MotherEducation *string `json:"mother_education"`
+	MotherJob       *string `json:"mother_job"`
+	MotherIncome    *string `json:"mother_income"`
+	ParentEmail     *string `json:"parent_email" binding:"omitempty,email"`
+	WaliName        *string `json:"wali_name"`
+	WaliNik         *string `json:"wali_nik" binding:"omitempty,nik"`
+	AlamatOrtuWali  *string `json:"alamat_ortu_wali"`
+	NoHpOrtuWali    *string `json:"no_hp_ortu_wali" binding:"required,notblank"`
+}
+
+func (DraftParents) Step() DraftStep { return StepParents }
+
+func (d DraftParents) ApplyTo(s *Student) {
+	s.Parent = &Parent{
+		FatherName:      d.FatherName,
+		FatherNik:       d.FatherNik,
+		FatherEducation: d.FatherEducation,
+		FatherJob:       d.FatherJob,
+		FatherIncome:    d.FatherIncome,
+		MotherName:      d.MotherName,
+		MotherNik:       d.MotherNik,
+		MotherEducation: d.MotherEducation,
+		MotherJob:       d.MotherJob,
+		MotherIncome:    d.MotherIncome,
+		ParentEmail:     d.ParentEmail,
+		WaliName:        d.WaliName,
+		WaliNik:         d.WaliNik,
+		AlamatOrtuWali:  d.AlamatOrtuWali,
+		NoHpOrtuWali:    d.NoHpOrtuWali,
+	}
+}
+
+type DraftHealth struct {
+	BloodType       *BloodType `json:"blood_type" binding:"omitempty,enum"`
+	BeratKg         *int       `json:"berat_kg" binding:"omitempty,gte=10,lte=200"`
+	TinggiCm        *int       `json:"tinggi_cm" binding:"omitempty,gte=50,lte=250"`
+	RiwayatPenyakit *string    `json:"riwayat_penyakit"`
+}
+
+func (DraftHealth) Step() DraftStep { return StepHealth }
+
+func (d DraftHealth) ApplyTo(s *Student) {
+	s.BloodType = d.BloodType
+	s.BeratKg = d.BeratKg
+	s.TinggiCm = d.TinggiCm
+	s.RiwayatPenyakit = d.RiwayatPenyakit
+}
+
+type DraftDocuments struct {
+	Photo         *string `json:"photo"`
+	KartuKeluarga *string `json:"kartu_keluarga" binding:"required,notblank"`
+	AktaKelahiran *string `json:"akta_kelahiran" binding:"required,notblank"`
+	IjazahSKL     *string `json:"ijazah_skl"`
+}
+
+func (DraftDocuments) Step() DraftStep { return StepDocuments }
+
+func (d DraftDocuments) ApplyTo(s *Student) {
+	s.Photo = d.Photo
+	s.KartuKeluarga = d.KartuKeluarga
+	s.AktaKelahiran = d.AktaKelahiran
+	s.IjazahSKL = d.IjazahSKL
+}
+
+// ======================
+// TRASH
+// ======================
+
+// TrashPurgeResult counts the records permanently deleted from the trash.
+type TrashPurgeResult struct {
+	Before   time.Time `json:"before"`
+	Students int64     `json:"students"`
+	Parents  int64     `json:"parents"`
+	Batches  int64     `json:"batches"`
+	Posts    int64     `json:"posts"`
+}
+
+// ======================
+// RETENTION
+// ======================
+
+// What became of an applicant once the batch ended.
+const (
+	RetentionRejected = "rejected" // never accepted
+	RetentionAccepted = "accepted" // accepted but never enrolled
+	RetentionEnrolled = "enrolled"
+)
+
+// What a retention rule deletes.
+const (
+	RetentionDocuments = "documents" // photo and document scans
+	RetentionHealth    = "health"    // blood type, weight, height and medical history
+	RetentionPersonal  = "personal"  // every personal field, as by anonymization
+)
+
+// RetentionRule deletes Data of applicants with Status Months after their
+// batch's EndDate.
+type RetentionRule struct {
+	Status string `json:"status"`
+	Data   string `json:"data"`
+	Months int    `json:"months"`
+}
+
+// RetentionPurgeLog records data deleted by a retention rule.
+type RetentionPurgeLog struct {
+	ID        int            `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time      `gorm:"index" json:"created_at"`
+	StudentID int            `gorm:"index" json:"student_id"`
+	BatchID   *int           `json:"batch_id"`
+	Status    string         `gorm:"type:varchar(20)" json:"status"`
+	Data      string         `gorm:"type:varchar(20)" json:"data"`
+	Fields    pq.StringArray `gorm:"type:text[]" json:"fields"`
+}
+
+// RetentionCandidate is an applicant a retention rule will purge.
+type RetentionCandidate struct {
+	StudentID    int        `json:"student_id"`
+	FullName     string     `json:"full_name"`
+	BatchID      *int       `json:"batch_id"`
+	BatchName    string     `json:"batch_name"`
+	BatchEndDate *time.Time `json:"batch_end_date"`
+	Status       string     `json:"status"`
+	Data         string     `json:"data"`
+	Fields       []string   `json:"fields"`
+	DueAt        time.Time  `json:"due_at"`
+}
+
+type RetentionPreview struct {
+	At         time.Time            `json:"at"`
+	Rules      []RetentionRule      `json:"rules"`
+	Candidates []RetentionCandidate `json:"candidates"`
+}
+
+type RetentionRunResult struct {
+	Purged int            `json:"purged"`
+	Failed map[int]string `json:"failed,omitempty"`
+}
+
+// ======================
+// SEARCH
+// ======================
+
+// SearchHit is one match of the admin search. Highlight is HTML: the escaped
+// text with the matched words wrapped in <mark>, and without marks when the
+// match was a typo or an exact NIK/NISN.
+type SearchHit struct {
+	ID        int     `json:"id"`
+	Title     string  `json:"title"`
+	Subtitle  *string `json:"subtitle"`
+	Highlight string  `json:"highlight"`
+	Rank      float64 `json:"rank"`
+}
+
+type SearchResult struct {
+	Query    string      `json:"query"`
+	Students []SearchHit `json:"students"`
+	Parents  []SearchHit `json:"parents"`
+	Posts    []SearchHit `json:"posts"`
+}
+
+// ======================
+// IMPORT
+// ======================
+
+const (
+	ImportPending   = "pending"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+const (
+	// ImportAllOrNothing stores no row unless every row is valid
+	ImportAllOrNothing = "all_or_nothing"
+	// ImportSkipInvalid stores the valid rows and reports the others
+	ImportSkipInvalid = "skip_invalid"
+)
+
+// ImportJob is an upload of applicants from a CSV or XLSX file, processed
+// in the background.
+type ImportJob struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    int       `gorm:"index" json:"user_id"`
+	FileName  string    `json:"file_name"`
+	Format    string    `gorm:"type:varchar(10)" json:"format"`
+	Mode      string    `gorm:"type:varchar(20)" json:"mode"`
+	DryRun    bool      `json:"dry_run"`
+	Status    string    `gorm:"type:varchar(20);index" json:"status"`
+
+	TotalRows    int `json:"total_rows"`
+	ValidRows    int `json:"valid_rows"`
+	ImportedRows int `json:"imported_rows"`
+
+	// Row errors by spreadsheet row number (the header is row 1)
+	Errors     []ImportRowError `gorm:"serializer:json" json:"errors"`
+	Message    *string          `json:"message"`
+	FinishedAt *time.Time       `json:"finished_at"`
+}
+
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ImportRow is one spreadsheet row, by import column.
+type ImportRow struct {
+	Number int
+	Values map[string]string
+}
+
+// ======================
+// DAPODIK
+// ======================
+
+// DapodikReport lists the accepted students Dapodik would not import as
+// they are.
+type DapodikReport struct {
+	Total    int                  `json:"total"`
+	Complete int                  `json:"complete"`
+	Students []DapodikReportEntry `json:"students"`
+}
+
+// DapodikReportEntry names the Dapodik columns of a student that are
+// mandatory but empty, or whose value matches no Dapodik code.
+type DapodikReportEntry struct {
+	StudentID int      `json:"student_id"`
+	FullName  string   `json:"full_name"`
+	Missing   []string `json:"missing"`
+	Unmapped  []string `json:"unmapped"`
+}
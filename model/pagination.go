@@ -0,0 +1,121 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+)
+
+// ======================
+// PAGINATION
+// ======================
+
+const (
+	DefaultPageLimit = 10
+	MaxPageLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PageRequest asks for one page of a listing, either by page number or by
+// the opaque cursor a previous page returned as next_cursor. Cursors keep
+// their place when rows are added or removed in between.
+type PageRequest struct {
+	Limit  int
+	Page   int
+	Cursor *Cursor
+}
+
+// Cursor points just past the last row of a page, by the values of the
+// listing's sort columns and the row ID.
+type Cursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+	ID     int           `json:"id"`
+}
+
+// NewPageRequest validates limit, page and cursor query values; blank ones
+// take defaults, and limit is capped at MaxPageLimit.
+func NewPageRequest(limit string, page string, cursor string) (PageRequest, map[string]string) {
+	req := PageRequest{Limit: DefaultPageLimit, Page: 1}
+	errs := map[string]string{}
+
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			errs["limit"] = "limit must be a positive number"
+		} else {
+			req.Limit = min(n, MaxPageLimit)
+		}
+	}
+
+	if page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			errs["page"] = "page must be a positive number"
+		} else {
+			req.Page = n
+		}
+	}
+
+	if cursor != "" {
+		c, err := DecodeCursor(cursor)
+		if err != nil {
+			errs["cursor"] = err.Error()
+		} else {
+			req.Cursor = c
+		}
+	}
+
+	if len(errs) > 0 {
+		return req, errs
+	}
+	return req, nil
+}
+
+// Offset is the number of rows before the page in page mode.
+func (p PageRequest) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// PageMeta goes in the meta of every paginated response. Page is left out
+// when the page was requested by cursor.
+type PageMeta struct {
+	Limit      int    `json:"limit"`
+	Page       int    `json:"page,omitempty"`
+	Total      int64  `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	HasNext    bool   `json:"has_next"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func NewPageMeta(req PageRequest, total int64) *PageMeta {
+	meta := &PageMeta{
+		Limit:      req.Limit,
+		Total:      total,
+		TotalPages: int(math.Ceil(float64(total) / float64(req.Limit))),
+	}
+	if req.Cursor == nil {
+		meta.Page = req.Page
+	}
+	return meta
+}
@@ -0,0 +1,194 @@
+package model
+
+// ======================
+// PATCH (JSON MERGE PATCH)
+// ======================
+
+// PATCH bodies are JSON merge patches (RFC 7386) applied to the request DTO
+// of the stored row: an absent field is left alone, null clears it and any
+// other value, false and "" included, is written as given. Only the fields
+// listed per resource may be patched; nested objects such as a student's
+// parent are patched through their own endpoint.
+
+type PatchFields map[string]bool
+
+func patchFields(names ...string) PatchFields {
+	fields := make(PatchFields, len(names))
+	for _, name := range names {
+		fields[name] = true
+	}
+	return fields
+}
+
+var StudentPatchFields = patchFields(
+	"full_name", "nisn", "nik", "asal_sekolah", "gender", "tempat_lahir", "tanggal_lahir",
+	"agama", "keadaan_ortu", "status_keluarga", "anak_ke", "dari_bersaudara",
+	"tinggal_bersama", "tinggal_bersama_lainnya", "kewarganegaraan", "alamat_jalan", "rt",
+	"rw", "desa_kelurahan", "kecamatan", "kabupaten", "provinsi", "kode_pos",
+	"provinsi_kode", "kabupaten_kode", "kecamatan_kode", "desa_kelurahan_kode", "latitude",
+	"longitude", "phone", "email", "photo", "kartu_keluarga", "akta_kelahiran",
+	"ijazah_skl", "blood_type", "berat_kg", "tinggi_cm", "riwayat_penyakit", "is_accepted",
+	"age_override", "age_override_reason", "batch_id",
+)
+
+var ParentPatchFields = patchFields(
+	"father_name", "father_nik", "father_education", "father_job", "father_income",
+	"mother_name", "mother_nik", "mother_education", "mother_job", "mother_income",
+	"parent_email", "wali_name", "wali_nik", "alamat_ortu_wali", "no_hp_ortu_wali",
+)
+
+var PostPatchFields = patchFields(
+	"title", "thumbnail", "description", "content", "excerpt", "published", "published_at",
+	"category",
+)
+
+var BatchPatchFields = patchFields(
+	"name", "jalur", "is_active", "start_date", "end_date", "max_age", "age_reference_date",
+	"zona_radius_km",
+)
+
+var FacilityPatchFields = patchFields(
+	"name", "image", "description",
+)
+
+var CurriculumPatchFields = patchFields(
+	"name", "image", "category", "description",
+)
+
+var FaqPatchFields = patchFields(
+	"question", "answer",
+)
+
+var RequirementPatchFields = patchFields(
+	"description",
+)
+
+// The New*Request functions fill a request DTO from a stored row, as the
+// starting point a merge patch is applied to.
+
+func NewStudentRequest(m *Student) StudentRequest {
+	return StudentRequest{
+		PPDBRequest: PPDBRequest{
+			FullName:              m.FullName,
+			Nisn:                  m.Nisn,
+			Nik:                   m.Nik,
+			AsalSekolah:           m.AsalSekolah,
+			Gender:                m.Gender,
+			TempatLahir:           m.TempatLahir,
+			TanggalLahir:          m.TanggalLahir,
+			Agama:                 m.Agama,
+			KeadaanOrtu:           m.KeadaanOrtu,
+			StatusKeluarga:        m.StatusKeluarga,
+			AnakKe:                m.AnakKe,
+			DariBersaudara:        m.DariBersaudara,
+			TinggalBersama:        m.TinggalBersama,
+			TinggalBersamaLainnya: m.TinggalBersamaLainnya,
+			Kewarganegaraan:       m.Kewarganegaraan,
+			AlamatJalan:           m.AlamatJalan,
+			Rt:                    m.Rt,
+			Rw:                    m.Rw,
+			DesaKelurahan:         m.DesaKelurahan,
+			Kecamatan:             m.Kecamatan,
+			Kabupaten:             m.Kabupaten,
+			Provinsi:              m.Provinsi,
+			KodePos:               m.KodePos,
+			ProvinsiKode:          m.ProvinsiKode,
+			KabupatenKode:         m.KabupatenKode,
+			KecamatanKode:         m.KecamatanKode,
+			DesaKelurahanKode:     m.DesaKelurahanKode,
+			Latitude:              m.Latitude,
+			Longitude:             m.Longitude,
+			Phone:                 m.Phone,
+			Email:                 m.Email,
+			Photo:                 m.Photo,
+			KartuKeluarga:         m.KartuKeluarga,
+			AktaKelahiran:         m.AktaKelahiran,
+			IjazahSKL:             m.IjazahSKL,
+			BloodType:             m.BloodType,
+			BeratKg:               m.BeratKg,
+			TinggiCm:              m.TinggiCm,
+			RiwayatPenyakit:       m.RiwayatPenyakit,
+		},
+		IsAccepted:        m.IsAccepted,
+		AgeOverride:       m.AgeOverride,
+		AgeOverrideReason: m.AgeOverrideReason,
+		ParentId:          m.ParentId,
+		BatchId:           m.BatchId,
+	}
+}
+
+func NewParentRequest(m *Parent) ParentRequest {
+	return ParentRequest{
+		FatherName:      m.FatherName,
+		FatherNik:       m.FatherNik,
+		FatherEducation: m.FatherEducation,
+		FatherJob:       m.FatherJob,
+		FatherIncome:    m.FatherIncome,
+		MotherName:      m.MotherName,
+		MotherNik:       m.MotherNik,
+		MotherEducation: m.MotherEducation,
+		MotherJob:       m.MotherJob,
+		MotherIncome:    m.MotherIncome,
+		ParentEmail:     m.ParentEmail,
+		WaliName:        m.WaliName,
+		WaliNik:         m.WaliNik,
+		AlamatOrtuWali:  m.AlamatOrtuWali,
+		NoHpOrtuWali:    m.NoHpOrtuWali,
+	}
+}
+
+func NewPostRequest(m *Post) PostRequest {
+	return PostRequest{
+		Title:       m.Title,
+		Thumbnail:   m.Thumbnail,
+		Description: m.Description,
+		Content:     m.Content,
+		Excerpt:     m.Excerpt,
+		Published:   m.Published,
+		PublishedAt: m.PublishedAt,
+		Category:    m.Category,
+	}
+}
+
+func NewBatchRequest(m *Batch) BatchRequest {
+	return BatchRequest{
+		Name:             m.Name,
+		Jalur:            m.Jalur,
+		IsActive:         m.IsActive,
+		StartDate:        m.StartDate,
+		EndDate:          m.EndDate,
+		MaxAge:           m.MaxAge,
+		AgeReferenceDate: m.AgeReferenceDate,
+		ZonaRadiusKm:     m.ZonaRadiusKm,
+	}
+}
+
+func NewFacilityRequest(m *Facility) FacilityRequest {
+	return FacilityRequest{
+		Name:        m.Name,
+		Image:       m.Image,
+		Description: m.Description,
+	}
+}
+
+func NewCurriculumRequest(m *Curriculum) CurriculumRequest {
+	return CurriculumRequest{
+		Name:        m.Name,
+		Image:       m.Image,
+		Category:    m.Category,
+		Description: m.Description,
+	}
+}
+
+func NewFaqRequest(m *Faq) FaqRequest {
+	return FaqRequest{
+		Question: m.Question,
+		Answer:   m.Answer,
+	}
+}
+
+func NewRequirementRequest(m *Requirement) RequirementRequest {
+	return RequirementRequest{
+		Description: m.Description,
+	}
+}
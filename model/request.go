@@ -0,0 +1,307 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// ======================
+// REQUEST BODIES
+// ======================
+
+// Binding tags are checked by gin on ShouldBindJSON; the custom tags (enum,
+// notblank, nik, nisn) are registered in the validation package.
+
+type UserLogin struct {
+	Email    string `json:"email" binding:"required,notblank"`
+	Password string `json:"password" binding:"required"`
+}
+
+// PPDBRequest is the public registration form. It only carries what an
+// applicant may fill in; acceptance, batch and age override are decided by
+// the school.
+type PPDBRequest struct {
+	FullName              string          `json:"full_name" binding:"required,notblank"`
+	Nisn                  *string         `json:"nisn" binding:"omitempty,nisn"`
+	Nik                   *string         `json:"nik" binding:"omitempty,nik"`
+	AsalSekolah           *string         `json:"asal_sekolah"`
+	Gender                Gender          `json:"gender" binding:"required,enum"`
+	TempatLahir           *string         `json:"tempat_lahir"`
+	TanggalLahir          *Date           `json:"tanggal_lahir"`
+	Agama                 *Religion       `json:"agama" binding:"required,enum"`
+	KeadaanOrtu           *KeadaanOrtu    `json:"keadaan_ortu" binding:"omitempty,enum"`
+	StatusKeluarga        *StatusKeluarga `json:"status_keluarga" binding:"omitempty,enum"`
+	AnakKe                *int            `json:"anak_ke" binding:"omitempty,gte=1"`
+	DariBersaudara        *int            `json:"dari_bersaudara" binding:"omitempty,gte=1"`
+	TinggalBersama        *TinggalBersama `json:"tinggal_bersama" binding:"omitempty,enum"`
+	TinggalBersamaLainnya *string         `json:"tinggal_bersama_lainnya"`
+	Kewarganegaraan       *string         `json:"kewarganegaraan"`
+	AlamatJalan           *string         `json:"alamat_jalan"`
+	Rt                    *string         `json:"rt"`
+	Rw                    *string         `json:"rw"`
+	DesaKelurahan         *string         `json:"desa_kelurahan"`
+	Kecamatan             *string         `json:"kecamatan"`
+	Kabupaten             *string         `json:"kabupaten"`
+	Provinsi              *string         `json:"provinsi"`
+	KodePos               *string         `json:"kode_pos"`
+	ProvinsiKode          *string         `json:"provinsi_kode"`
+	KabupatenKode         *string         `json:"kabupaten_kode"`
+	KecamatanKode         *string         `json:"kecamatan_kode"`
+	DesaKelurahanKode     *string         `json:"desa_kelurahan_kode"`
+	Latitude              *float64        `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude             *float64        `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	Phone                 *string         `json:"phone"`
+	Email                 *string         `json:"email" binding:"omitempty,email"`
+	Photo                 *string         `json:"photo"`
+	KartuKeluarga         *string         `json:"kartu_keluarga"`
+	AktaKelahiran         *string         `json:"akta_kelahiran"`
+	IjazahSKL             *string         `json:"ijazah_skl"`
+
+	BloodType       *BloodType `json:"blood_type" binding:"omitempty,enum"`
+	BeratKg         *int       `json:"berat_kg" binding:"omitempty,gte=10,lte=200"`
+	TinggiCm        *int       `json:"tinggi_cm" binding:"omitempty,gte=50,lte=250"`
+	RiwayatPenyakit *string    `json:"riwayat_penyakit"`
+
+	Parent *ParentRequest `json:"parent"`
+}
+
+func (r *PPDBRequest) ToModel() *Student {
+	student := &Student{
+		FullName:              strings.TrimSpace(r.FullName),
+		Nisn:                  r.Nisn,
+		Nik:                   r.Nik,
+		AsalSekolah:           r.AsalSekolah,
+		Gender:                r.Gender,
+		TempatLahir:           r.TempatLahir,
+		TanggalLahir:          r.TanggalLahir,
+		Agama:                 r.Agama,
+		KeadaanOrtu:           r.KeadaanOrtu,
+		StatusKeluarga:        r.StatusKeluarga,
+		AnakKe:                r.AnakKe,
+		DariBersaudara:        r.DariBersaudara,
+		TinggalBersama:        r.TinggalBersama,
+		TinggalBersamaLainnya: r.TinggalBersamaLainnya,
+		Kewarganegaraan:       r.Kewarganegaraan,
+		AlamatJalan:           r.AlamatJalan,
+		Rt:                    r.Rt,
+		Rw:                    r.Rw,
+		DesaKelurahan:         r.DesaKelurahan,
+		Kecamatan:             r.Kecamatan,
+		Kabupaten:             r.Kabupaten,
+		Provinsi:              r.Provinsi,
+		KodePos:               r.KodePos,
+		ProvinsiKode:          r.ProvinsiKode,
+		KabupatenKode:         r.KabupatenKode,
+		KecamatanKode:         r.KecamatanKode,
+		DesaKelurahanKode:     r.DesaKelurahanKode,
+		Latitude:              r.Latitude,
+		Longitude:             r.Longitude,
+		Phone:                 r.Phone,
+		Email:                 r.Email,
+		Photo:                 r.Photo,
+		KartuKeluarga:         r.KartuKeluarga,
+		AktaKelahiran:         r.AktaKelahiran,
+		IjazahSKL:             r.IjazahSKL,
+		BloodType:             r.BloodType,
+		BeratKg:               r.BeratKg,
+		TinggiCm:              r.TinggiCm,
+		RiwayatPenyakit:       r.RiwayatPenyakit,
+	}
+	if r.Parent != nil {
+		student.Parent = r.Parent.ToModel()
+	}
+	return student
+}
+
+// StudentRequest is an applicant entered or edited by an admin.
+type StudentRequest struct {
+	PPDBRequest
+	IsAccepted        bool    `json:"is_accepted"`
+	AgeOverride       bool    `json:"age_override"`
+	AgeOverrideReason *string `json:"age_override_reason"`
+	ParentId          *int    `json:"parent_id"`
+	BatchId           *int    `json:"batch_id"`
+}
+
+func (r *StudentRequest) ToModel() *Student {
+	student := r.PPDBRequest.ToModel()
+	student.IsAccepted = r.IsAccepted
+	student.AgeOverride = r.AgeOverride
+	student.AgeOverrideReason = r.AgeOverrideReason
+	student.ParentId = r.ParentId
+	student.BatchId = r.BatchId
+	return student
+}
+
+// UpdateStudentRequest is the body of a full student update. ParentVersion
+// is the version of the nested parent the client read; it is required when
+// the update edits the parent.
+type UpdateStudentRequest struct {
+	StudentRequest
+	ParentVersion *int `json:"parent_version"`
+}
+
+type ParentRequest struct {
+	FatherName      *string `json:"father_name"`
+	FatherNik       *string `json:"father_nik" binding:"omitempty,nik"`
+	FatherEducation *string `json:"father_education"`
+	FatherJob       *string `json:"father_job"`
+	FatherIncome    *string `json:"father_income"`
+	MotherName      *string `json:"mother_name"`
+	MotherNik       *string `json:"mother_nik" binding:"omitempty,nik"`
+	MotherEducation *string `json:"mother_education"`
+	MotherJob       *string `json:"mother_job"`
+	MotherIncome    *string `json:"mother_income"`
+	ParentEmail     *string `json:"parent_email" binding:"omitempty,email"`
+	WaliName        *string `json:"wali_name"`
+	WaliNik         *string `json:"wali_nik" binding:"omitempty,nik"`
+	AlamatOrtuWali  *string `json:"alamat_ortu_wali"`
+	NoHpOrtuWali    *string `json:"no_hp_ortu_wali"`
+}
+
+func (r *ParentRequest) ToModel() *Parent {
+	return &Parent{
+		FatherName:      r.FatherName,
+		FatherNik:       r.FatherNik,
+		FatherEducation: r.FatherEducation,
+		FatherJob:       r.FatherJob,
+		FatherIncome:    r.FatherIncome,
+		MotherName:      r.MotherName,
+		MotherNik:       r.MotherNik,
+		MotherEducation: r.MotherEducation,
+		MotherJob:       r.MotherJob,
+		MotherIncome:    r.MotherIncome,
+		ParentEmail:     r.ParentEmail,
+		WaliName:        r.WaliName,
+		WaliNik:         r.WaliNik,
+		AlamatOrtuWali:  r.AlamatOrtuWali,
+		NoHpOrtuWali:    r.NoHpOrtuWali,
+	}
+}
+
+// CreateParentRequest is a parent created on its own, which needs both names.
+type CreateParentRequest struct {
+	ParentRequest
+	FatherName *string `json:"father_name" binding:"required,notblank"`
+	MotherName *string `json:"mother_name" binding:"required,notblank"`
+}
+
+func (r *CreateParentRequest) ToModel() *Parent {
+	parent := r.ParentRequest.ToModel()
+	parent.FatherName = r.FatherName
+	parent.MotherName = r.MotherName
+	return parent
+}
+
+type PostRequest struct {
+	Title       string        `json:"title" binding:"required,notblank"`
+	Thumbnail   *string       `json:"thumbnail"`
+	Description *string       `json:"description"`
+	Content     string        `json:"content" binding:"required,notblank"`
+	Excerpt     *string       `json:"excerpt"`
+	Published   bool          `json:"published"`
+	PublishedAt *time.Time    `json:"published_at"`
+	Category    *PostCategory `json:"category" binding:"omitempty,enum"`
+}
+
+func (r *PostRequest) ToModel() *Post {
+	return &Post{
+		Title:       strings.TrimSpace(r.Title),
+		Thumbnail:   r.Thumbnail,
+		Description: r.Description,
+		Content:     r.Content,
+		Excerpt:     r.Excerpt,
+		Published:   r.Published,
+		PublishedAt: r.PublishedAt,
+		Category:    r.Category,
+	}
+}
+
+type BatchRequest struct {
+	Name             string     `json:"name" binding:"required,notblank"`
+	Jalur            string     `json:"jalur" binding:"required,notblank"`
+	IsActive         *bool      `json:"is_active"`
+	StartDate        *time.Time `json:"start_date"`
+	EndDate          *time.Time `json:"end_date" binding:"omitempty,gtfield=StartDate"`
+	MaxAge           *int       `json:"max_age" binding:"omitempty,gte=1"`
+	AgeReferenceDate *time.Time `json:"age_reference_date"`
+	ZonaRadiusKm     []float64  `json:"zona_radius_km" binding:"omitempty,dive,gt=0"`
+}
+
+func (r *BatchRequest) ToModel() *Batch {
+	return &Batch{
+		Name:             strings.TrimSpace(r.Name),
+		Jalur:            strings.TrimSpace(r.Jalur),
+		IsActive:         r.IsActive,
+		StartDate:        r.StartDate,
+		EndDate:          r.EndDate,
+		MaxAge:           r.MaxAge,
+		AgeReferenceDate: r.AgeReferenceDate,
+		ZonaRadiusKm:     r.ZonaRadiusKm,
+	}
+}
+
+type FacilityRequest struct {
+	Name        string  `json:"name" binding:"required,notblank"`
+	Image       *string `json:"image"`
+	Description *string `json:"description"`
+}
+
+func (r *FacilityRequest) ToModel() *Facility {
+	return &Facility{
+		Name:        strings.TrimSpace(r.Name),
+		Image:       r.Image,
+		Description: r.Description,
+	}
+}
+
+type CurriculumRequest struct {
+	Name        string              `json:"name" binding:"required,notblank"`
+	Image       *string             `json:"image"`
+	Category    *CurriculumCategory `json:"category" binding:"omitempty,enum"`
+	Description *string             `json:"description"`
+}
+
+func (r *CurriculumRequest) ToModel() *Curriculum {
+	return &Curriculum{
+		Name:        strings.TrimSpace(r.Name),
+		Image:       r.Image,
+		Category:    r.Category,
+		Description: r.Description,
+	}
+}
+
+type FaqRequest struct {
+	Question string `json:"question" binding:"required,notblank"`
+	Answer   string `json:"answer" binding:"required,notblank"`
+}
+
+func (r *FaqRequest) ToModel() *Faq {
+	return &Faq{
+		Question: strings.TrimSpace(r.Question),
+		Answer:   r.Answer,
+	}
+}
+
+type RequirementRequest struct {
+	Description string `json:"description" binding:"required,notblank"`
+}
+
+func (r *RequirementRequest) ToModel() *Requirement {
+	return &Requirement{
+		Description: strings.TrimSpace(r.Description),
+	}
+}
+
+type FilterPresetRequest struct {
+	Name  string `json:"name" binding:"required,notblank"`
+	Query string `json:"query"`
+}
+
+func (r *FilterPresetRequest) ToModel(userID int) *FilterPreset {
+	return &FilterPreset{
+		UserID: userID,
+		Name:   strings.TrimSpace(r.Name),
+		Query:  strings.TrimPrefix(strings.TrimSpace(r.Query), "?"),
+	}
+}
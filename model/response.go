@@ -0,0 +1,743 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ======================
+// RESPONSE BODIES
+// ======================
+
+// Handlers answer with these instead of the GORM models, so internal or
+// secret columns never reach the client by accident.
+
+type UserResponse struct {
+	UserID   int      `json:"user_id"`
+	Email    string   `json:"email"`
+	Fullname string   `json:"fullname"`
+	Role     UserRole `json:"role"`
+}
+
+func NewUserResponse(u *User) UserResponse {
+	return UserResponse{
+		UserID:   u.ID,
+		Email:    u.Email,
+		Fullname: u.Fullname,
+		Role:     u.Role,
+	}
+}
+
+// StudentSummary is a student listed under a parent or a batch.
+type StudentSummary struct {
+	ID         int     `json:"id"`
+	FullName   string  `json:"full_name"`
+	Nisn       *string `json:"nisn"`
+	Nis        *string `json:"nis"`
+	IsAccepted bool    `json:"is_accepted"`
+	BatchId    *int    `json:"batch_id"`
+}
+
+func NewStudentSummaries(students []Student) []StudentSummary {
+	summaries := make([]StudentSummary, 0, len(students))
+	for _, s := range students {
+		summaries = append(summaries, StudentSummary{
+			ID:         s.ID,
+			FullName:   s.FullName,
+			Nisn:       MaskNumber(s.Nisn),
+			Nis:        s.Nis,
+			IsAccepted: s.IsAccepted,
+			BatchId:    s.BatchId,
+		})
+	}
+	return summaries
+}
+
+// BatchSummary is the batch shown on a student.
+type BatchSummary struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Jalur    string `json:"jalur"`
+	IsActive *bool  `json:"is_active"`
+}
+
+type StudentResponse struct {
+	ID        int        `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Version   int        `json:"version"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	FullName              string          `json:"full_name"`
+	Nisn                  *string         `json:"nisn"`
+	Nik                   *string         `json:"nik"`
+	AsalSekolah           *string         `json:"asal_sekolah"`
+	Gender                Gender          `json:"gender"`
+	TempatLahir           *string         `json:"tempat_lahir"`
+	TanggalLahir          *Date           `json:"tanggal_lahir"`
+	Agama                 *Religion       `json:"agama"`
+	KeadaanOrtu           *KeadaanOrtu    `json:"keadaan_ortu"`
+	StatusKeluarga        *StatusKeluarga `json:"status_keluarga"`
+	AnakKe                *int            `json:"anak_ke"`
+	DariBersaudara        *int            `json:"dari_bersaudara"`
+	TinggalBersama        *TinggalBersama `json:"tinggal_bersama"`
+	TinggalBersamaLainnya *string         `json:"tinggal_bersama_lainnya"`
+	Kewarganegaraan       *string         `json:"kewarganegaraan"`
+	AlamatJalan           *string         `json:"alamat_jalan"`
+	Rt                    *string         `json:"rt"`
+	Rw                    *string         `json:"rw"`
+	DesaKelurahan         *string         `json:"desa_kelurahan"`
+	Kecamatan             *string         `json:"kecamatan"`
+	Kabupaten             *string         `json:"kabupaten"`
+	Provinsi              *string         `json:"provinsi"`
+	KodePos               *string         `json:"kode_pos"`
+	ProvinsiKode          *string         `json:"provinsi_kode"`
+	KabupatenKode         *string         `json:"kabupaten_kode"`
+	KecamatanKode         *string         `json:"kecamatan_kode"`
+	DesaKelurahanKode     *string         `json:"desa_kelurahan_kode"`
+	Latitude              *float64        `json:"latitude"`
+	Longitude             *float64        `json:"longitude"`
+	DistanceKm            *float64        `json:"distance_km"`
+	Phone                 *string         `json:"phone"`
+	Email                 *string         `json:"email"`
+	Photo                 *string         `json:"photo"`
+	KartuKeluarga         *string         `json:"kartu_keluarga"`
+	AktaKelahiran         *string         `json:"akta_kelahiran"`
+	IjazahSKL             *string         `json:"ijazah_skl"`
+	IsAccepted            bool            `json:"is_accepted"`
+
+	Nis        *string    `json:"nis"`
+	TahunMasuk *string    `json:"tahun_masuk"`
+	EnrolledAt *time.Time `json:"enrolled_at"`
+
+	AgeOverride       bool    `json:"age_override"`
+	AgeOverrideReason *string `json:"age_override_reason"`
+
+	BloodType       *BloodType `json:"blood_type"`
+	BeratKg         *int       `json:"berat_kg"`
+	TinggiCm        *int       `json:"tinggi_cm"`
+	RiwayatPenyakit *string    `json:"riwayat_penyakit"`
+
+	ParentId *int            `json:"parent_id"`
+	Parent   *ParentResponse `json:"parent"`
+	Siblings []Sibling       `json:"siblings,omitempty"`
+	BatchId  *int            `json:"batch_id"`
+	Batch    *BatchSummary   `json:"batch"`
+
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
+
+	// Masked is true when NIK, NISN and phone numbers are masked and health
+	// data, document scans and parent incomes are left out.
+	Masked bool `json:"masked"`
+}
+
+// NewStudentResponse is masked; see NewUnmaskedStudentResponse for the
+// full record.
+func NewStudentResponse(s *Student) StudentResponse {
+	res := NewUnmaskedStudentResponse(s)
+	res.mask()
+	return res
+}
+
+// NewUnmaskedStudentResponse is only for the detail view of users allowed
+// to see sensitive data, and every use must be logged.
+func NewUnmaskedStudentResponse(s *Student) StudentResponse {
+	res := StudentResponse{
+		ID:                    s.ID,
+		CreatedAt:             s.CreatedAt,
+		UpdatedAt:             s.UpdatedAt,
+		Version:               s.Version,
+		DeletedAt:             deletedAt(s.DeletedAt),
+		FullName:              s.FullName,
+		Nisn:                  s.Nisn,
+		Nik:                   s.Nik,
+		AsalSekolah:           s.AsalSekolah,
+		Gender:                s.Gender,
+		TempatLahir:           s.TempatLahir,
+		TanggalLahir:          s.TanggalLahir,
+		Agama:                 s.Agama,
+		KeadaanOrtu:           s.KeadaanOrtu,
+		StatusKeluarga:        s.StatusKeluarga,
+		AnakKe:                s.AnakKe,
+		DariBersaudara:        s.DariBersaudara,
+		TinggalBersama:        s.TinggalBersama,
+		TinggalBersamaLainnya: s.TinggalBersamaLainnya,
+		Kewarganegaraan:       s.Kewarganegaraan,
+		AlamatJalan:           s.AlamatJalan,
+		Rt:                    s.Rt,
+		Rw:                    s.Rw,
+		DesaKelurahan:         s.DesaKelurahan,
+		Kecamatan:             s.Kecamatan,
+		Kabupaten:             s.Kabupaten,
+		Provinsi:              s.Provinsi,
+		KodePos:               s.KodePos,
+		ProvinsiKode:          s.ProvinsiKode,
+		KabupatenKode:         s.KabupatenKode,
+		KecamatanKode:         s.KecamatanKode,
+		DesaKelurahanKode:     s.DesaKelurahanKode,
+		Latitude:              s.Latitude,
+		Longitude:             s.Longitude,
+		DistanceKm:            s.DistanceKm,
+		Phone:                 s.Phone,
+		Email:                 s.Email,
+		Photo:                 s.Photo,
+		KartuKeluarga:         s.KartuKeluarga,
+		AktaKelahiran:         s.AktaKelahiran,
+		IjazahSKL:             s.IjazahSKL,
+		IsAccepted:            s.IsAccepted,
+		Nis:                   s.Nis,
+		TahunMasuk:            s.TahunMasuk,
+		EnrolledAt:            s.EnrolledAt,
+		AgeOverride:           s.AgeOverride,
+		AgeOverrideReason:     s.AgeOverrideReason,
+		BloodType:             s.BloodType,
+		BeratKg:               s.BeratKg,
+		TinggiCm:              s.TinggiCm,
+		RiwayatPenyakit:       s.RiwayatPenyakit,
+		ParentId:              s.ParentId,
+		Siblings:              s.Siblings,
+		BatchId:               s.BatchId,
+		AnonymizedAt:          s.AnonymizedAt,
+	}
+	if s.Parent != nil {
+		parent := NewUnmaskedParentResponse(s.Parent)
+		res.Parent = &parent
+	}
+	if s.Batch != nil {
+		res.Batch = &BatchSummary{
+			ID:       s.Batch.ID,
+			Name:     s.Batch.Name,
+			Jalur:    s.Batch.Jalur,
+			IsActive: s.Batch.IsActive,
+		}
+	}
+	return res
+}
+
+func (r *StudentResponse) mask() {
+	r.Masked = true
+	r.Nik = MaskNumber(r.Nik)
+	r.Nisn = MaskNumber(r.Nisn)
+	r.Phone = MaskPhone(r.Phone)
+	r.KartuKeluarga = nil
+	r.AktaKelahiran = nil
+	r.IjazahSKL = nil
+	r.BloodType = nil
+	r.BeratKg = nil
+	r.TinggiCm = nil
+	r.RiwayatPenyakit = nil
+	if r.Parent != nil {
+		r.Parent.mask()
+	}
+}
+
+func NewStudentResponses(students []Student) []StudentResponse {
+	res := make([]StudentResponse, 0, len(students))
+	for i := range students {
+		res = append(res, NewStudentResponse(&students[i]))
+	}
+	return res
+}
+
+type ParentResponse struct {
+	ID        int        `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Version   int        `json:"version"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	FatherName      *string `json:"father_name"`
+	FatherNik       *string `json:"father_nik"`
+	FatherEducation *string `json:"father_education"`
+	FatherJob       *string `json:"father_job"`
+	FatherIncome    *string `json:"father_income"`
+
+	MotherName      *string `json:"mother_name"`
+	MotherNik       *string `json:"mother_nik"`
+	MotherEducation *string `json:"mother_education"`
+	MotherJob       *string `json:"mother_job"`
+	MotherIncome    *string `json:"mother_income"`
+
+	ParentEmail *string `json:"parent_email"`
+
+	WaliName       *string `json:"wali_name"`
+	WaliNik        *string `json:"wali_nik"`
+	AlamatOrtuWali *string `json:"alamat_ortu_wali"`
+	NoHpOrtuWali   *string `json:"no_hp_ortu_wali"`
+
+	Students []StudentSummary `json:"students,omitempty"`
+
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
+
+	// Masked is true when NIKs and the phone number are masked and incomes
+	// are left out.
+	Masked bool `json:"masked"`
+}
+
+func NewParentResponse(p *Parent) ParentResponse {
+	res := NewUnmaskedParentResponse(p)
+	res.mask()
+	return res
+}
+
+func NewUnmaskedParentResponse(p *Parent) ParentResponse {
+	res := ParentResponse{
+		ID:              p.ID,
+		CreatedAt:       p.CreatedAt,
+		UpdatedAt:       p.UpdatedAt,
+		Version:         p.Version,
+		DeletedAt:       deletedAt(p.DeletedAt),
+		FatherName:      p.FatherName,
+		FatherNik:       p.FatherNik,
+		FatherEducation: p.FatherEducation,
+		FatherJob:       p.FatherJob,
+		FatherIncome:    p.FatherIncome,
+		MotherName:      p.MotherName,
+		MotherNik:       p.MotherNik,
+		MotherEducation: p.MotherEducation,
+		MotherJob:       p.MotherJob,
+		MotherIncome:    p.MotherIncome,
+		ParentEmail:     p.ParentEmail,
+		WaliName:        p.WaliName,
+		WaliNik:         p.WaliNik,
+		AlamatOrtuWali:  p.AlamatOrtuWali,
+		NoHpOrtuWali:    p.NoHpOrtuWali,
+		AnonymizedAt:    p.AnonymizedAt,
+	}
+	if len(p.Students) > 0 {
+		res.Students = NewStudentSummaries(p.Students)
+	}
+	return res
+}
+
+func (r *ParentResponse) mask() {
+	r.Masked = true
+	r.FatherNik = MaskNumber(r.FatherNik)
+	r.MotherNik = MaskNumber(r.MotherNik)
+	r.WaliNik = MaskNumber(r.WaliNik)
+	r.NoHpOrtuWali = MaskPhone(r.NoHpOrtuWali)
+	r.FatherIncome = nil
+	r.MotherIncome = nil
+}
+
+func NewParentResponses(parents []Parent) []ParentResponse {
+	res := make([]ParentResponse, 0, len(parents))
+	for i := range parents {
+		res = append(res, NewParentResponse(&parents[i]))
+	}
+	return res
+}
+
+type AccessLogResponse struct {
+	ID         int       `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UserID     int       `json:"user_id"`
+	Resource   string    `json:"resource"`
+	Action     string    `json:"action"`
+	ResourceID int       `json:"resource_id"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+}
+
+func NewAccessLogResponses(entries []SensitiveDataAccessLog) []AccessLogResponse {
+	res := make([]AccessLogResponse, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, AccessLogResponse{
+			ID:         e.ID,
+			CreatedAt:  e.CreatedAt,
+			UserID:     e.UserID,
+			Resource:   e.Resource,
+			Action:     e.Action,
+			ResourceID: e.ResourceID,
+			IPAddress:  e.IPAddress,
+			UserAgent:  e.UserAgent,
+		})
+	}
+	return res
+}
+
+type RetentionPurgeLogResponse struct {
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	StudentID int       `json:"student_id"`
+	BatchID   *int      `json:"batch_id"`
+	Status    string    `json:"status"`
+	Data      string    `json:"data"`
+	Fields    []string  `json:"fields"`
+}
+
+func NewRetentionPurgeLogResponses(entries []RetentionPurgeLog) []RetentionPurgeLogResponse {
+	res := make([]RetentionPurgeLogResponse, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, RetentionPurgeLogResponse{
+			ID:        e.ID,
+			CreatedAt: e.CreatedAt,
+			StudentID: e.StudentID,
+			BatchID:   e.BatchID,
+			Status:    e.Status,
+			Data:      e.Data,
+			Fields:    e.Fields,
+		})
+	}
+	return res
+}
+
+// SubjectAccessExport is everything held about one applicant, answering a
+// UU PDP access request. Nothing in it is masked.
+type SubjectAccessExport struct {
+	GeneratedAt   time.Time           `json:"generated_at"`
+	Student       StudentResponse     `json:"student"`
+	Parent        *ParentResponse     `json:"parent"`
+	Documents     []ExportDocument    `json:"documents"`
+	StatusHistory []StatusEvent       `json:"status_history"`
+	AccessLog     []AccessLogResponse `json:"access_log"`
+}
+
+// ExportDocument is an uploaded document and where it is stored.
+type ExportDocument struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+}
+
+// StatusEvent is a step of the application. At is nil for states the
+// database keeps no time for, such as acceptance.
+type StatusEvent struct {
+	Status string     `json:"status"`
+	At     *time.Time `json:"at"`
+}
+
+type BatchResponse struct {
+	ID               int              `json:"id"`
+	Name             string           `json:"name"`
+	Jalur            string           `json:"jalur"`
+	IsActive         *bool            `json:"is_active"`
+	StartDate        *time.Time       `json:"start_date"`
+	EndDate          *time.Time       `json:"end_date"`
+	MaxAge           *int             `json:"max_age"`
+	AgeReferenceDate *time.Time       `json:"age_reference_date"`
+	ZonaRadiusKm     []float64        `json:"zona_radius_km"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	Version          int              `json:"version"`
+	DeletedAt        *time.Time       `json:"deleted_at,omitempty"`
+	Students         []StudentSummary `json:"students"`
+}
+
+func NewBatchResponse(b *Batch) BatchResponse {
+	return BatchResponse{
+		ID:               b.ID,
+		Name:             b.Name,
+		Jalur:            b.Jalur,
+		IsActive:         b.IsActive,
+		StartDate:        b.StartDate,
+		EndDate:          b.EndDate,
+		MaxAge:           b.MaxAge,
+		AgeReferenceDate: b.AgeReferenceDate,
+		ZonaRadiusKm:     b.ZonaRadiusKm,
+		CreatedAt:        b.CreatedAt,
+		UpdatedAt:        b.UpdatedAt,
+		Version:          b.Version,
+		DeletedAt:        deletedAt(b.DeletedAt),
+		Students:         NewStudentSummaries(b.Students),
+	}
+}
+
+func NewBatchResponses(batches []Batch) []BatchResponse {
+	res := make([]BatchResponse, 0, len(batches))
+	for i := range batches {
+		res = append(res, NewBatchResponse(&batches[i]))
+	}
+	return res
+}
+
+type PostResponse struct {
+	ID          int           `json:"id"`
+	Title       string        `json:"title"`
+	Slug        string        `json:"slug"`
+	Thumbnail   *string       `json:"thumbnail"`
+	Description *string       `json:"description"`
+	Content     string        `json:"content"`
+	Excerpt     *string       `json:"excerpt"`
+	Published   bool          `json:"published"`
+	PublishedAt *time.Time    `json:"published_at"`
+	Category    *PostCategory `json:"category"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	Version     int           `json:"version"`
+	DeletedAt   *time.Time    `json:"deleted_at,omitempty"`
+}
+
+func NewPostResponse(p *Post) PostResponse {
+	return PostResponse{
+		ID:          p.ID,
+		Title:       p.Title,
+		Slug:        p.Slug,
+		Thumbnail:   p.Thumbnail,
+		Description: p.Description,
+		Content:     p.Content,
+		Excerpt:     p.Excerpt,
+		Published:   p.Published,
+		PublishedAt: p.PublishedAt,
+		Category:    p.Category,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		Version:     p.Version,
+		DeletedAt:   deletedAt(p.DeletedAt),
+	}
+}
+
+func NewPostResponses(posts []Post) []PostResponse {
+	res := make([]PostResponse, 0, len(posts))
+	for i := range posts {
+		res = append(res, NewPostResponse(&posts[i]))
+	}
+	return res
+}
+
+type FacilityResponse struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Image       *string   `json:"image"`
+	Description *string   `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewFacilityResponse(f *Facility) FacilityResponse {
+	return FacilityResponse{
+		ID:          f.ID,
+		Name:        f.Name,
+		Image:       f.Image,
+		Description: f.Description,
+		CreatedAt:   f.CreatedAt,
+		UpdatedAt:   f.UpdatedAt,
+	}
+}
+
+func NewFacilityResponses(facilities []Facility) []FacilityResponse {
+	res := make([]FacilityResponse, 0, len(facilities))
+	for i := range facilities {
+		res = append(res, NewFacilityResponse(&facilities[i]))
+	}
+	return res
+}
+
+type CurriculumResponse struct {
+	ID          int                 `json:"id"`
+	Name        string              `json:"name"`
+	Image       *string             `json:"image"`
+	Category    *CurriculumCategory `json:"category"`
+	Description *string             `json:"description"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+func NewCurriculumResponse(c *Curriculum) CurriculumResponse {
+	return CurriculumResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		Image:       c.Image,
+		Category:    c.Category,
+		Description: c.Description,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+func NewCurriculumResponses(curricula []Curriculum) []CurriculumResponse {
+	res := make([]CurriculumResponse, 0, len(curricula))
+	for i := range curricula {
+		res = append(res, NewCurriculumResponse(&curricula[i]))
+	}
+	return res
+}
+
+type FaqResponse struct {
+	ID        int       `json:"id"`
+	Question  string    `json:"question"`
+	Answer    string    `json:"answer"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewFaqResponse(f *Faq) FaqResponse {
+	return FaqResponse{
+		ID:        f.ID,
+		Question:  f.Question,
+		Answer:    f.Answer,
+		CreatedAt: f.CreatedAt,
+		UpdatedAt: f.UpdatedAt,
+	}
+}
+
+func NewFaqResponses(faqs []Faq) []FaqResponse {
+	res := make([]FaqResponse, 0, len(faqs))
+	for i := range faqs {
+		res = append(res, NewFaqResponse(&faqs[i]))
+	}
+	return res
+}
+
+type FilterPresetResponse struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewFilterPresetResponse(p *FilterPreset) FilterPresetResponse {
+	return FilterPresetResponse{
+		ID:        p.ID,
+		Name:      p.Name,
+		Query:     p.Query,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+func NewFilterPresetResponses(presets []FilterPreset) []FilterPresetResponse {
+	res := make([]FilterPresetResponse, 0, len(presets))
+	for i := range presets {
+		res = append(res, NewFilterPresetResponse(&presets[i]))
+	}
+	return res
+}
+
+type RequirementResponse struct {
+	ID          int       `json:"id"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewRequirementResponse(r *Requirement) RequirementResponse {
+	return RequirementResponse{
+		ID:          r.ID,
+		Description: r.Description,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+func NewRequirementResponses(requirements []Requirement) []RequirementResponse {
+	res := make([]RequirementResponse, 0, len(requirements))
+	for i := range requirements {
+		res = append(res, NewRequirementResponse(&requirements[i]))
+	}
+	return res
+}
+
+// DraftResponse is a PPDB draft as shown to the registrant holding its
+// token. The form data is masked like any other student response.
+type DraftResponse struct {
+	Token          string          `json:"token"`
+	CompletedSteps []string        `json:"completed_steps"`
+	StudentId      *int            `json:"student_id"`
+	SubmittedAt    *time.Time      `json:"submitted_at"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Data           StudentResponse `json:"data"`
+}
+
+func NewDraftResponse(d *PPDBDraft) DraftResponse {
+	res := DraftResponse{
+		Token:          d.Token,
+		CompletedSteps: d.CompletedSteps,
+		StudentId:      d.StudentId,
+		SubmittedAt:    d.SubmittedAt,
+		ExpiresAt:      d.ExpiresAt,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+	if res.CompletedSteps == nil {
+		res.CompletedSteps = []string{}
+	}
+	if d.Data != nil {
+		res.Data = NewStudentResponse(d.Data)
+	}
+	return res
+}
+
+// deletedAt is set only for rows listed from the trash.
+func deletedAt(d gorm.DeletedAt) *time.Time {
+	if !d.Valid {
+		return nil
+	}
+	return &d.Time
+}
+
+// DeleteImpact reports what deleting a record does. The delete endpoints
+// return it after the fact, the delete-preview endpoints without deleting.
+type DeleteImpact struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+
+	// BlockingStudents keep the record from being deleted.
+	BlockingStudents []StudentSummary `json:"blocking_students"`
+
+	TrashedStudents  []StudentSummary `json:"trashed_students"`
+	TrashedParentIDs []int            `json:"trashed_parent_ids"`
+	// KeptParentIDs stay because siblings still belong to them.
+	KeptParentIDs []int `json:"kept_parent_ids"`
+
+	ReassignTo         *int             `json:"reassign_to,omitempty"`
+	ReassignedStudents []StudentSummary `json:"reassigned_students"`
+}
+
+func NewDeleteImpact() *DeleteImpact {
+	return &DeleteImpact{
+		Allowed:            true,
+		BlockingStudents:   []StudentSummary{},
+		TrashedStudents:    []StudentSummary{},
+		TrashedParentIDs:   []int{},
+		KeptParentIDs:      []int{},
+		ReassignedStudents: []StudentSummary{},
+	}
+}
+
+// MaskParentDuplicateGroups masks the shared phone numbers and NIKs like the
+// parent list does.
+func MaskParentDuplicateGroups(groups []ParentDuplicateGroup) []ParentDuplicateGroup {
+	masked := make([]ParentDuplicateGroup, 0, len(groups))
+	for _, group := range groups {
+		switch group.Field {
+		case "no_hp_ortu_wali":
+			group.Value = *MaskPhone(&group.Value)
+		case "father_nik", "mother_nik", "wali_nik":
+			group.Value = *MaskNumber(&group.Value)
+		}
+		masked = append(masked, group)
+	}
+	return masked
+}
+
+// MaskNumber keeps the last four digits of a NIK or NISN, e.g.
+// ************0001.
+func MaskNumber(value *string) *string {
+	return mask(value, 0, 4)
+}
+
+// MaskPhone keeps the first four and last three digits, e.g. 0812*****678.
+func MaskPhone(value *string) *string {
+	return mask(value, 4, 3)
+}
+
+func mask(value *string, keepStart int, keepEnd int) *string {
+	if value == nil {
+		return nil
+	}
+
+	runes := []rune(*value)
+	if len(runes) <= keepStart+keepEnd {
+		keepStart, keepEnd = 0, 0
+	}
+	for i := keepStart; i < len(runes)-keepEnd; i++ {
+		runes[i] = '*'
+	}
+
+	masked := string(runes)
+	return &masked
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"project_sdu/model"
+
+	"gorm.io/gorm"
+)
+
+type AccessLogRepository interface {
+	Create(entry *model.SensitiveDataAccessLog) error
+	GetAll(page model.PageRequest, resource string, resourceID int) ([]model.SensitiveDataAccessLog, *model.PageMeta, error)
+	GetByResource(resource string, resourceID int) ([]model.SensitiveDataAccessLog, error)
+}
+
+type accessLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessLogRepository(db *gorm.DB) AccessLogRepository {
+	return &accessLogRepository{db}
+}
+
+func (r *accessLogRepository) Create(entry *model.SensitiveDataAccessLog) error {
+	return r.db.Create(entry).Error
+}
+
+// GetAll lists the newest entries first, optionally only those for one
+// resource (and one record of it).
+func (r *accessLogRepository) GetAll(page model.PageRequest, resource string, resourceID int) ([]model.SensitiveDataAccessLog, *model.PageMeta, error) {
+	var entries []model.SensitiveDataAccessLog
+	db := r.db
+
+	if resource != "" {
+		db = db.Where("resource = ?", resource)
+	}
+	if resourceID != 0 {
+		db = db.Where("resource_id = ?", resourceID)
+	}
+
+	meta, err := paginate(db, &entries, page, pageOrder{Column: "created_at", Desc: true})
+	return entries, meta, err
+}
+
+// GetByResource lists every entry for one record, oldest first.
+func (r *accessLogRepository) GetByResource(resource string, resourceID int) ([]model.SensitiveDataAccessLog, error) {
+	var entries []model.SensitiveDataAccessLog
+	err := r.db.
+		Where("resource = ? AND resource_id = ?", resource, resourceID).
+		Order("created_at ASC").
+		Find(&entries).
+		Error
+	return entries, err
+}
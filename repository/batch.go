@@ -2,17 +2,23 @@ package repository
 
 import (
 	"project_sdu/model"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type BatchRepository interface {
 	Create(batch *model.Batch) error
-	GetAll(limit, page int, q string) ([]model.Batch, error)
+	GetAll(page model.PageRequest, q string) ([]model.Batch, *model.PageMeta, error)
 	GetActiveBatch() (*model.Batch, error)
 	GetByID(id int) (*model.Batch, error)
 	Update(id int, batch *model.Batch) error
+	Patch(id int, version int, fields map[string]interface{}) error
 	Delete(id int) error
+	GetTrash(page model.PageRequest) ([]model.Batch, *model.PageMeta, error)
+	Restore(id int) error
+	Purge(id int) error
+	PurgeDeletedBefore(before time.Time) (int64, error)
 	CountAll() (int, error)
 }
 
@@ -32,11 +38,9 @@ func (r *batchRepository) Create(batch *model.Batch) error {
 	return nil
 }
 
-func (r *batchRepository) GetAll(limit, page int, q string) ([]model.Batch, error) {
+func (r *batchRepository) GetAll(page model.PageRequest, q string) ([]model.Batch, *model.PageMeta, error) {
 	var batches []model.Batch
 
-	offset := (page - 1) * limit
-
 	db := r.db
 
 	// Filter search name
@@ -44,13 +48,8 @@ func (r *batchRepository) GetAll(limit, page int, q string) ([]model.Batch, erro
 		db = db.Where("name ILIKE ?", "%"+q+"%")
 	}
 
-	err := db.
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&batches).Error
-
-	return batches, err
+	meta, err := paginate(db, &batches, page, pageOrder{Column: "created_at", Desc: true})
+	return batches, meta, err
 }
 
 func (r *batchRepository) GetByID(id int) (*model.Batch, error) {
@@ -80,17 +79,43 @@ func (r *batchRepository) GetActiveBatch() (*model.Batch, error) {
 	return &batch, nil
 }
 
+// Update expects batch.Version to be the version the caller read.
 func (r *batchRepository) Update(id int, batch *model.Batch) error {
-	return r.db.Model(&model.Batch{}).
-		Where("id = ?", id).
-		Updates(batch).
-		Error
+	version := batch.Version
+	batch.Version = version + 1
+	return updateVersioned(r.db, &model.Batch{}, "id = ?", id, version, batch)
 }
 
+func (r *batchRepository) Patch(id int, version int, fields map[string]interface{}) error {
+	fields["Version"] = version + 1
+	return updateVersioned(r.db, &model.Batch{}, "id = ?", id, version, fields)
+}
+
+// Delete moves the batch to the trash.
 func (r *batchRepository) Delete(id int) error {
 	return r.db.Delete(&model.Batch{}, id).Error
 }
 
+func (r *batchRepository) GetTrash(page model.PageRequest) ([]model.Batch, *model.PageMeta, error) {
+	var batches []model.Batch
+	meta, err := listTrash(r.db, &batches, page)
+	return batches, meta, err
+}
+
+func (r *batchRepository) Restore(id int) error {
+	return restoreFromTrash(r.db, &model.Batch{}, "id = ?", id)
+}
+
+func (r *batchRepository) Purge(id int) error {
+	return purgeFromTrash(r.db, &model.Batch{}, "id = ?", id)
+}
+
+// PurgeDeletedBefore keeps batches that trashed students still point to.
+func (r *batchRepository) PurgeDeletedBefore(before time.Time) (int64, error) {
+	db := r.db.Where("NOT EXISTS (SELECT 1 FROM students WHERE students.batch_id = batches.id)")
+	return purgeDeletedBefore(db, &model.Batch{}, before)
+}
+
 func (r *batchRepository) CountAll() (int, error) {
 	var count int64
 
@@ -9,10 +9,11 @@ import (
 type CurriculumRepository interface {
 	Create(curriculum *model.Curriculum) error
 	Update(id int, curriculum *model.Curriculum) error
+	Patch(id int, fields map[string]interface{}) error
 	Delete(id int) error
 	GetByID(id int) (*model.Curriculum, error)
-	GetAll(limit, offset int) ([]model.Curriculum, error)
-	GetByCategory(category string, limit, page int) ([]model.Curriculum, error)
+	GetAll(page model.PageRequest) ([]model.Curriculum, *model.PageMeta, error)
+	GetByCategory(category string, page model.PageRequest) ([]model.Curriculum, *model.PageMeta, error)
 }
 
 type curriculumRepository struct {
@@ -34,6 +35,13 @@ func (r *curriculumRepository) Update(id int, curriculum *model.Curriculum) erro
 		Error
 }
 
+func (r *curriculumRepository) Patch(id int, fields map[string]interface{}) error {
+	return r.db.Model(&model.Curriculum{}).
+		Where("id = ?", id).
+		Updates(fields).
+		Error
+}
+
 func (r *curriculumRepository) Delete(id int) error {
 	return r.db.Delete(&model.Curriculum{}, id).Error
 }
@@ -47,30 +55,18 @@ func (r *curriculumRepository) GetByID(id int) (*model.Curriculum, error) {
 	return &curriculum, nil
 }
 
-func (r *curriculumRepository) GetAll(limit, page int) ([]model.Curriculum, error) {
+func (r *curriculumRepository) GetAll(page model.PageRequest) ([]model.Curriculum, *model.PageMeta, error) {
 	var curriculum []model.Curriculum
 
-	offset := (page - 1) * limit
-
-	err := r.db.
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&curriculum).Error
-	return curriculum, err
+	meta, err := paginate(r.db, &curriculum, page, pageOrder{Column: "created_at", Desc: true})
+	return curriculum, meta, err
 }
 
-func (r *curriculumRepository) GetByCategory(category string, limit, page int) ([]model.Curriculum, error) {
+func (r *curriculumRepository) GetByCategory(category string, page model.PageRequest) ([]model.Curriculum, *model.PageMeta, error) {
 	var curriculum []model.Curriculum
 
-	offset := (page - 1) * limit
-
-	err := r.db.
-		Where("category ILIKE ?", category).
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&curriculum).Error
+	db := r.db.Where("category ILIKE ?", category)
 
-	return curriculum, err
+	meta, err := paginate(db, &curriculum, page, pageOrder{Column: "created_at", Desc: true})
+	return curriculum, meta, err
 }
\ No newline at end of file
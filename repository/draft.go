@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"project_sdu/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DraftRepository interface {
+	Create(draft *model.PPDBDraft) error
+	GetByToken(token string) (*model.PPDBDraft, error)
+	Update(draft *model.PPDBDraft) error
+	DeleteExpired(before time.Time) (int64, error)
+	DeleteByStudentID(studentID int) error
+	GetStudentIDs(studentIDs []int) ([]int, error)
+	Reencrypt() (int, error)
+	EncryptPlaintext() (int, error)
+}
+
+type draftRepository struct {
+	db *gorm.DB
+}
+
+func NewDraftRepository(db *gorm.DB) DraftRepository {
+	return &draftRepository{db}
+}
+
+func (r *draftRepository) Create(draft *model.PPDBDraft) error {
+	return r.db.Create(draft).Error
+}
+
+func (r *draftRepository) GetByToken(token string) (*model.PPDBDraft, error) {
+	var draft model.PPDBDraft
+	err := r.db.Where("token = ?", token).First(&draft).Error
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+func (r *draftRepository) Update(draft *model.PPDBDraft) error {
+	return r.db.Model(draft).Select("payload", "completed_steps", "student_id", "submitted_at", "expires_at", "updated_at").Updates(draft).Error
+}
+
+// DeleteExpired removes expired drafts, submitted or not.
+func (r *draftRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&model.PPDBDraft{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteByStudentID removes the draft a student was submitted from.
+func (r *draftRepository) DeleteByStudentID(studentID int) error {
+	return r.db.Where("student_id = ?", studentID).Delete(&model.PPDBDraft{}).Error
+}
+
+// GetStudentIDs returns which of the given students still have a draft.
+func (r *draftRepository) GetStudentIDs(studentIDs []int) ([]int, error) {
+	var ids []int
+	err := r.db.Model(&model.PPDBDraft{}).
+		Where("student_id IN ?", studentIDs).
+		Distinct().
+		Pluck("student_id", &ids).
+		Error
+	return ids, err
+}
+
+// Reencrypt writes the payload of every draft again with the current key.
+func (r *draftRepository) Reencrypt() (int, error) {
+	return r.reencrypt(r.db)
+}
+
+// EncryptPlaintext encrypts the payloads written before drafts were
+// encrypted.
+func (r *draftRepository) EncryptPlaintext() (int, error) {
+	return r.reencrypt(r.db.Where("payload NOT LIKE ?", "enc:%"))
+}
+
+func (r *draftRepository) reencrypt(query *gorm.DB) (int, error) {
+	count := 0
+	var drafts []model.PPDBDraft
+	err := query.FindInBatches(&drafts, reencryptBatchSize, func(tx *gorm.DB, batch int) error {
+		for i := range drafts {
+			if err := r.db.Model(&drafts[i]).Select("payload").UpdateColumns(&drafts[i]).Error; err != nil {
+				return err
+			}
+		}
+		count += len(drafts)
+		return nil
+	}).Error
+	return count, err
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"project_sdu/encryption"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// reencryptBatchSize is how many rows rotate-keys loads at a time.
+const reencryptBatchSize = 200
+
+func parseModel(db *gorm.DB, m interface{}) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(m); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+func isEncrypted(field *schema.Field) bool {
+	return field.TagSettings["SERIALIZER"] == "encrypted"
+}
+
+// sealFields encrypts the encrypted fields of a map update and sets their
+// blind indexes. gorm does not run serializers for map updates.
+func sealFields(db *gorm.DB, m interface{}, fields map[string]interface{}) error {
+	s, err := parseModel(db, m)
+	if err != nil {
+		return err
+	}
+
+	sealed := map[string]interface{}{}
+	for name, value := range fields {
+		field := s.LookUpField(name)
+		if field == nil || !isEncrypted(field) {
+			continue
+		}
+
+		plaintext, _ := value.(*string)
+		ciphertext, err := encryption.EncryptPtr(plaintext)
+		if err != nil {
+			return err
+		}
+		sealed[name] = ciphertext
+		if hash := s.LookUpField(name + "Hash"); hash != nil {
+			sealed[hash.Name] = encryption.BlindIndex(plaintext)
+		}
+	}
+
+	for name, value := range sealed {
+		fields[name] = value
+	}
+	return nil
+}
+
+// encryptedColumns lists the encrypted fields of the model together with
+// their blind indexes.
+func encryptedColumns(db *gorm.DB, m interface{}) ([]string, error) {
+	s, err := parseModel(db, m)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []string
+	for _, field := range s.Fields {
+		if !isEncrypted(field) {
+			continue
+		}
+		columns = append(columns, field.DBName)
+		if hash := s.LookUpField(field.Name + "Hash"); hash != nil {
+			columns = append(columns, hash.DBName)
+		}
+	}
+	return columns, nil
+}
+
+// missingBlindIndexes is a condition matching rows that hold an encrypted
+// value without its blind index.
+func missingBlindIndexes(db *gorm.DB, m interface{}) (string, error) {
+	s, err := parseModel(db, m)
+	if err != nil {
+		return "", err
+	}
+
+	var conditions []string
+	for _, field := range s.Fields {
+		hash := s.LookUpField(field.Name + "Hash")
+		if !isEncrypted(field) || hash == nil {
+			continue
+		}
+		conditions = append(conditions,
+			"("+field.DBName+" IS NOT NULL AND "+field.DBName+" <> '' AND "+hash.DBName+" IS NULL)")
+	}
+	return strings.Join(conditions, " OR "), nil
+}
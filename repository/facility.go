@@ -9,9 +9,10 @@ import (
 type FacilityRepository interface {
 	Create(facility *model.Facility) error
 	Update(id int, facility *model.Facility) error
+	Patch(id int, fields map[string]interface{}) error
 	Delete(id int) error
 	GetByID(id int) (*model.Facility, error)
-	GetAll(limit, offset int) ([]model.Facility, error)
+	GetAll(page model.PageRequest) ([]model.Facility, *model.PageMeta, error)
 }
 
 type facilityRepository struct {
@@ -33,6 +34,13 @@ func (r *facilityRepository) Update(id int, facility *model.Facility) error {
 		Error
 }
 
+func (r *facilityRepository) Patch(id int, fields map[string]interface{}) error {
+	return r.db.Model(&model.Facility{}).
+		Where("id = ?", id).
+		Updates(fields).
+		Error
+}
+
 func (r *facilityRepository) Delete(id int) error {
 	return r.db.Delete(&model.Facility{}, id).Error
 }
@@ -46,15 +54,9 @@ func (r *facilityRepository) GetByID(id int) (*model.Facility, error) {
 	return &facility, nil
 }
 
-func (r *facilityRepository) GetAll(limit, page int) ([]model.Facility, error) {
+func (r *facilityRepository) GetAll(page model.PageRequest) ([]model.Facility, *model.PageMeta, error) {
 	var facilities []model.Facility
 
-	offset := (page - 1) * limit
-
-	err := r.db.
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&facilities).Error
-	return facilities, err
+	meta, err := paginate(r.db, &facilities, page, pageOrder{Column: "created_at", Desc: true})
+	return facilities, meta, err
 }
@@ -10,6 +10,7 @@ type FaqRepository interface {
 	Create(faq *model.Faq) error
 	GetAll() ([]model.Faq, error)
 	Update(id int, faq *model.Faq) error
+	Patch(id int, fields map[string]interface{}) error
 	Delete(id int) error
 	GetByID(id int) (*model.Faq, error)
 }
@@ -36,6 +37,13 @@ func (r *faqRepository) Update(id int, faq *model.Faq) error {
 	return r.db.Model(&model.Faq{}).Where("id = ?", id).Updates(faq).Error
 }
 
+func (r *faqRepository) Patch(id int, fields map[string]interface{}) error {
+	return r.db.Model(&model.Faq{}).
+		Where("id = ?", id).
+		Updates(fields).
+		Error
+}
+
 func (r *faqRepository) Delete(id int) error {
 	return r.db.Delete(&model.Faq{}, id).Error
 }
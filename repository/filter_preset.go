@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"project_sdu/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FilterPresetRepository interface {
+	Save(preset *model.FilterPreset) error
+	GetByUser(userID int) ([]model.FilterPreset, error)
+	GetByID(id int, userID int) (*model.FilterPreset, error)
+	Delete(id int, userID int) error
+}
+
+type filterPresetRepository struct {
+	db *gorm.DB
+}
+
+func NewFilterPresetRepository(db *gorm.DB) FilterPresetRepository {
+	return &filterPresetRepository{db}
+}
+
+// Save creates the preset, or replaces the query of the user's preset with
+// the same name.
+func (r *filterPresetRepository) Save(preset *model.FilterPreset) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"query", "updated_at"}),
+	}).Create(preset).Error
+}
+
+func (r *filterPresetRepository) GetByUser(userID int) ([]model.FilterPreset, error) {
+	var presets []model.FilterPreset
+	err := r.db.
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&presets).Error
+	return presets, err
+}
+
+// GetByID only finds presets of the given user.
+func (r *filterPresetRepository) GetByID(id int, userID int) (*model.FilterPreset, error) {
+	var preset model.FilterPreset
+	err := r.db.
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&preset).Error
+	if err != nil {
+		return nil, err
+	}
+	return &preset, nil
+}
+
+func (r *filterPresetRepository) Delete(id int, userID int) error {
+	result := r.db.
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&model.FilterPreset{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"project_sdu/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ImportJobRepository interface {
+	Create(job *model.ImportJob) error
+	Save(job *model.ImportJob) error
+	GetByID(id int) (*model.ImportJob, error)
+	GetAll(page model.PageRequest) ([]model.ImportJob, *model.PageMeta, error)
+	FailInterrupted(message string) (int64, error)
+}
+
+type importJobRepository struct {
+	db *gorm.DB
+}
+
+func NewImportJobRepository(db *gorm.DB) ImportJobRepository {
+	return &importJobRepository{db}
+}
+
+func (r *importJobRepository) Create(job *model.ImportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *importJobRepository) Save(job *model.ImportJob) error {
+	return r.db.Save(job).Error
+}
+
+func (r *importJobRepository) GetByID(id int) (*model.ImportJob, error) {
+	var job model.ImportJob
+	if err := r.db.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetAll leaves out the row errors, which are only loaded for a single job.
+func (r *importJobRepository) GetAll(page model.PageRequest) ([]model.ImportJob, *model.PageMeta, error) {
+	var jobs []model.ImportJob
+	meta, err := paginate(r.db, &jobs, page, pageOrder{Column: "created_at", Desc: true}, omitImportErrors)
+	return jobs, meta, err
+}
+
+func omitImportErrors(db *gorm.DB) *gorm.DB {
+	return db.Omit("errors")
+}
+
+// FailInterrupted marks jobs still pending or running as failed. Jobs run in
+// the server process, so after a restart nobody finishes them.
+func (r *importJobRepository) FailInterrupted(message string) (int64, error) {
+	result := r.db.Model(&model.ImportJob{}).
+		Where("status IN ?", []string{model.ImportPending, model.ImportRunning}).
+		Updates(map[string]interface{}{
+			"status":      model.ImportFailed,
+			"message":     message,
+			"finished_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"project_sdu/model"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// pageOrder is one sort column of a paginated listing. Rows with the same
+// values follow in ID order, so every row has a distinct cursor.
+type pageOrder struct {
+	Column string
+	Desc   bool
+}
+
+// sortKey names a sort in a cursor, e.g. "-created_at,full_name", so a
+// cursor is only accepted by the sort it came from.
+func sortKey(orders []pageOrder) string {
+	keys := make([]string, len(orders))
+	for i, order := range orders {
+		keys[i] = order.Column
+		if order.Desc {
+			keys[i] = "-" + order.Column
+		}
+	}
+	return strings.Join(keys, ",")
+}
+
+// paginate counts the rows matched by db and loads one page of them into
+// dest, a pointer to a slice. scopes, such as preloads, only apply to the
+// page query.
+func paginate(db *gorm.DB, dest interface{}, req model.PageRequest, order pageOrder, scopes ...func(*gorm.DB) *gorm.DB) (*model.PageMeta, error) {
+	return paginateSorted(db, dest, req, []pageOrder{order}, scopes...)
+}
+
+// paginateSorted is paginate for a listing sorted by several columns. The
+// row ID breaks ties in the direction of the last column.
+func paginateSorted(db *gorm.DB, dest interface{}, req model.PageRequest, orders []pageOrder, scopes ...func(*gorm.DB) *gorm.DB) (*model.PageMeta, error) {
+	var total int64
+	if err := db.Session(&gorm.Session{}).Model(dest).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	next, err := loadPage(db, dest, req, orders, scopes...)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := model.NewPageMeta(req, total)
+	if next != nil {
+		meta.HasNext = true
+		meta.NextCursor = next.Encode()
+	}
+	return meta, nil
+}
+
+// eachPage loads all rows matched by db into dest one page of size rows at
+// a time, following the cursors like a client would, and calls fn after
+// every page. Only one page is held in memory.
+func eachPage(db *gorm.DB, dest interface{}, size int, orders []pageOrder, fn func() error, scopes ...func(*gorm.DB) *gorm.DB) error {
+	req := model.PageRequest{Limit: size, Page: 1}
+	for {
+		next, err := loadPage(db, dest, req, orders, scopes...)
+		if err != nil {
+			return err
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+
+		// Decoded like a cursor sent by a client, so the values have the
+		// types loadPage expects
+		if req.Cursor, err = model.DecodeCursor(next.Encode()); err != nil {
+			return err
+		}
+	}
+}
+
+// loadPage loads one page into dest and returns the cursor of the next page,
+// or nil on the last page.
+func loadPage(db *gorm.DB, dest interface{}, req model.PageRequest, orders []pageOrder, scopes ...func(*gorm.DB) *gorm.DB) (*model.Cursor, error) {
+	s, err := parseModel(db, dest)
+	if err != nil {
+		return nil, err
+	}
+	idField := s.PrioritizedPrimaryField
+	orders = append(orders[:len(orders):len(orders)], pageOrder{Column: idField.DBName, Desc: orders[len(orders)-1].Desc})
+
+	fields := make([]*schema.Field, len(orders))
+	for i, order := range orders {
+		fields[i] = s.LookUpField(order.Column)
+	}
+
+	// One row more than asked tells whether there is a next page
+	query := db.Session(&gorm.Session{}).Scopes(scopes...).Limit(req.Limit + 1)
+	for _, order := range orders {
+		direction := " ASC"
+		if order.Desc {
+			direction = " DESC"
+		}
+		query = query.Order(s.Table + "." + order.Column + direction)
+	}
+
+	key := sortKey(orders[:len(orders)-1])
+	if req.Cursor != nil {
+		if req.Cursor.Sort != key || len(req.Cursor.Values) != len(orders)-1 {
+			return nil, model.ErrInvalidCursor
+		}
+		values := make([]interface{}, len(orders))
+		for i, value := range req.Cursor.Values {
+			if values[i], err = cursorValue(fields[i], value); err != nil {
+				return nil, err
+			}
+		}
+		values[len(orders)-1] = req.Cursor.ID
+		sql, args := keysetCondition(s.Table, orders, fields, values)
+		query = query.Where(sql, args...)
+	} else {
+		query = query.Offset(req.Offset())
+	}
+
+	if err := query.Find(dest).Error; err != nil {
+		return nil, err
+	}
+
+	rows := reflect.ValueOf(dest).Elem()
+	if rows.Len() <= req.Limit {
+		return nil, nil
+	}
+	rows.Set(rows.Slice(0, req.Limit))
+	last := rows.Index(req.Limit - 1)
+
+	ctx := context.Background()
+	cursor := &model.Cursor{Sort: key}
+	for _, field := range fields[:len(fields)-1] {
+		value, zero := field.ValueOf(ctx, last)
+		if zero && field.FieldType.Kind() == reflect.Ptr {
+			value = nil
+		}
+		cursor.Values = append(cursor.Values, value)
+	}
+	lastID, _ := idField.ValueOf(ctx, last)
+	cursor.ID = lastID.(int)
+	return cursor, nil
+}
+
+// keysetCondition matches the rows after the given sort values. Postgres
+// sorts NULL after every value ascending and before every value descending,
+// so a NULL column is only followed by other NULLs ascending and by every
+// value descending. Only pointer fields are taken to be nullable.
+func keysetCondition(table string, orders []pageOrder, fields []*schema.Field, values []interface{}) (string, []interface{}) {
+	var alternatives []string
+	var args []interface{}
+
+	for i, order := range orders {
+		column := table + "." + order.Column
+
+		var after string
+		var afterArgs []interface{}
+		switch {
+		case values[i] == nil && order.Desc:
+			after = column + " IS NOT NULL"
+		case values[i] == nil:
+			// Nothing sorts after NULL ascending
+		case order.Desc:
+			after, afterArgs = column+" < ?", []interface{}{values[i]}
+		case fields[i].FieldType.Kind() != reflect.Ptr:
+			after, afterArgs = column+" > ?", []interface{}{values[i]}
+		default:
+			after, afterArgs = "("+column+" > ? OR "+column+" IS NULL)", []interface{}{values[i]}
+		}
+
+		if after != "" {
+			parts := []string{}
+			var partArgs []interface{}
+			for j := 0; j < i; j++ {
+				previous := table + "." + orders[j].Column
+				if values[j] == nil {
+					parts = append(parts, previous+" IS NULL")
+				} else {
+					parts = append(parts, previous+" = ?")
+					partArgs = append(partArgs, values[j])
+				}
+			}
+			parts = append(parts, after)
+			alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+			args = append(append(args, partArgs...), afterArgs...)
+		}
+	}
+
+	if len(alternatives) == 0 {
+		return "FALSE", nil
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+// cursorValue turns a sort value read back from JSON into the column's type.
+func cursorValue(field *schema.Field, value interface{}) (interface{}, error) {
+	if value == nil {
+		if field.FieldType.Kind() != reflect.Ptr {
+			return nil, model.ErrInvalidCursor
+		}
+		return nil, nil
+	}
+
+	switch field.FieldType {
+	case reflect.TypeOf(time.Time{}), reflect.TypeOf(&time.Time{}), reflect.TypeOf(gorm.DeletedAt{}):
+		s, ok := value.(string)
+		if !ok {
+			return nil, model.ErrInvalidCursor
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, model.ErrInvalidCursor
+		}
+		return t, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		if field.IndirectFieldType.Kind() == reflect.Float32 || field.IndirectFieldType.Kind() == reflect.Float64 {
+			return v, nil
+		}
+		return int64(v), nil
+	}
+	return nil, model.ErrInvalidCursor
+}
@@ -1,17 +1,49 @@
 package repository
 
 import (
+	"errors"
+	"project_sdu/encryption"
 	"project_sdu/model"
+	"time"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
 type ParentRepository interface {
 	Create(parent *model.Parent) error
-	GetAll(limit int, offset int) ([]model.Parent, error)
+	GetAll(page model.PageRequest) ([]model.Parent, *model.PageMeta, error)
 	GetByID(id int) (*model.Parent, error)
 	Update(id int, parent *model.Parent) error
+	Patch(id int, version int, fields map[string]interface{}) error
 	Delete(id int) error
+	CountStudents(id int) (int, error)
+	GetTrash(page model.PageRequest) ([]model.Parent, *model.PageMeta, error)
+	Restore(id int) error
+	Purge(id int) error
+	PurgeDeletedBefore(before time.Time) (int64, error)
+	GetOrphans() ([]model.Parent, error)
+	FindMatch(parent *model.Parent) (*model.Parent, error)
+	FindDuplicateGroups() ([]model.ParentDuplicateGroup, error)
+	GetByIDs(ids []int) ([]model.Parent, error)
+	DeleteMany(ids []int) error
+	Reencrypt() (int, error)
+	FillBlindIndexes() (int, error)
+	Anonymize(id int, fields map[string]interface{}) error
+}
+
+// parentMatchColumns identify the same family across registrations. The
+// encrypted phone and NIKs are matched through their blind indexes.
+var parentMatchColumns = []struct {
+	Field  string
+	Column string
+	Value  func(p *model.Parent) *string
+}{
+	{"no_hp_ortu_wali", "no_hp_ortu_wali_hash", func(p *model.Parent) *string { return p.NoHpOrtuWali }},
+	{"parent_email", "parent_email", func(p *model.Parent) *string { return p.ParentEmail }},
+	{"father_nik", "father_nik_hash", func(p *model.Parent) *string { return p.FatherNik }},
+	{"mother_nik", "mother_nik_hash", func(p *model.Parent) *string { return p.MotherNik }},
+	{"wali_nik", "wali_nik_hash", func(p *model.Parent) *string { return p.WaliNik }},
 }
 
 type parentRepository struct {
@@ -23,45 +55,228 @@ func NewParentRepo(db *gorm.DB) ParentRepository {
 }
 
 func (r *parentRepository) Create(parent *model.Parent) error {
+	parent.SetBlindIndexes()
 	return r.db.Create(parent).Error
 }
 
-func (r *parentRepository) GetAll(limit int, offset int) ([]model.Parent, error) {
+func (r *parentRepository) GetAll(page model.PageRequest) ([]model.Parent, *model.PageMeta, error) {
 	var parents []model.Parent
 
-	err := r.db.
-		Limit(limit).
-		Offset(offset).
-		Find(&parents).
-		Error
-
+	meta, err := paginate(r.db, &parents, page, pageOrder{Column: "created_at"})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return parents, nil
+	return parents, meta, nil
 }
 
 func (r *parentRepository) GetByID(id int) (*model.Parent, error) {
 	var parent model.Parent
-	err := r.db.First(&parent, id).Error
+	err := r.db.Preload("Students").First(&parent, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &parent, nil
 }
 
+// Update expects parent.Version to be the version the caller read.
 func (r *parentRepository) Update(id int, parent *model.Parent) error {
-	if err := r.db.Model(&model.Parent{}).
-		Where("id = ?", id).
-		Updates(parent).
-		Error; err != nil {
+	parent.SetBlindIndexes()
+	version := parent.Version
+	parent.Version = version + 1
+	return updateVersioned(r.db, &model.Parent{}, "id = ?", id, version, parent)
+}
+
+func (r *parentRepository) Patch(id int, version int, fields map[string]interface{}) error {
+	if err := sealFields(r.db, &model.Parent{}, fields); err != nil {
 		return err
 	}
-
-	return nil
+	fields["Version"] = version + 1
+	return updateVersioned(r.db, &model.Parent{}, "id = ?", id, version, fields)
 }
 
+// Delete moves the parent to the trash.
 func (r *parentRepository) Delete(id int) error {
 	return r.db.Delete(&model.Parent{}, id).Error
 }
+
+// CountStudents counts the students, outside the trash, of the parent.
+func (r *parentRepository) CountStudents(id int) (int, error) {
+	var count int64
+	err := r.db.Model(&model.Student{}).
+		Where("parent_id = ?", id).
+		Count(&count).Error
+	return int(count), err
+}
+
+func (r *parentRepository) GetTrash(page model.PageRequest) ([]model.Parent, *model.PageMeta, error) {
+	var parents []model.Parent
+	meta, err := listTrash(r.db, &parents, page)
+	return parents, meta, err
+}
+
+func (r *parentRepository) Restore(id int) error {
+	return restoreFromTrash(r.db, &model.Parent{}, "id = ?", id)
+}
+
+func (r *parentRepository) Purge(id int) error {
+	return purgeFromTrash(r.db, &model.Parent{}, "id = ?", id)
+}
+
+// PurgeDeletedBefore keeps parents that trashed students still point to;
+// they go once those students are purged.
+func (r *parentRepository) PurgeDeletedBefore(before time.Time) (int64, error) {
+	db := r.db.Where("NOT EXISTS (SELECT 1 FROM students WHERE students.parent_id = parents.id)")
+	return purgeDeletedBefore(db, &model.Parent{}, before)
+}
+
+// GetOrphans returns parents that no student points to.
+func (r *parentRepository) GetOrphans() ([]model.Parent, error) {
+	var parents []model.Parent
+
+	err := r.db.
+		Where("NOT EXISTS (SELECT 1 FROM students WHERE students.parent_id = parents.id)").
+		Order("created_at ASC").
+		Find(&parents).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parents, nil
+}
+
+// FindMatch returns the oldest parent sharing a phone number, email or NIK
+// with the given one, or nil when there is none.
+func (r *parentRepository) FindMatch(parent *model.Parent) (*model.Parent, error) {
+	conditions := r.db
+	matched := 0
+	for _, match := range parentMatchColumns {
+		value := match.Value(parent)
+		if match.Column != match.Field {
+			value = encryption.BlindIndex(value)
+		}
+		if value == nil || *value == "" {
+			continue
+		}
+		if matched == 0 {
+			conditions = conditions.Where(match.Column+" = ?", *value)
+		} else {
+			conditions = conditions.Or(match.Column+" = ?", *value)
+		}
+		matched++
+	}
+
+	if matched == 0 {
+		return nil, nil
+	}
+
+	var match model.Parent
+	if err := r.db.Where(conditions).Order("id ASC").First(&match).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &match, nil
+}
+
+func (r *parentRepository) FindDuplicateGroups() ([]model.ParentDuplicateGroup, error) {
+	var groups []model.ParentDuplicateGroup
+
+	for _, match := range parentMatchColumns {
+		column := match.Column
+		var rows []struct {
+			Value string
+			IDs   pq.Int64Array `gorm:"column:ids"`
+		}
+
+		err := r.db.Raw(`
+			SELECT ` + column + ` AS value, array_agg(id ORDER BY id) AS ids
+			FROM parents
+			WHERE ` + column + ` IS NOT NULL AND ` + column + ` <> '' AND deleted_at IS NULL
+			GROUP BY ` + column + `
+			HAVING count(*) > 1`).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			ids := make([]int, len(row.IDs))
+			for i, id := range row.IDs {
+				ids[i] = int(id)
+			}
+
+			// A blind index means nothing to the admin; show the value itself
+			value := row.Value
+			if match.Column != match.Field {
+				var first model.Parent
+				if err := r.db.First(&first, ids[0]).Error; err != nil {
+					return nil, err
+				}
+				if v := match.Value(&first); v != nil {
+					value = *v
+				}
+			}
+			groups = append(groups, model.ParentDuplicateGroup{Field: match.Field, Value: value, ParentIDs: ids})
+		}
+	}
+
+	return groups, nil
+}
+
+func (r *parentRepository) GetByIDs(ids []int) ([]model.Parent, error) {
+	var parents []model.Parent
+	err := r.db.Where("id IN ?", ids).Find(&parents).Error
+	return parents, err
+}
+
+// DeleteMany permanently deletes parents merged into another one.
+func (r *parentRepository) DeleteMany(ids []int) error {
+	return r.db.Unscoped().Where("id IN ?", ids).Delete(&model.Parent{}).Error
+}
+
+// Reencrypt writes the encrypted columns of every parent, trashed ones
+// included, again with the current key and recomputes the blind indexes.
+func (r *parentRepository) Reencrypt() (int, error) {
+	return r.reencrypt(r.db.Unscoped())
+}
+
+// FillBlindIndexes does what Reencrypt does, but only for parents with a
+// value that has no blind index yet, such as rows written before
+// encryption.
+func (r *parentRepository) FillBlindIndexes() (int, error) {
+	condition, err := missingBlindIndexes(r.db, &model.Parent{})
+	if err != nil {
+		return 0, err
+	}
+	return r.reencrypt(r.db.Unscoped().Where(condition))
+}
+
+func (r *parentRepository) reencrypt(query *gorm.DB) (int, error) {
+	columns, err := encryptedColumns(r.db, &model.Parent{})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	var parents []model.Parent
+	err = query.FindInBatches(&parents, reencryptBatchSize, func(tx *gorm.DB, batch int) error {
+		for i := range parents {
+			parents[i].SetBlindIndexes()
+			if err := r.db.Unscoped().Model(&parents[i]).Select(columns).UpdateColumns(&parents[i]).Error; err != nil {
+				return err
+			}
+		}
+		count += len(parents)
+		return nil
+	}).Error
+	return count, err
+}
+
+// Anonymize overwrites personal fields for good.
+func (r *parentRepository) Anonymize(id int, fields map[string]interface{}) error {
+	return scrubFields(r.db, &model.Parent{}, id, fields)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"project_sdu/model"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -9,11 +10,16 @@ import (
 type PostRepository interface {
 	Create(post *model.Post) error
 	Update(slug string, post *model.Post) error
+	Patch(slug string, version int, fields map[string]interface{}) error
 	Delete(slug string) error
+	GetTrash(page model.PageRequest) ([]model.Post, *model.PageMeta, error)
+	Restore(slug string) error
+	Purge(slug string) error
+	PurgeDeletedBefore(before time.Time) (int64, error)
 	GetByID(id int) (*model.Post, error)
 	GetBySlug(slug string) (*model.Post, error)
-	GetAll(limit, page int, q string) ([]model.Post, error)
-	GetPublished(limit, offset int) ([]model.Post, error)
+	GetAll(page model.PageRequest, q string) ([]model.Post, *model.PageMeta, error)
+	GetPublished(page model.PageRequest) ([]model.Post, *model.PageMeta, error)
 	CountAll() (int, error)
 }
 
@@ -29,13 +35,19 @@ func (r *postRepository) Create(post *model.Post) error {
 	return r.db.Create(post).Error
 }
 
+// Update expects post.Version to be the version the caller read.
 func (r *postRepository) Update(slug string, post *model.Post) error {
-	return r.db.Model(&model.Post{}).
-		Where("slug = ?", slug).
-		Updates(post).
-		Error
+	version := post.Version
+	post.Version = version + 1
+	return updateVersioned(r.db, &model.Post{}, "slug = ?", slug, version, post)
+}
+
+func (r *postRepository) Patch(slug string, version int, fields map[string]interface{}) error {
+	fields["Version"] = version + 1
+	return updateVersioned(r.db, &model.Post{}, "slug = ?", slug, version, fields)
 }
 
+// Delete moves the post to the trash.
 func (r *postRepository) Delete(slug string) error {
 	return r.db.
 		Where("slug = ?", slug).
@@ -61,32 +73,28 @@ func (r *postRepository) GetBySlug(slug string) (*model.Post, error) {
 	return &post, nil
 }
 
-func (r *postRepository) GetAll(limit, page int, q string) ([]model.Post, error) {
+func (r *postRepository) GetAll(page model.PageRequest, q string) ([]model.Post, *model.PageMeta, error) {
 	var posts []model.Post
 	db := r.db
-	offset := (page - 1) * limit
 
 	if q != "" {
 		db = db.Where("title ILIKE ? OR content ILIKE ?", "%"+q+"%", "%"+q+"%")
 	}
 
-	err := db.
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&posts).Error
-	return posts, err
+	meta, err := paginate(db, &posts, page, pageOrder{Column: "created_at", Desc: true})
+	return posts, meta, err
 }
 
-func (r *postRepository) GetPublished(limit, offset int) ([]model.Post, error) {
+// GetPublished skips posts without published_at, which could not be
+// ordered by it.
+func (r *postRepository) GetPublished(page model.PageRequest) ([]model.Post, *model.PageMeta, error) {
 	var posts []model.Post
-	err := r.db.
+	db := r.db.
 		Where("published = ?", true).
-		Order("published_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&posts).Error
-	return posts, err
+		Where("published_at IS NOT NULL")
+
+	meta, err := paginate(db, &posts, page, pageOrder{Column: "published_at", Desc: true})
+	return posts, meta, err
 }
 
 func (r *postRepository) CountAll() (int, error) {
@@ -99,3 +107,21 @@ func (r *postRepository) CountAll() (int, error) {
 
 	return int(count), nil
 }
+
+func (r *postRepository) GetTrash(page model.PageRequest) ([]model.Post, *model.PageMeta, error) {
+	var posts []model.Post
+	meta, err := listTrash(r.db, &posts, page)
+	return posts, meta, err
+}
+
+func (r *postRepository) Restore(slug string) error {
+	return restoreFromTrash(r.db, &model.Post{}, "slug = ?", slug)
+}
+
+func (r *postRepository) Purge(slug string) error {
+	return purgeFromTrash(r.db, &model.Post{}, "slug = ?", slug)
+}
+
+func (r *postRepository) PurgeDeletedBefore(before time.Time) (int64, error) {
+	return purgeDeletedBefore(r.db, &model.Post{}, before)
+}
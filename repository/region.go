@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"project_sdu/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RegionRepository interface {
+	GetByCode(code string) (*model.Region, error)
+	GetByLevel(level model.RegionLevel, q string) ([]model.Region, error)
+	GetChildren(parentCode string, q string) ([]model.Region, error)
+	Upsert(regions []model.Region) error
+	CountAll() (int, error)
+}
+
+type regionRepository struct {
+	db *gorm.DB
+}
+
+func NewRegionRepository(db *gorm.DB) RegionRepository {
+	return &regionRepository{db}
+}
+
+func (r *regionRepository) GetByCode(code string) (*model.Region, error) {
+	var region model.Region
+	err := r.db.Where("code = ?", code).First(&region).Error
+	if err != nil {
+		return nil, err
+	}
+	return &region, nil
+}
+
+func (r *regionRepository) GetByLevel(level model.RegionLevel, q string) ([]model.Region, error) {
+	var regions []model.Region
+
+	db := r.db.Where("level = ?", level)
+	if q != "" {
+		db = db.Where("name ILIKE ?", "%"+q+"%")
+	}
+
+	err := db.Order("code ASC").Find(&regions).Error
+	return regions, err
+}
+
+func (r *regionRepository) GetChildren(parentCode string, q string) ([]model.Region, error) {
+	var regions []model.Region
+
+	db := r.db.Where("parent_code = ?", parentCode)
+	if q != "" {
+		db = db.Where("name ILIKE ?", "%"+q+"%")
+	}
+
+	err := db.Order("code ASC").Find(&regions).Error
+	return regions, err
+}
+
+// Upsert inserts regions, updating name and postal code of existing codes.
+func (r *regionRepository) Upsert(regions []model.Region) error {
+	if len(regions) == 0 {
+		return nil
+	}
+
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "level", "parent_code", "postal_code"}),
+	}).CreateInBatches(regions, 1000).Error
+}
+
+func (r *regionRepository) CountAll() (int, error) {
+	var count int64
+	err := r.db.Model(&model.Region{}).Count(&count).Error
+	return int(count), err
+}
@@ -10,6 +10,7 @@ type RequirementRepository interface {
 	Create(requirement *model.Requirement) error
 	GetAll() ([]model.Requirement, error)
 	Update(id int, requirement *model.Requirement) error
+	Patch(id int, fields map[string]interface{}) error
 	Delete(id int) error
 	GetByID(id int) (*model.Requirement, error)
 }
@@ -36,6 +37,13 @@ func (r *requirementRepository) Update(id int, requirement *model.Requirement) e
 	return r.db.Model(&model.Requirement{}).Where("id = ?", id).Updates(requirement).Error
 }
 
+func (r *requirementRepository) Patch(id int, fields map[string]interface{}) error {
+	return r.db.Model(&model.Requirement{}).
+		Where("id = ?", id).
+		Updates(fields).
+		Error
+}
+
 func (r *requirementRepository) Delete(id int) error {
 	return r.db.Delete(&model.Requirement{}, id).Error
 }
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"project_sdu/model"
+
+	"gorm.io/gorm"
+)
+
+type RetentionLogRepository interface {
+	Create(entry *model.RetentionPurgeLog) error
+	GetAll(page model.PageRequest) ([]model.RetentionPurgeLog, *model.PageMeta, error)
+}
+
+type retentionLogRepository struct {
+	db *gorm.DB
+}
+
+func NewRetentionLogRepository(db *gorm.DB) RetentionLogRepository {
+	return &retentionLogRepository{db}
+}
+
+func (r *retentionLogRepository) Create(entry *model.RetentionPurgeLog) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *retentionLogRepository) GetAll(page model.PageRequest) ([]model.RetentionPurgeLog, *model.PageMeta, error) {
+	var entries []model.RetentionPurgeLog
+	meta, err := paginate(r.db, &entries, page, pageOrder{Column: "created_at", Desc: true})
+	return entries, meta, err
+}
+
+const hasDraftCondition = "EXISTS (SELECT 1 FROM ppdb_drafts WHERE ppdb_drafts.student_id = students.id)"
+
+// retentionStatusConditions tell the retention statuses apart.
+var retentionStatusConditions = map[string]string{
+	model.RetentionRejected: "students.is_accepted = false",
+	model.RetentionAccepted: "students.is_accepted = true AND students.enrolled_at IS NULL",
+	model.RetentionEnrolled: "students.enrolled_at IS NOT NULL",
+}
+
+// retentionDataConditions match students that still hold the data, either
+// on the student row or in the PPDB draft it was submitted from.
+var retentionDataConditions = map[string]string{
+	model.RetentionDocuments: "(students.photo IS NOT NULL OR students.kartu_keluarga IS NOT NULL OR students.akta_kelahiran IS NOT NULL OR students.ijazah_skl IS NOT NULL OR " + hasDraftCondition + ")",
+	model.RetentionHealth:    "(students.blood_type IS NOT NULL OR students.berat_kg IS NOT NULL OR students.tinggi_cm IS NOT NULL OR students.riwayat_penyakit IS NOT NULL OR " + hasDraftCondition + ")",
+	model.RetentionPersonal:  "students.anonymized_at IS NULL",
+}
@@ -0,0 +1,24 @@
+package repository
+
+import "gorm.io/gorm"
+
+// scrubFields overwrites the given fields of a row, trashed or not, and bumps
+// its version. fields are keyed by Go field name like a patch.
+func scrubFields(db *gorm.DB, m interface{}, id int, fields map[string]interface{}) error {
+	if err := sealFields(db, m, fields); err != nil {
+		return err
+	}
+	fields["Version"] = gorm.Expr("version + 1")
+
+	result := db.Unscoped().
+		Model(m).
+		Where("id = ?", id).
+		Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
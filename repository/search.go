@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"html"
+	"project_sdu/encryption"
+	"project_sdu/model"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+type SearchRepository interface {
+	SearchStudents(q string, limit int) ([]model.SearchHit, error)
+	SearchParents(q string, limit int) ([]model.SearchHit, error)
+	SearchPosts(q string, limit int) ([]model.SearchHit, error)
+}
+
+type searchRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	return &searchRepository{db}
+}
+
+// headlineOptions wraps matched words in control characters rather than
+// <mark>, so the text can be HTML-escaped before the marks are put in; names
+// come from the public form and may contain markup.
+const (
+	headlineStart   = "\x02"
+	headlineStop    = "\x03"
+	headlineOptions = "StartSel=" + headlineStart + ", StopSel=" + headlineStop + ", HighlightAll=true"
+)
+
+var headlineMarks = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+
+// markHighlights turns the headlines of the hits into safe HTML.
+func markHighlights(hits []model.SearchHit) {
+	for i := range hits {
+		hits[i].Highlight = headlineMarks.Replace(html.EscapeString(hits[i].Highlight))
+	}
+}
+
+// searchArgs are the named arguments of the search queries: the text query
+// with every word as a prefix, the raw words for trigram similarity, and the
+// blind index for an exact NIK or NISN.
+func searchArgs(q string) map[string]interface{} {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words = append(words, word+":*")
+	}
+	q = strings.TrimSpace(q)
+
+	return map[string]interface{}{
+		"tsquery": strings.Join(words, " & "),
+		"q":       q,
+		"hash":    encryption.BlindIndex(&q),
+	}
+}
+
+// studentSearchCondition matches students by name, origin school, birthplace
+// and region, by a name with a typo, by exact NIK or NISN, or by the name of
+// a parent.
+const studentSearchCondition = `(
+	search_vector @@ to_tsquery('simple', @tsquery)
+	OR @q <% full_name
+	OR nik_hash = @hash OR nisn_hash = @hash
+	OR parent_id IN (SELECT id FROM parents WHERE ` + parentSearchCondition + `)
+)`
+
+const parentSearchCondition = `(
+	search_vector @@ to_tsquery('simple', @tsquery)
+	OR @q <% (coalesce(father_name, '') || ' ' || coalesce(mother_name, '') || ' ' || coalesce(wali_name, ''))
+	OR father_nik_hash = @hash OR mother_nik_hash = @hash OR wali_nik_hash = @hash
+)`
+
+// searchStudents narrows a student query to the search matches.
+func searchStudents(db *gorm.DB, q string) *gorm.DB {
+	return db.Where(studentSearchCondition, searchArgs(q))
+}
+
+// SearchStudents ranks text matches by ts_rank and typos by similarity; an
+// exact NIK or NISN always comes first.
+func (r *searchRepository) SearchStudents(q string, limit int) ([]model.SearchHit, error) {
+	hits := []model.SearchHit{}
+	args := searchArgs(q)
+	args["limit"] = limit
+
+	err := r.db.Raw(`
+		SELECT id, full_name AS title, asal_sekolah AS subtitle,
+			ts_headline('simple', full_name, to_tsquery('simple', @tsquery), '`+headlineOptions+`') AS highlight,
+			GREATEST(ts_rank(search_vector, to_tsquery('simple', @tsquery)), word_similarity(@q, full_name))
+				+ CASE WHEN nik_hash = @hash OR nisn_hash = @hash THEN 1 ELSE 0 END AS rank
+		FROM students
+		WHERE deleted_at IS NULL AND `+studentSearchCondition+`
+		ORDER BY rank DESC, id ASC
+		LIMIT @limit`, args).
+		Scan(&hits).Error
+	markHighlights(hits)
+	return hits, err
+}
+
+func (r *searchRepository) SearchParents(q string, limit int) ([]model.SearchHit, error) {
+	hits := []model.SearchHit{}
+	args := searchArgs(q)
+	args["limit"] = limit
+
+	err := r.db.Raw(`
+		SELECT id, names AS title, NULL AS subtitle,
+			ts_headline('simple', names, to_tsquery('simple', @tsquery), '`+headlineOptions+`') AS highlight,
+			GREATEST(ts_rank(search_vector, to_tsquery('simple', @tsquery)), word_similarity(@q, names))
+				+ CASE WHEN father_nik_hash = @hash OR mother_nik_hash = @hash OR wali_nik_hash = @hash THEN 1 ELSE 0 END AS rank
+		FROM (
+			SELECT *, concat_ws(' / ', father_name, mother_name, wali_name) AS names
+			FROM parents
+			WHERE deleted_at IS NULL AND `+parentSearchCondition+`
+		) matches
+		ORDER BY rank DESC, id ASC
+		LIMIT @limit`, args).
+		Scan(&hits).Error
+	markHighlights(hits)
+	return hits, err
+}
+
+// SearchPosts covers drafts as well as published posts.
+func (r *searchRepository) SearchPosts(q string, limit int) ([]model.SearchHit, error) {
+	hits := []model.SearchHit{}
+	args := searchArgs(q)
+	args["limit"] = limit
+
+	err := r.db.Raw(`
+		SELECT id, title, description AS subtitle,
+			ts_headline('simple', title, to_tsquery('simple', @tsquery), '`+headlineOptions+`') AS highlight,
+			GREATEST(ts_rank(search_vector, to_tsquery('simple', @tsquery)), word_similarity(@q, title)) AS rank
+		FROM posts
+		WHERE deleted_at IS NULL AND (search_vector @@ to_tsquery('simple', @tsquery) OR @q <% title)
+		ORDER BY rank DESC, id ASC
+		LIMIT @limit`, args).
+		Scan(&hits).Error
+	markHighlights(hits)
+	return hits, err
+}
@@ -1,27 +1,66 @@
 package repository
 
 import (
-	"errors"
+	"project_sdu/encryption"
 	"project_sdu/model"
+	"project_sdu/validation"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 var (
-	ErrNIKExists  = errors.New("NIK sudah terdaftar")
-	ErrNISNExists = errors.New("NISN sudah terdaftar")
+	ErrNIKExists  = validation.NewFieldError(validation.MsgNIKExists)
+	ErrNISNExists = validation.NewFieldError(validation.MsgNISNExists)
+	ErrNISExists  = validation.NewFieldError(validation.MsgNISExists)
 )
 
 type StudentRepository interface {
 	Create(student *model.Student) error
-	GetStudentsByBatchID(batchID int, limit int, page int, q string) ([]model.Student, error)
+	GetStudentsByBatchID(batchID int, page model.PageRequest, q string) ([]model.Student, *model.PageMeta, error)
 	GetByID(id int) (*model.Student, error)
-	GetAll(limit int, page int, q string, batchID *int, isAccepted *bool) ([]model.Student, error)
+	CheckIdentityTaken(student *model.Student) error
+	GetAll(page model.PageRequest, filter model.StudentFilter) ([]model.Student, *model.PageMeta, error)
+	EachStudent(filter model.StudentFilter, batchSize int, fn func([]model.Student) error) error
 	Update(id int, student *model.Student) error
+	Patch(id int, version int, fields map[string]interface{}) error
 	Delete(id int) error
+	DeletePermanently(id int) error
+	GetTrash(page model.PageRequest) ([]model.Student, *model.PageMeta, error)
+	Restore(id int) error
+	Purge(id int) error
+	PurgeDeletedBefore(before time.Time) (int64, error)
 	CountAll() (int, error)
 	CountByBatchID(batchID int) (int, error)
+	GetEnrolled(page model.PageRequest, q string, tahunMasuk string) ([]model.Student, *model.PageMeta, error)
+	Enroll(id int, nis string, tahunMasuk string, enrolledAt time.Time) error
+	NextNISSequence(year int) (int, error)
+	GetAgeOverrides(batchID *int) ([]model.Student, error)
+	GetRankedByDistance(batchID int) ([]model.Student, error)
+	GetWithCoordinates() ([]model.Student, error)
+	UpdateDistance(id int, distanceKm *float64) error
+	GetSiblings(studentID int, parentID int) ([]model.Student, error)
+	ReassignParent(fromParentIDs []int, toParentID int) error
+	ReassignBatch(fromBatchID int, toBatchID int) error
+	GetAllByBatch(batchID int) ([]model.Student, error)
+	GetAllByBatchIncludingTrashed(batchID int) ([]model.Student, error)
+	GetByIDs(ids []int) ([]model.Student, error)
+	Reencrypt() (int, error)
+	FillBlindIndexes() (int, error)
+	GetIncludingTrashed(id int) (*model.Student, error)
+	CountByParentID(parentID int, exceptID int) (int, error)
+	Anonymize(id int, fields map[string]interface{}) error
+	GetRetentionCandidates(rule model.RetentionRule, endedBefore time.Time) ([]model.Student, error)
+	ClearFields(id int, fields []string) error
+}
+
+func preloadParent(db *gorm.DB) *gorm.DB {
+	return db.Preload("Parent")
+}
+
+func preloadParentAndBatch(db *gorm.DB) *gorm.DB {
+	return db.Preload("Parent").Preload("Batch")
 }
 
 type studentRepository struct {
@@ -32,24 +71,31 @@ func NewStudentRepo(db *gorm.DB) StudentRepository {
 	return &studentRepository{db}
 }
 
+// Create reports a taken NIK or NISN through the unique indexes on their
+// blind indexes.
 func (r *studentRepository) Create(student *model.Student) error {
-	err := r.db.Create(student).Error
-	if err != nil {
-		if strings.Contains(err.Error(), "idx_students_nik") {
-			return ErrNIKExists
-		}
-		if strings.Contains(err.Error(), "idx_students_nisn") {
-			return ErrNISNExists
-		}
-		return err
+	student.SetBlindIndexes()
+	return identityError(r.db.Create(student).Error)
+}
+
+// identityError turns a violation of the unique NIK or NISN index into
+// ErrNIKExists or ErrNISNExists.
+func identityError(err error) error {
+	if err == nil {
+		return nil
 	}
-	return nil
+	if strings.Contains(err.Error(), "idx_students_nik") {
+		return ErrNIKExists
+	}
+	if strings.Contains(err.Error(), "idx_students_nisn") {
+		return ErrNISNExists
+	}
+	return err
 }
 
-func (r *studentRepository) GetStudentsByBatchID(batchID int, limit int, page int, q string) ([]model.Student, error) {
+func (r *studentRepository) GetStudentsByBatchID(batchID int, page model.PageRequest, q string) ([]model.Student, *model.PageMeta, error) {
 	var students []model.Student
 
-	offset := (page - 1) * limit
 	db := r.db
 
 	db = db.Where("batch_id = ?", batchID)
@@ -58,18 +104,40 @@ func (r *studentRepository) GetStudentsByBatchID(batchID int, limit int, page in
 		db = db.Where("full_name ILIKE ?", "%"+q+"%")
 	}
 
-	err := db.
-		Preload("Parent").
-		Order("full_name ASC").
-		Limit(limit).
-		Offset(offset).
-		Find(&students).Error
-
+	meta, err := paginate(db, &students, page, pageOrder{Column: "full_name"}, preloadParent)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return students, nil
+	return students, meta, nil
+}
+
+// CheckIdentityTaken returns ErrNIKExists or ErrNISNExists when another
+// student, trashed ones included, has the student's NIK or NISN.
+func (r *studentRepository) CheckIdentityTaken(student *model.Student) error {
+	for _, identity := range []struct {
+		column string
+		hash   *string
+		err    error
+	}{
+		{"nik_hash", encryption.BlindIndex(student.Nik), ErrNIKExists},
+		{"nisn_hash", encryption.BlindIndex(student.Nisn), ErrNISNExists},
+	} {
+		if identity.hash == nil {
+			continue
+		}
+		var count int64
+		err := r.db.Unscoped().Model(&model.Student{}).
+			Where(identity.column+" = ? AND id <> ?", *identity.hash, student.ID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return identity.err
+		}
+	}
+	return nil
 }
 
 func (r *studentRepository) GetByID(id int) (*model.Student, error) {
@@ -87,56 +155,136 @@ func (r *studentRepository) GetByID(id int) (*model.Student, error) {
 	return &student, nil
 }
 
-func (r *studentRepository) GetAll(limit int, page int, q string, batchID *int, isAccepted *bool) ([]model.Student, error) {
+func (r *studentRepository) GetAll(page model.PageRequest, filter model.StudentFilter) ([]model.Student, *model.PageMeta, error) {
 	var students []model.Student
 
-	offset := (page - 1) * limit
+	db := filterStudents(r.db, filter)
 
-	db := r.db
+	meta, err := paginateSorted(db, &students, page, studentOrders(filter), preloadParentAndBatch)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Filter search name
-	if q != "" {
-		db = db.Where("full_name ILIKE ?", "%"+q+"%")
+	return students, meta, nil
+}
+
+// EachStudent calls fn with the students matched by filter, in its sort
+// order, batchSize at a time, so exports never load all of them at once.
+func (r *studentRepository) EachStudent(filter model.StudentFilter, batchSize int, fn func([]model.Student) error) error {
+	var students []model.Student
+	return eachPage(filterStudents(r.db, filter), &students, batchSize, studentOrders(filter), func() error {
+		return fn(students)
+	}, preloadParentAndBatch)
+}
+
+// studentOrders is the sort of filter, by name when it has none.
+func studentOrders(filter model.StudentFilter) []pageOrder {
+	orders := make([]pageOrder, len(filter.Sort))
+	for i, sort := range filter.Sort {
+		orders[i] = pageOrder{Column: sort.Field, Desc: sort.Desc}
+	}
+	if len(orders) == 0 {
+		orders = []pageOrder{{Column: "full_name"}}
+	}
+	return orders
+}
+
+// filterStudents applies the applicant list filters.
+func filterStudents(db *gorm.DB, filter model.StudentFilter) *gorm.DB {
+	// Full-text search, see searchStudents
+	if filter.Q != "" {
+		db = searchStudents(db, filter.Q)
 	}
 
 	// Filter based on batch id
-	if batchID != nil && *batchID != 0 {
-		db = db.Where("batch_id = ?", *batchID)
+	if filter.BatchID != nil {
+		db = db.Where("batch_id = ?", *filter.BatchID)
 	}
 
 	// Filter by is_accepted (true/false)
-	if isAccepted != nil {
-		db = db.Where("is_accepted = ?", *isAccepted)
+	if filter.IsAccepted != nil {
+		db = db.Where("is_accepted = ?", *filter.IsAccepted)
 	}
 
-	err := db.
-		Preload("Parent").
-		Preload("Batch").
-		Order("full_name ASC").
-		Limit(limit).
-		Offset(offset).
-		Find(&students).
-		Error
+	// Filter by distance from home to school (zonasi)
+	if filter.MaxDistanceKm != nil {
+		db = db.Where("distance_km <= ?", *filter.MaxDistanceKm)
+	}
 
-	if err != nil {
-		return nil, err
+	if filter.Gender != nil {
+		db = db.Where("gender = ?", *filter.Gender)
+	}
+	if filter.Agama != nil {
+		db = db.Where("agama = ?", *filter.Agama)
+	}
+	if filter.AsalSekolah != "" {
+		db = db.Where("asal_sekolah ILIKE ?", "%"+filter.AsalSekolah+"%")
+	}
+	if filter.KabupatenKode != "" {
+		db = db.Where("kabupaten_kode = ?", filter.KabupatenKode)
+	}
+	if filter.KecamatanKode != "" {
+		db = db.Where("kecamatan_kode = ?", filter.KecamatanKode)
+	}
+	if filter.Jalur != "" {
+		db = db.Where("batch_id IN (SELECT id FROM batches WHERE jalur ILIKE ?)", filter.Jalur)
 	}
 
-	return students, nil
+	if filter.RegisteredFrom != nil {
+		db = db.Where("created_at >= ?", filter.RegisteredFrom.Time)
+	}
+	if filter.RegisteredTo != nil {
+		db = db.Where("created_at < ?", filter.RegisteredTo.AddDate(0, 0, 1))
+	}
+
+	// All four uploads present
+	documents := "COALESCE(photo, '') <> '' AND COALESCE(kartu_keluarga, '') <> '' AND " +
+		"COALESCE(akta_kelahiran, '') <> '' AND COALESCE(ijazah_skl, '') <> ''"
+	if filter.DocumentsComplete != nil {
+		if *filter.DocumentsComplete {
+			db = db.Where(documents)
+		} else {
+			db = db.Where("NOT (" + documents + ")")
+		}
+	}
+
+	// Age today: born on or before today minus min_age years, and after
+	// today minus max_age+1 years
+	today := time.Now()
+	if filter.MinAge != nil {
+		db = db.Where("tanggal_lahir <= ?", model.NewDate(today.Year()-*filter.MinAge, today.Month(), today.Day()))
+	}
+	if filter.MaxAge != nil {
+		db = db.Where("tanggal_lahir > ?", model.NewDate(today.Year()-*filter.MaxAge-1, today.Month(), today.Day()))
+	}
+
+	return db
 }
 
+// Update expects student.Version, and student.Parent.Version when the parent
+// is edited along, to be the versions the caller read, and returns
+// ErrVersionConflict when either row has moved on since. Run it in a
+// transaction so a parent conflict also undoes the student write.
 func (r *studentRepository) Update(id int, student *model.Student) error {
 	var existingStudent model.Student
 	if err := r.db.Preload("Parent").First(&existingStudent, id).Error; err != nil {
 		return err
 	}
 
-	if err := r.db.Model(&existingStudent).Updates(student).Error; err != nil {
-		return err
+	// The parent is written on its own below, against its own version
+	parent := student.Parent
+	student.Parent = nil
+	defer func() { student.Parent = parent }()
+
+	student.SetBlindIndexes()
+	version := student.Version
+	student.Version = version + 1
+	if err := updateVersioned(r.db, &existingStudent, "id = ?", id, version, student); err != nil {
+		return identityError(err)
 	}
 
-	if student.Parent != nil {
-		if err := r.db.Model(&existingStudent.Parent).Updates(student.Parent).Error; err != nil {
+	if parent != nil && existingStudent.Parent != nil {
+		if err := NewParentRepo(r.db).Update(existingStudent.Parent.ID, parent); err != nil {
 			return err
 		}
 	}
@@ -144,10 +292,45 @@ func (r *studentRepository) Update(id int, student *model.Student) error {
 	return nil
 }
 
+// Patch writes the given fields as they are, including nil, false and "".
+// Like Update it only applies to the given version and bumps it.
+func (r *studentRepository) Patch(id int, version int, fields map[string]interface{}) error {
+	if err := sealFields(r.db, &model.Student{}, fields); err != nil {
+		return err
+	}
+	fields["Version"] = version + 1
+	return identityError(updateVersioned(r.db, &model.Student{}, "id = ?", id, version, fields))
+}
+
+// Delete moves the student to the trash.
 func (r *studentRepository) Delete(id int) error {
 	return r.db.Delete(&model.Student{}, id).Error
 }
 
+// DeletePermanently skips the trash, e.g. for records merged into another
+// one, whose NIK and NISN must be free for the kept record.
+func (r *studentRepository) DeletePermanently(id int) error {
+	return r.db.Unscoped().Delete(&model.Student{}, id).Error
+}
+
+func (r *studentRepository) GetTrash(page model.PageRequest) ([]model.Student, *model.PageMeta, error) {
+	var students []model.Student
+	meta, err := listTrash(r.db, &students, page, preloadParent)
+	return students, meta, err
+}
+
+func (r *studentRepository) Restore(id int) error {
+	return restoreFromTrash(r.db, &model.Student{}, "id = ?", id)
+}
+
+func (r *studentRepository) Purge(id int) error {
+	return purgeFromTrash(r.db, &model.Student{}, "id = ?", id)
+}
+
+func (r *studentRepository) PurgeDeletedBefore(before time.Time) (int64, error) {
+	return purgeDeletedBefore(r.db, &model.Student{}, before)
+}
+
 func (r *studentRepository) CountAll() (int, error) {
 	var count int64
 	err := r.db.Model(&model.Student{}).Count(&count).Error
@@ -161,3 +344,300 @@ func (r *studentRepository) CountByBatchID(batchID int) (int, error) {
 		Count(&count).Error
 	return int(count), err
 }
+
+func (r *studentRepository) GetEnrolled(page model.PageRequest, q string, tahunMasuk string) ([]model.Student, *model.PageMeta, error) {
+	var students []model.Student
+
+	db := r.db.Where("enrolled_at IS NOT NULL")
+
+	if q != "" {
+		db = db.Where("full_name ILIKE ? OR nis ILIKE ?", "%"+q+"%", "%"+q+"%")
+	}
+
+	if tahunMasuk != "" {
+		db = db.Where("tahun_masuk = ?", tahunMasuk)
+	}
+
+	meta, err := paginate(db, &students, page, pageOrder{Column: "nis"}, preloadParentAndBatch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return students, meta, nil
+}
+
+// Enroll assigns a NIS to an accepted applicant. The applicant row is kept as is,
+// so registration data stays available as history.
+func (r *studentRepository) Enroll(id int, nis string, tahunMasuk string, enrolledAt time.Time) error {
+	result := r.db.Model(&model.Student{}).
+		Where("id = ? AND enrolled_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"nis":         nis,
+			"tahun_masuk": tahunMasuk,
+			"enrolled_at": enrolledAt,
+			"version":     gorm.Expr("version + 1"),
+		})
+
+	if result.Error != nil {
+		if strings.Contains(result.Error.Error(), "idx_students_nis") {
+			return ErrNISExists
+		}
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// NextNISSequence atomically increments and returns the sequence for the given year.
+func (r *studentRepository) NextNISSequence(year int) (int, error) {
+	var seq int
+	err := r.db.Raw(`
+		INSERT INTO nis_sequences (year, last_seq) VALUES (?, 1)
+		ON CONFLICT (year) DO UPDATE SET last_seq = nis_sequences.last_seq + 1
+		RETURNING last_seq`, year).
+		Scan(&seq).Error
+	return seq, err
+}
+
+func (r *studentRepository) GetAgeOverrides(batchID *int) ([]model.Student, error) {
+	var students []model.Student
+
+	db := r.db.Where("age_override = ?", true)
+
+	if batchID != nil && *batchID != 0 {
+		db = db.Where("batch_id = ?", *batchID)
+	}
+
+	err := db.
+		Preload("Batch").
+		Order("tanggal_lahir ASC").
+		Find(&students).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return students, nil
+}
+
+// GetRankedByDistance lists applicants of a batch nearest first. Ties go to the
+// older applicant, then to whoever registered first.
+func (r *studentRepository) GetRankedByDistance(batchID int) ([]model.Student, error) {
+	var students []model.Student
+
+	err := r.db.
+		Where("batch_id = ? AND distance_km IS NOT NULL", batchID).
+		Order("distance_km ASC").
+		Order("tanggal_lahir ASC NULLS LAST").
+		Order("created_at ASC").
+		Find(&students).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return students, nil
+}
+
+func (r *studentRepository) GetWithCoordinates() ([]model.Student, error) {
+	var students []model.Student
+	err := r.db.
+		Select("id", "latitude", "longitude").
+		Where("latitude IS NOT NULL AND longitude IS NOT NULL").
+		Find(&students).
+		Error
+	return students, err
+}
+
+func (r *studentRepository) UpdateDistance(id int, distanceKm *float64) error {
+	return r.db.Model(&model.Student{}).
+		Where("id = ?", id).
+		UpdateColumn("distance_km", distanceKm).
+		Error
+}
+
+func (r *studentRepository) GetSiblings(studentID int, parentID int) ([]model.Student, error) {
+	var students []model.Student
+
+	err := r.db.
+		Select("id", "full_name", "tanggal_lahir", "nis", "is_accepted", "batch_id").
+		Where("parent_id = ? AND id <> ?", parentID, studentID).
+		Preload("Batch").
+		Order("tanggal_lahir ASC").
+		Find(&students).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return students, nil
+}
+
+func (r *studentRepository) ReassignParent(fromParentIDs []int, toParentID int) error {
+	return r.db.Model(&model.Student{}).
+		Where("parent_id IN ?", fromParentIDs).
+		Updates(map[string]interface{}{
+			"parent_id": toParentID,
+			"version":   gorm.Expr("version + 1"),
+		}).
+		Error
+}
+
+// ReassignBatch moves every student of a batch, trashed ones included, to
+// another batch.
+func (r *studentRepository) ReassignBatch(fromBatchID int, toBatchID int) error {
+	return r.db.Unscoped().
+		Model(&model.Student{}).
+		Where("batch_id = ?", fromBatchID).
+		Updates(map[string]interface{}{
+			"batch_id": toBatchID,
+			"version":  gorm.Expr("version + 1"),
+		}).
+		Error
+}
+
+func (r *studentRepository) GetAllByBatch(batchID int) ([]model.Student, error) {
+	var students []model.Student
+
+	err := r.db.
+		Where("batch_id = ?", batchID).
+		Preload("Parent").
+		Order("id ASC").
+		Find(&students).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return students, nil
+}
+
+// GetAllByBatchIncludingTrashed lists the applicants of a batch, trashed
+// ones included, as those still point to the batch.
+func (r *studentRepository) GetAllByBatchIncludingTrashed(batchID int) ([]model.Student, error) {
+	var students []model.Student
+	err := r.db.Unscoped().
+		Where("batch_id = ?", batchID).
+		Order("id ASC").
+		Find(&students).
+		Error
+	return students, err
+}
+
+func (r *studentRepository) GetByIDs(ids []int) ([]model.Student, error) {
+	var students []model.Student
+	err := r.db.Where("id IN ?", ids).Preload("Parent").Find(&students).Error
+	return students, err
+}
+
+// Reencrypt writes the encrypted columns of every student, trashed ones
+// included, again with the current key and recomputes the blind indexes.
+func (r *studentRepository) Reencrypt() (int, error) {
+	return r.reencrypt(r.db.Unscoped())
+}
+
+// FillBlindIndexes does what Reencrypt does, but only for students with a
+// value that has no blind index yet, such as rows written before
+// encryption.
+func (r *studentRepository) FillBlindIndexes() (int, error) {
+	condition, err := missingBlindIndexes(r.db, &model.Student{})
+	if err != nil {
+		return 0, err
+	}
+	return r.reencrypt(r.db.Unscoped().Where(condition))
+}
+
+func (r *studentRepository) reencrypt(query *gorm.DB) (int, error) {
+	columns, err := encryptedColumns(r.db, &model.Student{})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	var students []model.Student
+	err = query.FindInBatches(&students, reencryptBatchSize, func(tx *gorm.DB, batch int) error {
+		for i := range students {
+			students[i].SetBlindIndexes()
+			if err := r.db.Unscoped().Model(&students[i]).Select(columns).UpdateColumns(&students[i]).Error; err != nil {
+				return err
+			}
+		}
+		count += len(students)
+		return nil
+	}).Error
+	return count, err
+}
+
+// GetIncludingTrashed loads a student with its parent and batch even when
+// any of them is in the trash.
+func (r *studentRepository) GetIncludingTrashed(id int) (*model.Student, error) {
+	var student model.Student
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+	err := r.db.Unscoped().
+		Preload("Parent", unscoped).
+		Preload("Batch", unscoped).
+		First(&student, id).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &student, nil
+}
+
+// CountByParentID counts the students of a parent other than exceptID,
+// trashed ones included.
+func (r *studentRepository) CountByParentID(parentID int, exceptID int) (int, error) {
+	var count int64
+	err := r.db.Unscoped().
+		Model(&model.Student{}).
+		Where("parent_id = ? AND id <> ?", parentID, exceptID).
+		Count(&count).Error
+	return int(count), err
+}
+
+// Anonymize overwrites personal fields for good. The date of birth kept in
+// tanggal_lahir_legacy by the date migration is cleared as well.
+func (r *studentRepository) Anonymize(id int, fields map[string]interface{}) error {
+	if err := scrubFields(r.db, &model.Student{}, id, fields); err != nil {
+		return err
+	}
+	if r.db.Migrator().HasColumn(&model.Student{}, "tanggal_lahir_legacy") {
+		return r.db.Exec(`UPDATE students SET tanggal_lahir_legacy = NULL WHERE id = ?`, id).Error
+	}
+	return nil
+}
+
+// GetRetentionCandidates returns the students, trashed ones included, that
+// match the rule's status, still hold its data and whose batch ended before
+// the given time.
+func (r *studentRepository) GetRetentionCandidates(rule model.RetentionRule, endedBefore time.Time) ([]model.Student, error) {
+	var students []model.Student
+	err := r.db.Unscoped().
+		Joins("JOIN batches ON batches.id = students.batch_id").
+		Where("batches.end_date < ?", endedBefore).
+		Where(retentionStatusConditions[rule.Status]).
+		Where(retentionDataConditions[rule.Data]).
+		Preload("Batch", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Order("students.id ASC").
+		Find(&students).
+		Error
+	return students, err
+}
+
+// ClearFields sets the given fields to NULL, e.g. documents past their
+// retention period.
+func (r *studentRepository) ClearFields(id int, fields []string) error {
+	values := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		values[field] = nil
+	}
+	return scrubFields(r.db, &model.Student{}, id, values)
+}
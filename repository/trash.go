@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"errors"
+	"project_sdu/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrStillReferenced is returned when purging a row that students, trashed
+// or not, still point to.
+var ErrStillReferenced = errors.New("record is still referenced by students")
+
+// listTrash loads one page of trashed rows, most recently deleted first.
+func listTrash(db *gorm.DB, dest interface{}, page model.PageRequest, scopes ...func(*gorm.DB) *gorm.DB) (*model.PageMeta, error) {
+	return paginate(db.Unscoped().Where("deleted_at IS NOT NULL"), dest, page, pageOrder{Column: "deleted_at", Desc: true}, scopes...)
+}
+
+// restoreFromTrash takes the row matched by where out of the trash and bumps
+// its version. A row that is not in the trash is reported as not found.
+func restoreFromTrash(db *gorm.DB, m interface{}, where string, key interface{}) error {
+	result := db.Unscoped().
+		Model(m).
+		Where(where, key).
+		Where("deleted_at IS NOT NULL").
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// purgeFromTrash permanently deletes the row matched by where, which must be
+// in the trash.
+func purgeFromTrash(db *gorm.DB, m interface{}, where string, key interface{}) error {
+	result := db.Unscoped().
+		Where(where, key).
+		Where("deleted_at IS NOT NULL").
+		Delete(m)
+	if result.Error != nil {
+		if strings.Contains(result.Error.Error(), "violates foreign key constraint") {
+			return ErrStillReferenced
+		}
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// purgeDeletedBefore permanently deletes rows trashed before the given time
+// and returns how many were deleted.
+func purgeDeletedBefore(db *gorm.DB, m interface{}, before time.Time) (int64, error) {
+	result := db.Unscoped().
+		Where("deleted_at < ?", before).
+		Delete(m)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,48 @@
+package repository
+
+import "gorm.io/gorm"
+
+// UnitOfWork runs several repository calls in one database transaction.
+type UnitOfWork interface {
+	Transaction(fn func(tx *Tx) error) error
+}
+
+// Tx hands out repositories bound to the running transaction.
+type Tx struct {
+	db *gorm.DB
+}
+
+func (t *Tx) Students() StudentRepository {
+	return NewStudentRepo(t.db)
+}
+
+func (t *Tx) Parents() ParentRepository {
+	return NewParentRepo(t.db)
+}
+
+func (t *Tx) Batches() BatchRepository {
+	return NewBatchRepository(t.db)
+}
+
+func (t *Tx) Drafts() DraftRepository {
+	return NewDraftRepository(t.db)
+}
+
+func (t *Tx) RetentionLogs() RetentionLogRepository {
+	return NewRetentionLogRepository(t.db)
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{db}
+}
+
+// Transaction commits when fn returns nil and rolls back on error or panic.
+func (u *unitOfWork) Transaction(fn func(tx *Tx) error) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&Tx{tx})
+	})
+}
@@ -10,6 +10,7 @@ type UserRepository interface {
 	Add(user model.User) error
 	CheckAvail(user model.User) (model.User, error)
 	GetUserByID(id int) (model.User, error)
+	UpdateRole(email string, role model.UserRole) error
 }
 
 type userRepository struct {
@@ -45,3 +46,14 @@ func (u *userRepository) GetUserByID(id int) (model.User, error) {
 
 	return user, nil 
 }
+
+func (u *userRepository) UpdateRole(email string, role model.UserRole) error {
+	result := u.db.Model(&model.User{}).Where("email = ?", email).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
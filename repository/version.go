@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict is returned when a row was changed by someone else
+// after the caller read it.
+var ErrVersionConflict = errors.New("record has been modified since it was read")
+
+// updateVersioned updates the row matched by where only while it still has
+// the expected version. values must carry the next version, so the update
+// bumps it. A missing row is reported as gorm.ErrRecordNotFound.
+func updateVersioned(db *gorm.DB, m interface{}, where string, key interface{}, version int, values interface{}) error {
+	result := db.Model(m).
+		Where(where, key).
+		Where("version = ?", version).
+		Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(m).Where(where, key).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ErrVersionConflict
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"project_sdu/model"
+	"project_sdu/repository"
+)
+
+type AccessLogService interface {
+	Record(entry *model.SensitiveDataAccessLog) error
+	GetAll(page model.PageRequest, resource string, resourceID int) ([]model.SensitiveDataAccessLog, *model.PageMeta, error)
+}
+
+type accessLogService struct {
+	accessLogRepo repository.AccessLogRepository
+}
+
+func NewAccessLogService(accessLogRepo repository.AccessLogRepository) AccessLogService {
+	return &accessLogService{accessLogRepo}
+}
+
+// Record must succeed before unmasked data is handed out.
+func (s *accessLogService) Record(entry *model.SensitiveDataAccessLog) error {
+	return s.accessLogRepo.Create(entry)
+}
+
+func (s *accessLogService) GetAll(page model.PageRequest, resource string, resourceID int) ([]model.SensitiveDataAccessLog, *model.PageMeta, error) {
+	return s.accessLogRepo.GetAll(page, resource, resourceID)
+}
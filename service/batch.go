@@ -4,26 +4,40 @@ import (
 	"errors"
 	"project_sdu/model"
 	"project_sdu/repository"
+	"sort"
+
+	"gorm.io/gorm"
 )
 
+var ErrBatchHasStudents = errors.New("batch cannot be deleted because it has associated students")
+
 type BatchService interface {
 	Create(batch *model.Batch) error
 	Update(id int, batch *model.Batch) error
-	Delete(id int) error
+	Patch(id int, version int, patch Patch) (*model.Batch, error)
+	Delete(id int, reassignTo *int) (*model.DeleteImpact, error)
+	PreviewDelete(id int, reassignTo *int) (*model.DeleteImpact, error)
+	GetTrash(page model.PageRequest) ([]model.Batch, *model.PageMeta, error)
+	Restore(id int) (*model.Batch, error)
+	Purge(id int) error
 	GetByID(id int) (*model.Batch, error)
-	GetAll(limit, page int, q string) ([]model.Batch, error)
+	GetAll(page model.PageRequest, q string) ([]model.Batch, *model.PageMeta, error)
 	GetActiveBatch() (*model.Batch, error)
 }
 
 type batchService struct {
-	batchRepo repository.BatchRepository
+	batchRepo   repository.BatchRepository
+	studentRepo repository.StudentRepository
+	uow         repository.UnitOfWork
 }
 
-func NewBatchService(batchRepo repository.BatchRepository) BatchService {
-	return &batchService{batchRepo}
+func NewBatchService(batchRepo repository.BatchRepository, studentRepo repository.StudentRepository, uow repository.UnitOfWork) BatchService {
+	return &batchService{batchRepo, studentRepo, uow}
 }
 
 func (s *batchService) Create(batch *model.Batch) error {
+	sort.Float64s(batch.ZonaRadiusKm)
+
 	if err := s.batchRepo.Create(batch); err != nil {
 		return err
 	}
@@ -31,6 +45,8 @@ func (s *batchService) Create(batch *model.Batch) error {
 }
 
 func (s *batchService) Update(id int, batch *model.Batch) error {
+	sort.Float64s(batch.ZonaRadiusKm)
+
 	batchExist, _ := s.batchRepo.GetActiveBatch()
 
 	if batchExist != nil && batch.IsActive != nil && *batch.IsActive && batchExist.ID != id {
@@ -43,29 +59,151 @@ func (s *batchService) Update(id int, batch *model.Batch) error {
 	return nil
 }
 
-func (s *batchService) Delete(id int) error {
-	if err := s.batchRepo.Delete(id); err != nil {
-		return err
+func (s *batchService) Patch(id int, version int, patch Patch) (*model.Batch, error) {
+	existing, err := s.batchRepo.GetByID(id)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	req := model.NewBatchRequest(existing)
+	if err := applyPatch(&req, patch, model.BatchPatchFields); err != nil {
+		return nil, err
+	}
+
+	batch := req.ToModel()
+	sort.Float64s(batch.ZonaRadiusKm)
+
+	if patch.Has("is_active") && batch.IsActive != nil && *batch.IsActive {
+		active, _ := s.batchRepo.GetActiveBatch()
+		if active != nil && active.ID != id {
+			return nil, errors.New("there is already an active batch exist")
+		}
+	}
+
+	if err := s.batchRepo.Patch(id, version, patchedColumns(batch, patch.Fields())); err != nil {
+		return nil, err
+	}
+	return s.batchRepo.GetByID(id)
 }
 
-func (s *batchService) GetByID(id int) (*model.Batch, error) {
+// Delete moves the batch to the trash. A batch with applicants is only
+// deleted when reassignTo names the batch they move to. The impact is also
+// returned when the delete is refused.
+func (s *batchService) Delete(id int, reassignTo *int) (*model.DeleteImpact, error) {
+	var impact *model.DeleteImpact
+	err := s.uow.Transaction(func(tx *repository.Tx) error {
+		var err error
+		impact, err = planBatchDelete(tx.Batches(), tx.Students(), id, reassignTo)
+		if err != nil {
+			return err
+		}
+		if !impact.Allowed {
+			return ErrBatchHasStudents
+		}
+
+		if impact.ReassignTo != nil {
+			if err := tx.Students().ReassignBatch(id, *impact.ReassignTo); err != nil {
+				return err
+			}
+		}
+		return tx.Batches().Delete(id)
+	})
+	return impact, err
+}
+
+func (s *batchService) PreviewDelete(id int, reassignTo *int) (*model.DeleteImpact, error) {
+	return planBatchDelete(s.batchRepo, s.studentRepo, id, reassignTo)
+}
+
+// planBatchDelete works out what deleting a batch affects. Its applicants
+// block the delete unless they are reassigned to another batch.
+func planBatchDelete(batches repository.BatchRepository, students repository.StudentRepository, id int, reassignTo *int) (*model.DeleteImpact, error) {
+	if _, err := batches.GetByID(id); err != nil {
+		return nil, err
+	}
+
+	// Trashed applicants count too: restoring them must not leave them in a
+	// deleted batch.
+	applicants, err := students.GetAllByBatchIncludingTrashed(id)
+	if err != nil {
+		return nil, err
+	}
+
+	impact := model.NewDeleteImpact()
+	if len(applicants) == 0 {
+		return impact, nil
+	}
+
+	if reassignTo == nil {
+		impact.Allowed = false
+		impact.Reason = ErrBatchHasStudents.Error()
+		impact.BlockingStudents = model.NewStudentSummaries(applicants)
+		return impact, nil
+	}
+
+	if *reassignTo == id {
+		return nil, FieldErrors{"reassign_to": errors.New("must be another batch")}
+	}
+	if _, err := batches.GetByID(*reassignTo); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, FieldErrors{"reassign_to": errors.New("batch not found")}
+		}
+		return nil, err
+	}
+
+	impact.ReassignTo = reassignTo
+	impact.ReassignedStudents = model.NewStudentSummaries(applicants)
+	return impact, nil
+}
+
+func (s *batchService) GetTrash(page model.PageRequest) ([]model.Batch, *model.PageMeta, error) {
+	return s.batchRepo.GetTrash(page)
+}
+
+// Restore takes the batch out of the trash. It comes back inactive when
+// another batch has been activated in the meantime.
+func (s *batchService) Restore(id int) (*model.Batch, error) {
+	if err := s.batchRepo.Restore(id); err != nil {
+		return nil, err
+	}
+
 	batch, err := s.batchRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
 
+	if batch.IsActive != nil && *batch.IsActive {
+		active, _ := s.batchRepo.GetActiveBatch()
+		if active != nil && active.ID != id {
+			if err := s.batchRepo.Patch(id, batch.Version, map[string]interface{}{"IsActive": false}); err != nil {
+				return nil, err
+			}
+			return s.batchRepo.GetByID(id)
+		}
+	}
 	return batch, nil
 }
 
-func (s *batchService) GetAll(limit, page int, q string) ([]model.Batch, error) {
-	batches, err := s.batchRepo.GetAll(limit, page, q)
+func (s *batchService) Purge(id int) error {
+	return s.batchRepo.Purge(id)
+}
+
+func (s *batchService) GetByID(id int) (*model.Batch, error) {
+	batch, err := s.batchRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	return batches, nil
+	return batch, nil
+}
+
+func (s *batchService) GetAll(page model.PageRequest, q string) ([]model.Batch, *model.PageMeta, error) {
+	batches, meta, err := s.batchRepo.GetAll(page, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return batches, meta, nil
 }
 
 func (s *batchService) GetActiveBatch() (*model.Batch, error) {
@@ -8,10 +8,11 @@ import (
 type CurriculumService interface {
 	Create(ex *model.Curriculum) error
 	Update(id int, ex *model.Curriculum) error
+	Patch(id int, patch Patch) (*model.Curriculum, error)
 	Delete(id int) error
 	GetByID(id int) (*model.Curriculum, error)
-	GetAll(limit, offset int) ([]model.Curriculum, error)
-	GetByCategory(category string, limit, page int) ([]model.Curriculum, error)
+	GetAll(page model.PageRequest) ([]model.Curriculum, *model.PageMeta, error)
+	GetByCategory(category string, page model.PageRequest) ([]model.Curriculum, *model.PageMeta, error)
 }
 
 type curriculumService struct {
@@ -38,6 +39,23 @@ func (s *curriculumService) Update(id int, ex *model.Curriculum) error {
 	return nil
 }
 
+func (s *curriculumService) Patch(id int, patch Patch) (*model.Curriculum, error) {
+	curriculum, err := s.curriculumRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req := model.NewCurriculumRequest(curriculum)
+	if err := applyPatch(&req, patch, model.CurriculumPatchFields); err != nil {
+		return nil, err
+	}
+
+	if err := s.curriculumRepo.Patch(id, patchedColumns(req.ToModel(), patch.Fields())); err != nil {
+		return nil, err
+	}
+	return s.curriculumRepo.GetByID(id)
+}
+
 func (s *curriculumService) Delete(id int) error {
 	if err := s.curriculumRepo.Delete(id); err != nil {
 		return err
@@ -55,15 +73,15 @@ func (s *curriculumService) GetByID(id int) (*model.Curriculum, error) {
 	return curriculum, nil
 }
 
-func (s *curriculumService) GetAll(limit, page int) ([]model.Curriculum, error) {
-	curriculum, err := s.curriculumRepo.GetAll(limit, page)
+func (s *curriculumService) GetAll(page model.PageRequest) ([]model.Curriculum, *model.PageMeta, error) {
+	curriculum, meta, err := s.curriculumRepo.GetAll(page)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	
-	return curriculum, nil
+	return curriculum, meta, nil
 }
 
-func (s *curriculumService) GetByCategory(category string, limit, page int) ([]model.Curriculum, error) {
-	return s.curriculumRepo.GetByCategory(category, limit, page)
+func (s *curriculumService) GetByCategory(category string, page model.PageRequest) ([]model.Curriculum, *model.PageMeta, error) {
+	return s.curriculumRepo.GetByCategory(category, page)
 }
\ No newline at end of file
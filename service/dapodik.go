@@ -0,0 +1,408 @@
+package service
+
+import (
+	"io"
+	"project_sdu/model"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Codes of the Dapodik reference tables: ref.jenis_kelamin, ref.agama,
+// ref.jenis_tinggal, ref.pekerjaan, ref.jenjang_pendidikan and
+// ref.penghasilan_orangtua_wali.
+var (
+	dapodikJK = map[model.Gender]string{
+		model.Male:   "L",
+		model.Female: "P",
+	}
+
+	dapodikAgama = map[model.Religion]string{
+		model.Islam:     "1",
+		model.Christian: "2",
+		model.Catholic:  "3",
+		model.Hindu:     "4",
+		model.Buddha:    "5",
+		model.Konghucu:  "6",
+	}
+
+	// Living with relatives other than the parents is "Wali" in Dapodik.
+	// The form has one choice for orphanages and pesantren, which Dapodik
+	// tells apart; it is exported as Panti Asuhan.
+	dapodikJenisTinggal = map[model.TinggalBersama]string{
+		model.OrangTua:       "1",
+		model.KakekNenek:     "2",
+		model.PamanBibi:      "2",
+		model.SaudaraKandung: "2",
+		model.Kerabat:        "2",
+		model.PantiPontRen:   "5",
+		model.Lainnya:        "99",
+	}
+)
+
+// dapodikPekerjaanMeninggal is the occupation code of a deceased parent,
+// which is how Dapodik records KeadaanOrtu.
+const dapodikPekerjaanMeninggal = "98"
+
+// codePattern is the code of free text matching pattern.
+type codePattern struct {
+	code    string
+	pattern *regexp.Regexp
+}
+
+// dapodikPekerjaan matches the occupations typed into the form, in order.
+var dapodikPekerjaan = []codePattern{
+	{"98", regexp.MustCompile(`meninggal|almarhum|alm\b`)},
+	{"1", regexp.MustCompile(`tidak bekerja|belum bekerja|ibu rumah tangga|\birt\b`)},
+	{"2", regexp.MustCompile(`nelayan`)},
+	{"3", regexp.MustCompile(`petani|pekebun`)},
+	{"4", regexp.MustCompile(`peternak`)},
+	{"5", regexp.MustCompile(`\bpns\b|\basn\b|\btni\b|polri|polisi|pegawai negeri`)},
+	{"14", regexp.MustCompile(`bumn|bumd`)},
+	{"9", regexp.MustCompile(`wiraswasta`)},
+	{"6", regexp.MustCompile(`swasta|karyawan|pegawai`)},
+	{"8", regexp.MustCompile(`pedagang besar`)},
+	{"7", regexp.MustCompile(`pedagang|dagang`)},
+	{"10", regexp.MustCompile(`wirausaha|usaha`)},
+	{"11", regexp.MustCompile(`buruh`)},
+	{"12", regexp.MustCompile(`pensiun`)},
+	{"13", regexp.MustCompile(`\btki\b|\btkw\b|\bpmi\b|tenaga kerja`)},
+	{"99", regexp.MustCompile(`.`)},
+}
+
+// dapodikPendidikan matches the education levels typed into the form, in
+// order, so that e.g. "D4" is not taken for "D3".
+var dapodikPendidikan = []codePattern{
+	{"0", regexp.MustCompile(`tidak sekolah|tidak tamat sd|belum sekolah`)},
+	{"3", regexp.MustCompile(`putus sd`)},
+	{"7", regexp.MustCompile(`paket a`)},
+	{"8", regexp.MustCompile(`paket b`)},
+	{"9", regexp.MustCompile(`paket c`)},
+	{"40", regexp.MustCompile(`\bs3\b|doktor`)},
+	{"35", regexp.MustCompile(`\bs2\b|magister`)},
+	{"30", regexp.MustCompile(`\bs1\b|sarjana`)},
+	{"23", regexp.MustCompile(`\bd4\b`)},
+	{"22", regexp.MustCompile(`\bd3\b|diploma`)},
+	{"21", regexp.MustCompile(`\bd2\b`)},
+	{"20", regexp.MustCompile(`\bd1\b`)},
+	{"6", regexp.MustCompile(`\bsma\b|\bsmk\b|\bma\b|\bmak\b|\bslta\b|\bstm\b|\bsmea\b`)},
+	{"5", regexp.MustCompile(`\bsmp\b|\bmts\b|\bsltp\b`)},
+	{"4", regexp.MustCompile(`\bsd\b|\bmi\b|sekolah dasar`)},
+	{"2", regexp.MustCompile(`\btk\b|\bra\b`)},
+}
+
+// dapodikPenghasilan are the lower bounds of the income ranges, in rupiah.
+var dapodikPenghasilan = []struct {
+	code string
+	from int
+}{
+	{"16", 20000001},
+	{"15", 5000000},
+	{"14", 2000000},
+	{"13", 1000000},
+	{"12", 500000},
+	{"11", 1},
+}
+
+// dapodikTidakBerpenghasilan is the income code of a parent without income.
+const dapodikTidakBerpenghasilan = "99"
+
+var amountPattern = regexp.MustCompile(`\d[\d.,]*`)
+
+// dapodikColumn is a column of the Dapodik peserta didik import sheet.
+type dapodikColumn struct {
+	header    string
+	mandatory bool
+	value     func(s *model.Student) dapodikValue
+}
+
+// dapodikValue is an exported cell. unmapped is set when the student has a
+// value that matches no Dapodik code, so the cell is left empty.
+type dapodikValue struct {
+	cell     string
+	unmapped bool
+}
+
+func textCell(value *string) dapodikValue {
+	if value == nil {
+		return dapodikValue{}
+	}
+	return dapodikValue{cell: strings.TrimSpace(*value)}
+}
+
+func numberCell(value *int) dapodikValue {
+	if value == nil {
+		return dapodikValue{}
+	}
+	return dapodikValue{cell: strconv.Itoa(*value)}
+}
+
+func decimalCell(value *float64) dapodikValue {
+	if value == nil {
+		return dapodikValue{}
+	}
+	return dapodikValue{cell: strconv.FormatFloat(*value, 'f', -1, 64)}
+}
+
+// codeCell looks a value up in a code list.
+func codeCell[T ~string](codes map[T]string, value *T) dapodikValue {
+	if value == nil || *value == "" {
+		return dapodikValue{}
+	}
+	c, ok := codes[*value]
+	return dapodikValue{cell: c, unmapped: !ok}
+}
+
+// matchCell finds the first code whose pattern matches free text.
+func matchCell(patterns []codePattern, value *string) dapodikValue {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return dapodikValue{}
+	}
+	lower := strings.ToLower(*value)
+	for _, p := range patterns {
+		if p.pattern.MatchString(lower) {
+			return dapodikValue{cell: p.code}
+		}
+	}
+	return dapodikValue{unmapped: true}
+}
+
+// penghasilanCell puts an income typed into the form, such as "Rp 1.500.000"
+// or "1-2 juta", into its Dapodik range by the first amount.
+func penghasilanCell(value *string) dapodikValue {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return dapodikValue{}
+	}
+	lower := strings.ToLower(*value)
+	if strings.Contains(lower, "tidak") {
+		return dapodikValue{cell: dapodikTidakBerpenghasilan}
+	}
+
+	found := amountPattern.FindString(lower)
+	if found == "" {
+		return dapodikValue{unmapped: true}
+	}
+	var amount int
+	switch {
+	case strings.Contains(lower, "juta"), strings.Contains(lower, "ribu"), strings.Contains(lower, "rb"):
+		// Decimal comma or point: "1,5 juta", "750 rb"
+		f, err := strconv.ParseFloat(strings.Replace(found, ",", ".", 1), 64)
+		if err != nil {
+			return dapodikValue{unmapped: true}
+		}
+		unit := 1000.0
+		if strings.Contains(lower, "juta") {
+			unit = 1000000
+		}
+		amount = int(f * unit)
+	default:
+		// Thousands separators: "1.500.000"
+		amount, _ = strconv.Atoi(strings.NewReplacer(".", "", ",", "").Replace(found))
+	}
+
+	if amount == 0 {
+		return dapodikValue{cell: dapodikTidakBerpenghasilan}
+	}
+	for _, r := range dapodikPenghasilan {
+		if amount >= r.from {
+			return dapodikValue{cell: r.code}
+		}
+	}
+	return dapodikValue{unmapped: true}
+}
+
+// pekerjaanCell is a parent's occupation, or "Sudah Meninggal" when
+// KeadaanOrtu says the parent died.
+func pekerjaanCell(s *model.Student, job func(p *model.Parent) *string, deceased ...model.KeadaanOrtu) dapodikValue {
+	if s.KeadaanOrtu != nil {
+		for _, k := range deceased {
+			if *s.KeadaanOrtu == k {
+				return dapodikValue{cell: dapodikPekerjaanMeninggal}
+			}
+		}
+	}
+	if s.Parent == nil {
+		return dapodikValue{}
+	}
+	return matchCell(dapodikPekerjaan, job(s.Parent))
+}
+
+// parentField reads a field of the student's parent.
+func parentField(s *model.Student, field func(p *model.Parent) *string) *string {
+	if s.Parent == nil {
+		return nil
+	}
+	return field(s.Parent)
+}
+
+func dateCell(value *model.Date) dapodikValue {
+	if value == nil {
+		return dapodikValue{}
+	}
+	return dapodikValue{cell: value.String()}
+}
+
+// dapodikColumns is the layout of the Dapodik peserta didik import sheet.
+var dapodikColumns = []dapodikColumn{
+	{"Nama", true, func(s *model.Student) dapodikValue { return dapodikValue{cell: strings.TrimSpace(s.FullName)} }},
+	{"NIPD", false, func(s *model.Student) dapodikValue { return textCell(s.Nis) }},
+	{"JK", true, func(s *model.Student) dapodikValue {
+		return codeCell(dapodikJK, &s.Gender)
+	}},
+	{"NISN", true, func(s *model.Student) dapodikValue { return textCell(s.Nisn) }},
+	{"Tempat Lahir", true, func(s *model.Student) dapodikValue { return textCell(s.TempatLahir) }},
+	{"Tanggal Lahir", true, func(s *model.Student) dapodikValue { return dateCell(s.TanggalLahir) }},
+	{"NIK", true, func(s *model.Student) dapodikValue { return textCell(s.Nik) }},
+	{"Agama", true, func(s *model.Student) dapodikValue { return codeCell(dapodikAgama, s.Agama) }},
+	{"Kewarganegaraan", true, func(s *model.Student) dapodikValue {
+		// WNI unless the form says otherwise
+		if s.Kewarganegaraan == nil || strings.TrimSpace(*s.Kewarganegaraan) == "" {
+			return dapodikValue{cell: "ID"}
+		}
+		switch strings.ToLower(strings.TrimSpace(*s.Kewarganegaraan)) {
+		case "wni", "indonesia", "id":
+			return dapodikValue{cell: "ID"}
+		}
+		return dapodikValue{unmapped: true}
+	}},
+	{"Alamat Jalan", true, func(s *model.Student) dapodikValue { return textCell(s.AlamatJalan) }},
+	{"RT", false, func(s *model.Student) dapodikValue { return textCell(s.Rt) }},
+	{"RW", false, func(s *model.Student) dapodikValue { return textCell(s.Rw) }},
+	{"Desa/Kelurahan", true, func(s *model.Student) dapodikValue { return textCell(s.DesaKelurahan) }},
+	{"Kecamatan", true, func(s *model.Student) dapodikValue { return textCell(s.Kecamatan) }},
+	{"Kode Pos", false, func(s *model.Student) dapodikValue { return textCell(s.KodePos) }},
+	{"Lintang", false, func(s *model.Student) dapodikValue { return decimalCell(s.Latitude) }},
+	{"Bujur", false, func(s *model.Student) dapodikValue { return decimalCell(s.Longitude) }},
+	{"Jenis Tinggal", true, func(s *model.Student) dapodikValue { return codeCell(dapodikJenisTinggal, s.TinggalBersama) }},
+	{"HP", false, func(s *model.Student) dapodikValue { return textCell(s.Phone) }},
+	{"E-Mail", false, func(s *model.Student) dapodikValue { return textCell(s.Email) }},
+	{"Sekolah Asal", false, func(s *model.Student) dapodikValue { return textCell(s.AsalSekolah) }},
+	{"Anak ke-berapa", false, func(s *model.Student) dapodikValue { return numberCell(s.AnakKe) }},
+	{"Jumlah Saudara Kandung", false, func(s *model.Student) dapodikValue {
+		if s.DariBersaudara == nil || *s.DariBersaudara < 1 {
+			return dapodikValue{}
+		}
+		siblings := *s.DariBersaudara - 1
+		return numberCell(&siblings)
+	}},
+	{"Berat Badan", false, func(s *model.Student) dapodikValue { return numberCell(s.BeratKg) }},
+	{"Tinggi Badan", false, func(s *model.Student) dapodikValue { return numberCell(s.TinggiCm) }},
+	{"Jarak Rumah ke Sekolah (km)", false, func(s *model.Student) dapodikValue { return decimalCell(s.DistanceKm) }},
+
+	{"Nama Ayah", false, func(s *model.Student) dapodikValue {
+		return textCell(parentField(s, func(p *model.Parent) *string { return p.FatherName }))
+	}},
+	{"NIK Ayah", false, func(s *model.Student) dapodikValue {
+		return textCell(parentField(s, func(p *model.Parent) *string { return p.FatherNik }))
+	}},
+	{"Jenjang Pendidikan Ayah", false, func(s *model.Student) dapodikValue {
+		return matchCell(dapodikPendidikan, parentField(s, func(p *model.Parent) *string { return p.FatherEducation }))
+	}},
+	{"Pekerjaan Ayah", false, func(s *model.Student) dapodikValue {
+		return pekerjaanCell(s, func(p *model.Parent) *string { return p.FatherJob }, model.Yatim, model.YatimPiatu)
+	}},
+	{"Penghasilan Ayah", false, func(s *model.Student) dapodikValue {
+		return penghasilanCell(parentField(s, func(p *model.Parent) *string { return p.FatherIncome }))
+	}},
+
+	{"Nama Ibu Kandung", true, func(s *model.Student) dapodikValue {
+		return textCell(parentField(s, func(p *model.Parent) *string { return p.MotherName }))
+	}},
+	{"NIK Ibu", false, func(s *model.Student) dapodikValue {
+		return textCell(parentField(s, func(p *model.Parent) *string { return p.MotherNik }))
+	}},
+	{"Jenjang Pendidikan Ibu", false, func(s *model.Student) dapodikValue {
+		return matchCell(dapodikPendidikan, parentField(s, func(p *model.Parent) *string { return p.MotherEducation }))
+	}},
+	{"Pekerjaan Ibu", false, func(s *model.Student) dapodikValue {
+		return pekerjaanCell(s, func(p *model.Parent) *string { return p.MotherJob }, model.Piatu, model.YatimPiatu)
+	}},
+	{"Penghasilan Ibu", false, func(s *model.Student) dapodikValue {
+		return penghasilanCell(parentField(s, func(p *model.Parent) *string { return p.MotherIncome }))
+	}},
+
+	{"Nama Wali", false, func(s *model.Student) dapodikValue {
+		return textCell(parentField(s, func(p *model.Parent) *string { return p.WaliName }))
+	}},
+	{"NIK Wali", false, func(s *model.Student) dapodikValue {
+		return textCell(parentField(s, func(p *model.Parent) *string { return p.WaliNik }))
+	}},
+}
+
+// dapodikRow returns the cells of a student and the report of the columns
+// Dapodik will reject.
+func dapodikRow(s *model.Student, cells []string) model.DapodikReportEntry {
+	entry := model.DapodikReportEntry{StudentID: s.ID, FullName: s.FullName, Missing: []string{}, Unmapped: []string{}}
+	for i, column := range dapodikColumns {
+		value := column.value(s)
+		cells[i] = value.cell
+		switch {
+		case value.unmapped:
+			entry.Unmapped = append(entry.Unmapped, column.header)
+		case column.mandatory && value.cell == "":
+			entry.Missing = append(entry.Missing, column.header)
+		}
+	}
+	return entry
+}
+
+// dapodikFilter narrows a filter to the accepted students, the only ones
+// that go into Dapodik.
+func dapodikFilter(filter model.StudentFilter) model.StudentFilter {
+	accepted := true
+	filter.IsAccepted = &accepted
+	return filter
+}
+
+// ExportDapodik writes the accepted students matched by filter in the
+// layout of the Dapodik peserta didik import, unmasked.
+func (s *exportService) ExportDapodik(w io.Writer, format string, filter model.StudentFilter) error {
+	header := make([]string, len(dapodikColumns))
+	for i, column := range dapodikColumns {
+		header[i] = column.header
+	}
+
+	rw, err := newRowWriter(w, format, "Peserta Didik", header)
+	if err != nil {
+		return err
+	}
+
+	cells := make([]string, len(dapodikColumns))
+	err = s.studentRepo.EachStudent(dapodikFilter(filter), exportBatchSize, func(students []model.Student) error {
+		for i := range students {
+			dapodikRow(&students[i], cells)
+			if err := rw.WriteRow(cells); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return rw.Close()
+}
+
+// DapodikReport lists the accepted students whose mandatory Dapodik fields
+// are empty, or whose values match no Dapodik code.
+func (s *exportService) DapodikReport(filter model.StudentFilter) (*model.DapodikReport, error) {
+	report := &model.DapodikReport{Students: []model.DapodikReportEntry{}}
+	cells := make([]string, len(dapodikColumns))
+
+	err := s.studentRepo.EachStudent(dapodikFilter(filter), exportBatchSize, func(students []model.Student) error {
+		for i := range students {
+			report.Total++
+			entry := dapodikRow(&students[i], cells)
+			if len(entry.Missing) == 0 && len(entry.Unmapped) == 0 {
+				report.Complete++
+				continue
+			}
+			report.Students = append(report.Students, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
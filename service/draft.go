@@ -0,0 +1,250 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"project_sdu/model"
+	"project_sdu/repository"
+	"project_sdu/validation"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultDraftTTLDays is how long an untouched draft can still be resumed.
+const DefaultDraftTTLDays = 30
+
+var (
+	ErrDraftNotFound  = errors.New("draf pendaftaran tidak ditemukan")
+	ErrDraftExpired   = errors.New("draf pendaftaran sudah kedaluwarsa, silakan mulai pendaftaran baru")
+	ErrDraftSubmitted = errors.New("draf pendaftaran sudah dikirim")
+)
+
+type DraftService interface {
+	Create() (*model.PPDBDraft, error)
+	Get(token string) (*model.PPDBDraft, error)
+	SaveStep(token string, data model.DraftStepData) (*model.PPDBDraft, error)
+	Submit(token string) (*model.PPDBDraft, error)
+}
+
+type draftService struct {
+	draftRepo      repository.DraftRepository
+	regionRepo     repository.RegionRepository
+	studentService StudentService
+}
+
+func NewDraftService(draftRepo repository.DraftRepository, regionRepo repository.RegionRepository, studentService StudentService) DraftService {
+	return &draftService{draftRepo, regionRepo, studentService}
+}
+
+func (s *draftService) Create() (*model.PPDBDraft, error) {
+	token, err := newDraftToken()
+	if err != nil {
+		return nil, err
+	}
+
+	draft := &model.PPDBDraft{
+		Token:          token,
+		Payload:        "{}",
+		CompletedSteps: []string{},
+		ExpiresAt:      time.Now().AddDate(0, 0, draftTTLDays()),
+		Data:           &model.Student{},
+	}
+	if err := s.draftRepo.Create(draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// Get resumes a draft. A submitted draft is no longer served: the token
+// only proves who started the form, not who may read the application.
+func (s *draftService) Get(token string) (*model.PPDBDraft, error) {
+	return s.openForEdit(token)
+}
+
+func (s *draftService) SaveStep(token string, data model.DraftStepData) (*model.PPDBDraft, error) {
+	draft, err := s.openForEdit(token)
+	if err != nil {
+		return nil, err
+	}
+
+	data.ApplyTo(draft.Data)
+	if err := s.validateStep(data.Step(), draft.Data); err != nil {
+		return nil, err
+	}
+
+	if !draft.HasStep(data.Step()) {
+		draft.CompletedSteps = append(draft.CompletedSteps, string(data.Step()))
+	}
+	// Every save keeps the draft alive for another full period
+	draft.ExpiresAt = time.Now().AddDate(0, 0, draftTTLDays())
+
+	if err := s.store(draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+func (s *draftService) Submit(token string) (*model.PPDBDraft, error) {
+	draft, err := s.openForEdit(token)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := FieldErrors{}
+	for _, step := range model.RequiredDraftSteps {
+		if !draft.HasStep(step) {
+			missing[string(step)] = validation.NewFieldError(validation.MsgDraftStepMissing)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, missing
+	}
+
+	student := draft.Data
+	if errs := validation.StudentIdentity(student); len(errs) > 0 {
+		return nil, FieldErrors(errs)
+	}
+
+	if err := s.studentService.RegisterPPDB(student); err != nil {
+		return nil, err
+	}
+
+	// The application now lives in the students table, encrypted and subject
+	// to erasure and retention, so the draft keeps no copy of it
+	now := time.Now()
+	draft.StudentId = &student.ID
+	draft.SubmittedAt = &now
+	draft.Payload = "{}"
+	if err := s.draftRepo.Update(draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+func (s *draftService) load(token string) (*model.PPDBDraft, error) {
+	draft, err := s.draftRepo.GetByToken(strings.TrimSpace(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDraftNotFound
+		}
+		return nil, err
+	}
+
+	draft.Data = &model.Student{}
+	if err := json.Unmarshal([]byte(draft.Payload), draft.Data); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// openForEdit loads a draft that can still be changed or submitted.
+func (s *draftService) openForEdit(token string) (*model.PPDBDraft, error) {
+	draft, err := s.load(token)
+	if err != nil {
+		return nil, err
+	}
+	if draft.SubmittedAt != nil {
+		return nil, ErrDraftSubmitted
+	}
+	if time.Now().After(draft.ExpiresAt) {
+		return nil, ErrDraftExpired
+	}
+	return draft, nil
+}
+
+func (s *draftService) store(draft *model.PPDBDraft) error {
+	payload, err := json.Marshal(draft.Data)
+	if err != nil {
+		return err
+	}
+	draft.Payload = string(payload)
+	return s.draftRepo.Update(draft)
+}
+
+// validateStep runs the checks of one step that binding tags cannot express:
+// NIK cross-checks, region codes and parent names. Checks that need the whole
+// form, such as the age limit of the active batch, run on submit.
+func (s *draftService) validateStep(step model.DraftStep, student *model.Student) error {
+	errs := FieldErrors{}
+
+	switch step {
+	case model.StepIdentity:
+		for field, msg := range validation.StudentIdentity(student) {
+			errs[field] = msg
+		}
+
+	case model.StepAddress:
+		if err := resolveStudentRegions(s.regionRepo, student, nil); err != nil {
+			fieldErrs, ok := err.(FieldErrors)
+			if !ok {
+				return err
+			}
+			for field, msg := range fieldErrs {
+				errs[field] = msg
+			}
+		}
+		if err := applyDistance(student, nil); err != nil {
+			fieldErrs, ok := err.(FieldErrors)
+			if !ok {
+				return err
+			}
+			for field, msg := range fieldErrs {
+				errs[field] = msg
+			}
+		}
+
+	case model.StepParents:
+		parent := student.Parent
+		if isBlank(parent.FatherName) && isBlank(parent.MotherName) && isBlank(parent.WaliName) {
+			errs["mother_name"] = validation.NewFieldError(validation.MsgParentNameRequired)
+		}
+		parentNiks := []struct {
+			field  string
+			nik    *string
+			gender model.Gender
+		}{
+			{"father_nik", parent.FatherNik, model.Male},
+			{"mother_nik", parent.MotherNik, model.Female},
+			{"wali_nik", parent.WaliNik, ""},
+		}
+		for _, p := range parentNiks {
+			if isBlank(p.nik) {
+				continue
+			}
+			if err := validation.ValidateNIK(strings.TrimSpace(*p.nik), nil, p.gender); err != nil {
+				errs[p.field] = err
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func isBlank(s *string) bool {
+	return s == nil || strings.TrimSpace(*s) == ""
+}
+
+func newDraftToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func draftTTLDays() int {
+	days, err := strconv.Atoi(os.Getenv("PPDB_DRAFT_TTL_DAYS"))
+	if err != nil || days <= 0 {
+		return DefaultDraftTTLDays
+	}
+	return days
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"project_sdu/model"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultDuplicateThreshold is the score from which a pair is reported.
+const DefaultDuplicateThreshold = 0.8
+
+// minNameSimilarity is the name similarity below which two records are never
+// considered the same child.
+const minNameSimilarity = 0.75
+
+// Weights of the signals compared between two applicants. Signals missing on
+// either side are left out and the remaining weights are scaled up.
+var duplicateWeights = map[string]float64{
+	"full_name":     0.40,
+	"tanggal_lahir": 0.25,
+	"parent_phone":  0.15,
+	"tempat_lahir":  0.10,
+	"asal_sekolah":  0.10,
+}
+
+// nameVariants maps common spelling variants to one form before comparing.
+var nameVariants = map[string]string{
+	"muhamad": "muhammad", "muhammmad": "muhammad", "mohammad": "muhammad", "mohamad": "muhammad",
+	"mohammed": "muhammad", "muhammed": "muhammad", "moh": "muhammad", "moch": "muhammad",
+	"mochammad": "muhammad", "muh": "muhammad", "m": "muhammad", "md": "muhammad",
+	"abd": "abdul", "abdoel": "abdul",
+	"achmad": "ahmad", "akhmad": "ahmad", "ahmed": "ahmad",
+	"noor": "nur", "sitti": "siti",
+}
+
+// NormalizeName lowercases a name, drops punctuation and titles, and maps
+// common spelling variants, so "Moch. Rizky" and "Muhammad Rizki" get close.
+func NormalizeName(name string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsSpace(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, name)
+
+	words := strings.Fields(cleaned)
+	for i, word := range words {
+		if variant, ok := nameVariants[word]; ok {
+			words[i] = variant
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// JaroWinkler returns the Jaro-Winkler similarity of two strings in [0, 1].
+func JaroWinkler(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	window := max(len(ra), len(rb))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// nameSimilarity compares normalized names both as written and with their
+// words sorted, so swapped first and last names still match.
+func nameSimilarity(a, b string) float64 {
+	wa, wb := strings.Fields(NormalizeName(a)), strings.Fields(NormalizeName(b))
+	written := wordSimilarity(wa, wb)
+
+	sort.Strings(wa)
+	sort.Strings(wb)
+	return max(written, wordSimilarity(wa, wb))
+}
+
+// wordSimilarity compares names of the same length word by word and takes
+// the least similar pair, so twins sharing a family name still differ by
+// their first names. Names of different lengths are compared as a whole.
+func wordSimilarity(a, b []string) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return JaroWinkler(strings.Join(a, " "), strings.Join(b, " "))
+	}
+
+	similarity := 1.0
+	for i := range a {
+		similarity = min(similarity, JaroWinkler(a[i], b[i]))
+	}
+	return similarity
+}
+
+// birthDateSimilarity is 1 for the same date, 0.5 for dates differing in one
+// component or with day and month swapped (typical typing mistakes).
+func birthDateSimilarity(a, b model.Date) float64 {
+	if a.Equal(b.Time) {
+		return 1
+	}
+
+	same := 0
+	if a.Year() == b.Year() {
+		same++
+	}
+	if a.Month() == b.Month() {
+		same++
+	}
+	if a.Day() == b.Day() {
+		same++
+	}
+	swapped := a.Year() == b.Year() && a.Day() == int(b.Month()) && int(a.Month()) == b.Day()
+	if same == 2 || swapped {
+		return 0.5
+	}
+	return 0
+}
+
+// ScoreDuplicate scores how likely two applicants are the same child and
+// returns the per-signal similarities that went into the score.
+func ScoreDuplicate(a, b *model.Student) (float64, map[string]float64) {
+	components := map[string]float64{
+		"full_name": nameSimilarity(a.FullName, b.FullName),
+	}
+
+	if a.TanggalLahir != nil && b.TanggalLahir != nil && !a.TanggalLahir.IsZero() && !b.TanggalLahir.IsZero() {
+		components["tanggal_lahir"] = birthDateSimilarity(*a.TanggalLahir, *b.TanggalLahir)
+	}
+	if hasText(a.TempatLahir) && hasText(b.TempatLahir) {
+		components["tempat_lahir"] = JaroWinkler(NormalizeName(*a.TempatLahir), NormalizeName(*b.TempatLahir))
+	}
+	if hasText(a.AsalSekolah) && hasText(b.AsalSekolah) {
+		components["asal_sekolah"] = JaroWinkler(NormalizeName(*a.AsalSekolah), NormalizeName(*b.AsalSekolah))
+	}
+	if phoneA, phoneB := parentPhone(a), parentPhone(b); phoneA != "" && phoneB != "" {
+		components["parent_phone"] = 0
+		if phoneA == phoneB {
+			components["parent_phone"] = 1
+		}
+	}
+
+	var weighted, total float64
+	for signal, similarity := range components {
+		weighted += duplicateWeights[signal] * similarity
+		total += duplicateWeights[signal]
+	}
+
+	// A name alone is too weak to call two records the same child, and
+	// clearly different names are siblings (twins share everything else)
+	if total < 0.6 || components["full_name"] < minNameSimilarity {
+		return 0, components
+	}
+	return weighted / total, components
+}
+
+// FindDuplicates scores every pair of applicants and returns the pairs at or
+// above threshold, best first. Pairs with two different NIKs are skipped.
+func FindDuplicates(students []model.Student, threshold float64) []model.DuplicateCandidate {
+	candidates := []model.DuplicateCandidate{}
+
+	for i := 0; i < len(students); i++ {
+		for j := i + 1; j < len(students); j++ {
+			a, b := &students[i], &students[j]
+			if hasText(a.Nik) && hasText(b.Nik) && *a.Nik != *b.Nik {
+				continue
+			}
+
+			score, components := ScoreDuplicate(a, b)
+			if score < threshold {
+				continue
+			}
+
+			candidates = append(candidates, model.DuplicateCandidate{
+				Students:   []model.DuplicateStudent{duplicateSummary(a), duplicateSummary(b)},
+				Score:      score,
+				Components: components,
+			})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
+
+func duplicateSummary(student *model.Student) model.DuplicateStudent {
+	return model.DuplicateStudent{
+		ID:           student.ID,
+		FullName:     student.FullName,
+		TanggalLahir: student.TanggalLahir,
+		TempatLahir:  student.TempatLahir,
+		AsalSekolah:  student.AsalSekolah,
+		CreatedAt:    student.CreatedAt,
+		Completeness: countFilled(student),
+	}
+}
+
+func parentPhone(student *model.Student) string {
+	if student.Parent == nil || student.Parent.NoHpOrtuWali == nil {
+		return ""
+	}
+	return NormalizePhone(*student.Parent.NoHpOrtuWali)
+}
+
+func hasText(value *string) bool {
+	return value != nil && strings.TrimSpace(*value) != ""
+}
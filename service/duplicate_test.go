@@ -0,0 +1,173 @@
+package service
+
+import (
+	"math"
+	"project_sdu/model"
+	"testing"
+	"time"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Muhammad Rizki", "muhammad rizki"},
+		{"Moch. Rizki", "muhammad rizki"},
+		{"M. Rizki", "muhammad rizki"},
+		{"MOHAMMAD  rizki", "muhammad rizki"},
+		{"Abd. Rahman", "abdul rahman"},
+		{"Achmad Noor", "ahmad nur"},
+		{"Sitti Aisyah-Putri", "siti aisyah putri"},
+		{"  ", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeName(tt.name); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"martha", "martha", 1},
+		{"martha", "marhta", 0.9611},
+		{"dwayne", "duane", 0.84},
+		{"dixon", "dicksonx", 0.8133},
+		{"abc", "xyz", 0},
+		{"", "abc", 0},
+		{"abc", "", 0},
+	}
+
+	for _, tt := range tests {
+		if got := JaroWinkler(tt.a, tt.b); math.Abs(got-tt.want) > 0.0001 {
+			t.Errorf("JaroWinkler(%q, %q) = %.4f, want %.4f", tt.a, tt.b, got, tt.want)
+		}
+		if got, reverse := JaroWinkler(tt.a, tt.b), JaroWinkler(tt.b, tt.a); math.Abs(got-reverse) > 1e-9 {
+			t.Errorf("JaroWinkler(%q, %q) = %.4f, but reversed %.4f", tt.a, tt.b, got, reverse)
+		}
+	}
+}
+
+func TestScoreDuplicate(t *testing.T) {
+	date := func(year int, month time.Month, day int) *model.Date {
+		d := model.NewDate(year, month, day)
+		return &d
+	}
+	text := func(s string) *string { return &s }
+	parent := func(phone string) *model.Parent { return &model.Parent{NoHpOrtuWali: &phone} }
+
+	tests := []struct {
+		name string
+		a, b model.Student
+		// minScore and maxScore bound the score; components lists expected
+		// per-signal similarities
+		minScore, maxScore float64
+		components         map[string]float64
+	}{
+		{
+			name:       "name variant with the same birth date and parent phone",
+			a:          model.Student{FullName: "Muhammad Rizki", TanggalLahir: date(2012, 5, 17), Parent: parent("0812-3456-7890")},
+			b:          model.Student{FullName: "Moch. Rizki", TanggalLahir: date(2012, 5, 17), Parent: parent("+62 812 3456 7890")},
+			minScore:   0.99,
+			maxScore:   1,
+			components: map[string]float64{"full_name": 1, "tanggal_lahir": 1, "parent_phone": 1},
+		},
+		{
+			name:       "first and last name swapped",
+			a:          model.Student{FullName: "Rizki Pratama", TanggalLahir: date(2012, 5, 17)},
+			b:          model.Student{FullName: "Pratama Rizki", TanggalLahir: date(2012, 5, 17)},
+			minScore:   0.99,
+			maxScore:   1,
+			components: map[string]float64{"full_name": 1, "tanggal_lahir": 1},
+		},
+		{
+			name:       "day and month of the birth date swapped",
+			a:          model.Student{FullName: "Siti Aisyah", TanggalLahir: date(2012, 3, 7), TempatLahir: text("Mataram")},
+			b:          model.Student{FullName: "Siti Aisyah", TanggalLahir: date(2012, 7, 3), TempatLahir: text("Mataram")},
+			minScore:   0.8,
+			maxScore:   0.9,
+			components: map[string]float64{"full_name": 1, "tanggal_lahir": 0.5, "tempat_lahir": 1},
+		},
+		{
+			name:       "birth dates differing in more than one part",
+			a:          model.Student{FullName: "Siti Aisyah", TanggalLahir: date(2012, 3, 7)},
+			b:          model.Student{FullName: "Siti Aisyah", TanggalLahir: date(2011, 4, 7)},
+			minScore:   0.6,
+			maxScore:   0.7,
+			components: map[string]float64{"full_name": 1, "tanggal_lahir": 0},
+		},
+		{
+			name:     "twins share everything but the first name",
+			a:        model.Student{FullName: "Rizki Basri", TanggalLahir: date(2012, 5, 17), TempatLahir: text("Mataram"), AsalSekolah: text("SDN 1 Mataram"), Parent: parent("081234567890")},
+			b:        model.Student{FullName: "Dewi Basri", TanggalLahir: date(2012, 5, 17), TempatLahir: text("Mataram"), AsalSekolah: text("SDN 1 Mataram"), Parent: parent("081234567890")},
+			minScore: 0,
+			maxScore: 0,
+		},
+		{
+			name:     "first name just below the cut-off",
+			a:        model.Student{FullName: "Fatimah Zahra", TanggalLahir: date(2012, 5, 17), Parent: parent("081234567890")},
+			b:        model.Student{FullName: "Aisyah Zahra", TanggalLahir: date(2012, 5, 17), Parent: parent("081234567890")},
+			minScore: 0,
+			maxScore: 0,
+		},
+		{
+			name:     "typo in a swapped name",
+			a:        model.Student{FullName: "Rizki Pratama", TanggalLahir: date(2012, 5, 17)},
+			b:        model.Student{FullName: "Pratama Riski", TanggalLahir: date(2012, 5, 17)},
+			minScore: 0.9,
+			maxScore: 1,
+		},
+		{
+			name:     "different children",
+			a:        model.Student{FullName: "Ahmad Fauzi", TanggalLahir: date(2012, 5, 17)},
+			b:        model.Student{FullName: "Dewi Lestari", TanggalLahir: date(2012, 5, 17)},
+			minScore: 0,
+			maxScore: 0,
+		},
+		{
+			name:       "name alone is not enough",
+			a:          model.Student{FullName: "Ahmad Fauzi"},
+			b:          model.Student{FullName: "Ahmad Fauzi"},
+			minScore:   0,
+			maxScore:   0,
+			components: map[string]float64{"full_name": 1},
+		},
+		{
+			name:       "different parent phones",
+			a:          model.Student{FullName: "Ahmad Fauzi", TanggalLahir: date(2012, 5, 17), Parent: parent("081234567890")},
+			b:          model.Student{FullName: "Ahmad Fauzi", TanggalLahir: date(2012, 5, 17), Parent: parent("081298765432")},
+			minScore:   0.81,
+			maxScore:   0.82,
+			components: map[string]float64{"full_name": 1, "tanggal_lahir": 1, "parent_phone": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, components := ScoreDuplicate(&tt.a, &tt.b)
+			if score < tt.minScore-1e-9 || score > tt.maxScore+1e-9 {
+				t.Errorf("score = %.4f, want between %.2f and %.2f (components %v)", score, tt.minScore, tt.maxScore, components)
+			}
+			for signal, want := range tt.components {
+				got, ok := components[signal]
+				if !ok {
+					t.Errorf("component %s missing", signal)
+					continue
+				}
+				if math.Abs(got-want) > 1e-9 {
+					t.Errorf("component %s = %.4f, want %.4f", signal, got, want)
+				}
+			}
+
+			reverse, _ := ScoreDuplicate(&tt.b, &tt.a)
+			if math.Abs(score-reverse) > 1e-9 {
+				t.Errorf("score = %.4f, but reversed %.4f", score, reverse)
+			}
+		})
+	}
+}
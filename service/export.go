@@ -0,0 +1,241 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"project_sdu/model"
+	"project_sdu/repository"
+	"project_sdu/spreadsheet"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportBatchSize is how many students an export reads at a time.
+const exportBatchSize = 500
+
+// DefaultExportColumns are exported when no columns are asked for.
+var DefaultExportColumns = []string{
+	"full_name", "nisn", "nik", "gender", "tempat_lahir", "tanggal_lahir", "agama",
+	"asal_sekolah", "alamat_jalan", "desa_kelurahan", "kecamatan", "kabupaten", "phone",
+	"is_accepted", "batch.name", "batch.jalur",
+	"parent.father_name", "parent.mother_name", "parent.no_hp_ortu_wali", "created_at",
+}
+
+// exportSkipped are the StudentResponse and ParentResponse fields that are
+// not columns: bookkeeping, and lists that do not fit in one cell.
+var exportSkipped = map[string]bool{
+	"version": true, "deleted_at": true, "masked": true, "siblings": true, "students": true,
+}
+
+var (
+	// ExportColumns are the columns a student export can have: the fields
+	// of StudentResponse, and those of its parent and batch as parent.x and
+	// batch.x.
+	ExportColumns []string
+	exportFields  = map[string][]int{}
+)
+
+func init() {
+	var collect func(t reflect.Type, prefix string, index []int)
+	collect = func(t reflect.Type, prefix string, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+			if name == "" || name == "-" || exportSkipped[name] {
+				continue
+			}
+			fieldIndex := append(index[:len(index):len(index)], i)
+
+			switch f.Type {
+			case reflect.TypeOf(&model.ParentResponse{}), reflect.TypeOf(&model.BatchSummary{}):
+				if prefix == "" {
+					collect(f.Type.Elem(), name+".", fieldIndex)
+				}
+				continue
+			}
+
+			ExportColumns = append(ExportColumns, prefix+name)
+			exportFields[prefix+name] = fieldIndex
+		}
+	}
+	collect(reflect.TypeOf(model.StudentResponse{}), "", nil)
+}
+
+// ParseExportColumns reads a comma separated column list, e.g.
+// "full_name,gender,parent.father_name". An empty list is the default.
+func ParseExportColumns(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultExportColumns, nil
+	}
+
+	var columns []string
+	for _, column := range strings.Split(value, ",") {
+		column = strings.TrimSpace(column)
+		if _, ok := exportFields[column]; !ok {
+			return nil, FieldErrors{"columns": fmt.Errorf("%s is not an export column", column)}
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+type ExportService interface {
+	ExportStudents(w io.Writer, format string, filter model.StudentFilter, columns []string, unmask bool) error
+	ExportDapodik(w io.Writer, format string, filter model.StudentFilter) error
+	DapodikReport(filter model.StudentFilter) (*model.DapodikReport, error)
+}
+
+type exportService struct {
+	studentRepo repository.StudentRepository
+}
+
+func NewExportService(studentRepo repository.StudentRepository) ExportService {
+	return &exportService{studentRepo}
+}
+
+// rowWriter is a CSV or XLSX file being written.
+type rowWriter interface {
+	WriteRow(cells []string) error
+	Close() error
+}
+
+type csvRowWriter struct {
+	w   *csv.Writer
+	row []string
+}
+
+// WriteRow neutralizes cells Excel would read as a formula.
+func (c *csvRowWriter) WriteRow(cells []string) error {
+	c.row = c.row[:0]
+	for _, cell := range cells {
+		c.row = append(c.row, csvSafeCell(cell))
+	}
+	return c.w.Write(c.row)
+}
+
+// csvSafeCell prefixes a value starting with =, +, -, @, a tab or a carriage
+// return with an apostrophe, so a name like =HYPERLINK(...) from the public
+// form stays text when the file is opened in Excel. Plain numbers such as a
+// negative latitude are left alone.
+func csvSafeCell(cell string) string {
+	if cell == "" || !strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return cell
+	}
+	if _, err := strconv.ParseFloat(cell, 64); err == nil {
+		return cell
+	}
+	return "'" + cell
+}
+
+func (c *csvRowWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// newRowWriter starts a file of the given format with a header row.
+func newRowWriter(w io.Writer, format string, sheetName string, header []string) (rowWriter, error) {
+	var rw rowWriter
+	switch format {
+	case spreadsheet.FormatCSV:
+		cw, err := spreadsheet.WriteCSV(w)
+		if err != nil {
+			return nil, err
+		}
+		rw = &csvRowWriter{w: cw}
+	case spreadsheet.FormatXLSX:
+		xw, err := spreadsheet.NewXLSXWriter(w, sheetName, len(header))
+		if err != nil {
+			return nil, err
+		}
+		rw = xw
+	default:
+		return nil, ErrFileFormat
+	}
+
+	if err := rw.WriteRow(header); err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+// ExportStudents writes the students matched by filter to w, one row each,
+// reading them in batches. Unless unmask is set the values are masked like
+// in the applicant list.
+func (s *exportService) ExportStudents(w io.Writer, format string, filter model.StudentFilter, columns []string, unmask bool) error {
+	rw, err := newRowWriter(w, format, "Pendaftar", columns)
+	if err != nil {
+		return err
+	}
+
+	row := make([]string, len(columns))
+	err = s.studentRepo.EachStudent(filter, exportBatchSize, func(students []model.Student) error {
+		for i := range students {
+			res := model.NewStudentResponse(&students[i])
+			if unmask {
+				res = model.NewUnmaskedStudentResponse(&students[i])
+			}
+
+			value := reflect.ValueOf(res)
+			for j, column := range columns {
+				row[j] = exportCell(value, exportFields[column])
+			}
+			if err := rw.WriteRow(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return rw.Close()
+}
+
+// labeled is implemented by the enums with Indonesian labels.
+type labeled interface {
+	Label() string
+}
+
+// exportCell formats a field for a spreadsheet: enums by their Indonesian
+// label, booleans as Ya or Tidak, and missing values as an empty cell.
+func exportCell(v reflect.Value, index []int) string {
+	field, err := v.FieldByIndexErr(index)
+	if err != nil {
+		// A field of a missing parent or batch
+		return ""
+	}
+	return formatCell(field)
+}
+
+func formatCell(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch value := v.Interface().(type) {
+	case labeled:
+		return value.Label()
+	case model.Date:
+		return value.String()
+	case time.Time:
+		return value.Format("2006-01-02 15:04:05")
+	case bool:
+		if value {
+			return "Ya"
+		}
+		return "Tidak"
+	case string:
+		return value
+	case int:
+		return strconv.Itoa(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -8,9 +8,10 @@ import (
 type FacilityService interface {
 	Create(facility *model.Facility) error
 	Update(id int, facility *model.Facility) error
+	Patch(id int, patch Patch) (*model.Facility, error)
 	Delete(id int) error
 	GetByID(id int) (*model.Facility, error)
-	GetAll(limit, offset int) ([]model.Facility, error)
+	GetAll(page model.PageRequest) ([]model.Facility, *model.PageMeta, error)
 }
 
 type facilityService struct {
@@ -37,6 +38,23 @@ func (s *facilityService) Update(id int, facility *model.Facility) error {
 	return nil
 }
 
+func (s *facilityService) Patch(id int, patch Patch) (*model.Facility, error) {
+	facility, err := s.facilityRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req := model.NewFacilityRequest(facility)
+	if err := applyPatch(&req, patch, model.FacilityPatchFields); err != nil {
+		return nil, err
+	}
+
+	if err := s.facilityRepo.Patch(id, patchedColumns(req.ToModel(), patch.Fields())); err != nil {
+		return nil, err
+	}
+	return s.facilityRepo.GetByID(id)
+}
+
 func (s *facilityService) Delete(id int) error {
 	if err := s.facilityRepo.Delete(id); err != nil {
 		return err
@@ -54,11 +72,11 @@ func (s *facilityService) GetByID(id int) (*model.Facility, error) {
 	return facility, nil
 }
 
-func (s *facilityService) GetAll(limit, page int) ([]model.Facility, error) {
-	facilities, err := s.facilityRepo.GetAll(limit, page)
+func (s *facilityService) GetAll(page model.PageRequest) ([]model.Facility, *model.PageMeta, error) {
+	facilities, meta, err := s.facilityRepo.GetAll(page)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	
-	return facilities, nil
+	return facilities, meta, nil
 }
@@ -9,6 +9,7 @@ type FaqService interface {
 	Create(faq *model.Faq) error
 	GetAll() ([]model.Faq, error)
 	Update(id int, faq *model.Faq) error
+	Patch(id int, patch Patch) (*model.Faq, error)
 	Delete(id int) error
 	GetByID(id int) (*model.Faq, error)
 }
@@ -33,6 +34,23 @@ func (s *faqService) Update(id int, faq *model.Faq) error {
 	return s.faqRepo.Update(id, faq)
 }
 
+func (s *faqService) Patch(id int, patch Patch) (*model.Faq, error) {
+	faq, err := s.faqRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req := model.NewFaqRequest(faq)
+	if err := applyPatch(&req, patch, model.FaqPatchFields); err != nil {
+		return nil, err
+	}
+
+	if err := s.faqRepo.Patch(id, patchedColumns(req.ToModel(), patch.Fields())); err != nil {
+		return nil, err
+	}
+	return s.faqRepo.GetByID(id)
+}
+
 func (s *faqService) Delete(id int) error {
 	return s.faqRepo.Delete(id)
 }
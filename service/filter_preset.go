@@ -0,0 +1,48 @@
+package service
+
+import (
+	"errors"
+	"net/url"
+	"project_sdu/model"
+	"project_sdu/repository"
+)
+
+// FilterPresetService keeps each admin's saved applicant list filters.
+type FilterPresetService interface {
+	GetAll(userID int) ([]model.FilterPreset, error)
+	Save(preset *model.FilterPreset) error
+	Delete(id int, userID int) error
+}
+
+type filterPresetService struct {
+	filterPresetRepo repository.FilterPresetRepository
+}
+
+func NewFilterPresetService(filterPresetRepo repository.FilterPresetRepository) FilterPresetService {
+	return &filterPresetService{filterPresetRepo}
+}
+
+func (s *filterPresetService) GetAll(userID int) ([]model.FilterPreset, error) {
+	return s.filterPresetRepo.GetByUser(userID)
+}
+
+// Save only stores queries the applicant list accepts, so a preset never
+// fails when applied. Errors are keyed as query.<parameter>.
+func (s *filterPresetService) Save(preset *model.FilterPreset) error {
+	query, err := url.ParseQuery(preset.Query)
+	if err != nil {
+		return FieldErrors{"query": errors.New("query must be a URL query string")}
+	}
+	if _, errs := model.ParseStudentFilter(query); errs != nil {
+		fieldErrs := FieldErrors{}
+		for param, msg := range errs {
+			fieldErrs["query."+param] = errors.New(msg)
+		}
+		return fieldErrs
+	}
+	return s.filterPresetRepo.Save(preset)
+}
+
+func (s *filterPresetService) Delete(id int, userID int) error {
+	return s.filterPresetRepo.Delete(id, userID)
+}
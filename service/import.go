@@ -0,0 +1,526 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"project_sdu/model"
+	"project_sdu/repository"
+	"project_sdu/spreadsheet"
+	"project_sdu/validation"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	// MaxImportRows caps the applicants of one import file
+	MaxImportRows = 5000
+	// importProgressEvery is how often, in rows, a running job is saved
+	importProgressEvery = 100
+)
+
+var (
+	ErrFileFormat     = errors.New("format must be csv or xlsx")
+	ErrImportMode     = errors.New("mode must be all_or_nothing or skip_invalid")
+	ErrImportNoHeader = errors.New("the file has no header row")
+)
+
+var errTooManyImportRows = fmt.Errorf("the file has more than %d rows, split it into smaller files", MaxImportRows)
+
+// ImportFile is an uploaded import file.
+type ImportFile interface {
+	io.Reader
+	io.ReaderAt
+}
+
+type ImportService interface {
+	Template(format string, w io.Writer) error
+	Start(job *model.ImportJob, file ImportFile, size int64, mapping map[string]string, lang validation.Lang) error
+	GetJob(id int) (*model.ImportJob, error)
+	GetJobs(page model.PageRequest) ([]model.ImportJob, *model.PageMeta, error)
+	FailInterrupted() error
+}
+
+type importService struct {
+	importJobRepo  repository.ImportJobRepository
+	studentService StudentService
+}
+
+func NewImportService(importJobRepo repository.ImportJobRepository, studentService StudentService) ImportService {
+	return &importService{importJobRepo, studentService}
+}
+
+// importField is an import column and the request field it fills.
+type importField struct {
+	index  []int
+	parent bool // a field of ParentRequest instead of StudentRequest
+}
+
+var (
+	// ImportColumns are the columns of the import template: the JSON names
+	// of StudentRequest, with the parent fields inline.
+	ImportColumns []string
+	importFields  = map[string]importField{}
+)
+
+func init() {
+	var collect func(t reflect.Type, index []int, parent bool)
+	collect = func(t reflect.Type, index []int, parent bool) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			fieldIndex := append(index[:len(index):len(index)], i)
+			switch {
+			case f.Anonymous:
+				collect(f.Type, fieldIndex, parent)
+				continue
+			case f.Type == reflect.TypeOf(&model.ParentRequest{}):
+				collect(f.Type.Elem(), nil, true)
+				continue
+			}
+
+			name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			ImportColumns = append(ImportColumns, name)
+			importFields[name] = importField{index: fieldIndex, parent: parent}
+		}
+	}
+	collect(reflect.TypeOf(model.StudentRequest{}), nil, false)
+}
+
+// Template writes an empty import file with the header row.
+func (s *importService) Template(format string, w io.Writer) error {
+	rw, err := newRowWriter(w, format, "Pendaftar", ImportColumns)
+	if err != nil {
+		return err
+	}
+	return rw.Close()
+}
+
+// Start reads and checks the file, stores the job and processes the rows in
+// the background. Problems with the file as a whole, such as an unknown
+// column in mapping, are returned as FieldErrors before a job is created.
+func (s *importService) Start(job *model.ImportJob, file ImportFile, size int64, mapping map[string]string, lang validation.Lang) error {
+	switch job.Mode {
+	case "":
+		job.Mode = model.ImportAllOrNothing
+	case model.ImportAllOrNothing, model.ImportSkipInvalid:
+	default:
+		return FieldErrors{"mode": ErrImportMode}
+	}
+
+	var (
+		records [][]string
+		err     error
+	)
+	switch job.Format {
+	case spreadsheet.FormatCSV:
+		records, err = spreadsheet.ReadCSV(file)
+	case spreadsheet.FormatXLSX:
+		// Room for a header row above the applicants
+		records, err = spreadsheet.ReadXLSX(file, size, MaxImportRows+1)
+	default:
+		return FieldErrors{"format": ErrFileFormat}
+	}
+	if errors.Is(err, spreadsheet.ErrTooManyRows) {
+		return FieldErrors{"file": errTooManyImportRows}
+	}
+	if err != nil {
+		return FieldErrors{"file": err}
+	}
+
+	rows, err := importRows(records, mapping)
+	if err != nil {
+		return err
+	}
+
+	job.Status = model.ImportPending
+	job.TotalRows = len(rows)
+	job.Errors = []model.ImportRowError{}
+	if err := s.importJobRepo.Create(job); err != nil {
+		return err
+	}
+
+	// The caller responds with job while the copy is updated
+	running := *job
+	go s.run(&running, rows, lang)
+	return nil
+}
+
+func (s *importService) GetJob(id int) (*model.ImportJob, error) {
+	return s.importJobRepo.GetByID(id)
+}
+
+func (s *importService) GetJobs(page model.PageRequest) ([]model.ImportJob, *model.PageMeta, error) {
+	return s.importJobRepo.GetAll(page)
+}
+
+// FailInterrupted fails the jobs a previous server process did not finish.
+func (s *importService) FailInterrupted() error {
+	_, err := s.importJobRepo.FailInterrupted("the server restarted before the import finished, upload the file again")
+	return err
+}
+
+// importRows turns the records of a file into rows keyed by import column.
+// The first non-empty record is the header; blank rows are skipped.
+func importRows(records [][]string, mapping map[string]string) ([]model.ImportRow, error) {
+	start := 0
+	for start < len(records) && blankRecord(records[start]) {
+		start++
+	}
+	if start == len(records) {
+		return nil, FieldErrors{"file": ErrImportNoHeader}
+	}
+
+	columns, err := importHeader(records[start], mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []model.ImportRow
+	for i := start + 1; i < len(records); i++ {
+		if blankRecord(records[i]) {
+			continue
+		}
+		if len(rows) == MaxImportRows {
+			return nil, FieldErrors{"file": errTooManyImportRows}
+		}
+
+		row := model.ImportRow{Number: i + 1, Values: map[string]string{}}
+		for j, value := range records[i] {
+			if j < len(columns) && columns[j] != "" {
+				row.Values[columns[j]] = strings.TrimSpace(value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, FieldErrors{"file": errors.New("the file has no rows below the header")}
+	}
+	return rows, nil
+}
+
+// importHeader finds the import column of every file column. mapping, from
+// file header to import column, takes precedence; other headers match the
+// column of the same name, ignoring case, spaces and dashes. Headers mapped
+// to "" and headers matching no column are ignored.
+func importHeader(header []string, mapping map[string]string) ([]string, error) {
+	errs := FieldErrors{}
+	for from, to := range mapping {
+		if _, ok := importFields[to]; !ok && to != "" {
+			errs["mapping."+from] = fmt.Errorf("%s is not an import column", to)
+		}
+	}
+
+	columns := make([]string, len(header))
+	source := map[string]string{}
+	found := map[string]bool{}
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		column, ok := mapping[name]
+		if ok {
+			found[name] = true
+		} else {
+			column = importColumnName(name)
+			if _, known := importFields[column]; !known {
+				continue
+			}
+		}
+		if column == "" {
+			continue
+		}
+		if other, taken := source[column]; taken {
+			errs["mapping."+name] = fmt.Errorf("%s is already filled from %s", column, other)
+			continue
+		}
+		source[column] = name
+		columns[i] = column
+	}
+
+	for from := range mapping {
+		if !found[from] {
+			errs["mapping."+from] = errors.New("the file has no such header")
+		}
+	}
+	if len(errs) == 0 && source["full_name"] == "" {
+		errs["file"] = errors.New("no column is mapped to full_name")
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return columns, nil
+}
+
+// importColumnName normalizes a header, e.g. "Tanggal Lahir" to tanggal_lahir.
+func importColumnName(header string) string {
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(header)))
+}
+
+func blankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// run validates and, unless it is a dry run, stores the rows of job.
+func (s *importService) run(job *model.ImportJob, rows []model.ImportRow, lang validation.Lang) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.finish(job, model.ImportFailed, fmt.Sprint("import stopped: ", r))
+		}
+	}()
+
+	job.Status = model.ImportRunning
+	s.save(job)
+
+	store := !job.DryRun && job.Mode == model.ImportSkipInvalid
+	var valid []*model.Student
+	var validRows []int
+	identities := map[string]int{}
+
+	for i, row := range rows {
+		student, errs := parseImportRow(row, lang)
+		if len(errs) == 0 {
+			errs = duplicateIdentity(student, row.Number, identities)
+		}
+		if len(errs) == 0 {
+			var err error
+			if store {
+				err = s.studentService.CreateStudent(student)
+			} else {
+				err = s.studentService.CheckStudent(student)
+			}
+			if err != nil {
+				fieldErrs, ok := StudentErrorFields(err)
+				if !ok {
+					s.finish(job, model.ImportFailed, fmt.Sprintf("row %d: %v", row.Number, err))
+					return
+				}
+				errs = validation.Localize(fieldErrs, lang)
+			}
+		}
+
+		if len(errs) > 0 {
+			job.Errors = append(job.Errors, importRowErrors(row.Number, errs)...)
+		} else {
+			job.ValidRows++
+			valid = append(valid, student)
+			validRows = append(validRows, row.Number)
+			if store {
+				job.ImportedRows++
+			}
+		}
+
+		if (i+1)%importProgressEvery == 0 {
+			s.save(job)
+		}
+	}
+
+	switch {
+	case job.DryRun:
+		s.finish(job, model.ImportCompleted, fmt.Sprintf("dry run: %d of %d rows are valid, nothing was imported", job.ValidRows, job.TotalRows))
+
+	case job.Mode == model.ImportSkipInvalid:
+		s.finish(job, model.ImportCompleted, fmt.Sprintf("%d of %d rows imported", job.ImportedRows, job.TotalRows))
+
+	case job.ValidRows < job.TotalRows:
+		s.finish(job, model.ImportFailed, fmt.Sprintf("%d rows are invalid, nothing was imported", job.TotalRows-job.ValidRows))
+
+	default:
+		if err := s.studentService.CreateStudents(valid); err != nil {
+			var bulkErr *BulkCreateError
+			if errors.As(err, &bulkErr) {
+				if fieldErrs, ok := StudentErrorFields(bulkErr.Err); ok {
+					job.Errors = append(job.Errors, importRowErrors(validRows[bulkErr.Index], validation.Localize(fieldErrs, lang))...)
+				}
+			}
+			s.finish(job, model.ImportFailed, "nothing was imported: "+err.Error())
+			return
+		}
+		job.ImportedRows = len(valid)
+		s.finish(job, model.ImportCompleted, fmt.Sprintf("%d rows imported", job.ImportedRows))
+	}
+}
+
+func (s *importService) finish(job *model.ImportJob, status string, message string) {
+	now := time.Now()
+	job.Status = status
+	job.Message = &message
+	job.FinishedAt = &now
+	s.save(job)
+}
+
+// save stores the progress of a job. Nobody waits for a running job, so a
+// failure can only be logged.
+func (s *importService) save(job *model.ImportJob) {
+	if err := s.importJobRepo.Save(job); err != nil {
+		log.Printf("Failed to save import job %d: %v\n", job.ID, err)
+	}
+}
+
+// parseImportRow fills a StudentRequest from a row and validates it like a
+// request body, with errors keyed by import column.
+func parseImportRow(row model.ImportRow, lang validation.Lang) (*model.Student, map[string]string) {
+	var (
+		req       model.StudentRequest
+		parent    model.ParentRequest
+		hasParent bool
+	)
+	errs := map[string]string{}
+
+	for name, value := range row.Values {
+		if value == "" {
+			continue
+		}
+		f := importFields[name]
+		target := reflect.ValueOf(&req).Elem()
+		if f.parent {
+			target = reflect.ValueOf(&parent).Elem()
+			hasParent = true
+		}
+		field := target.FieldByIndex(f.index)
+
+		if err := setImportValue(field, value); err != nil {
+			if !errors.Is(err, model.ErrInvalidDate) {
+				err = &json.UnmarshalTypeError{Value: "string", Type: field.Type(), Field: name}
+			}
+			validation.CollectBindingErrors(err, "", lang, errs)
+		}
+	}
+	if hasParent {
+		req.Parent = &parent
+	}
+
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		bindErrs := map[string]string{}
+		validation.CollectBindingErrors(err, "", lang, bindErrs)
+		for field, msg := range bindErrs {
+			field = strings.TrimPrefix(field, "parent.")
+			if _, ok := errs[field]; !ok {
+				errs[field] = msg
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	student := req.ToModel()
+	if identityErrs := validation.StudentIdentity(student); len(identityErrs) > 0 {
+		return nil, validation.Localize(identityErrs, lang)
+	}
+	return student, nil
+}
+
+// importEnumAliases are the Indonesian spellings accepted for enum values.
+var importEnumAliases = map[reflect.Type]map[string]string{
+	reflect.TypeOf(model.Gender("")): {
+		"L": string(model.Male), "LAKI_LAKI": string(model.Male),
+		"P": string(model.Female), "PEREMPUAN": string(model.Female),
+	},
+	reflect.TypeOf(model.Religion("")): {
+		"KRISTEN": string(model.Christian), "KATOLIK": string(model.Catholic),
+		"BUDHA": string(model.Buddha), "KHONGHUCU": string(model.Konghucu),
+	},
+}
+
+// setImportValue parses a cell into a request field. Enum values are matched
+// ignoring case, so "yatim piatu" is YATIM_PIATU; numbers may use a decimal
+// comma.
+func setImportValue(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := setImportValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Struct: // model.Date
+		date, err := model.ParseDate(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(date))
+
+	case reflect.String:
+		if field.Type() != reflect.TypeOf("") {
+			value = strings.ToUpper(importColumnName(value))
+			if alias, ok := importEnumAliases[field.Type()][value]; ok {
+				value = alias
+			}
+		}
+		field.SetString(value)
+
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+
+	case reflect.Bool:
+		switch strings.ToLower(value) {
+		case "1", "true", "ya", "y", "yes":
+			field.SetBool(true)
+		case "0", "false", "tidak", "t", "no":
+			field.SetBool(false)
+		default:
+			return strconv.ErrSyntax
+		}
+	}
+	return nil
+}
+
+// duplicateIdentity reports a NIK or NISN that an earlier row of the file
+// already has. seen maps identities to the row they were first seen on.
+func duplicateIdentity(student *model.Student, row int, seen map[string]int) map[string]string {
+	errs := map[string]string{}
+	for field, value := range map[string]*string{"nik": student.Nik, "nisn": student.Nisn} {
+		if value == nil || strings.TrimSpace(*value) == "" {
+			continue
+		}
+		key := field + ":" + strings.TrimSpace(*value)
+		if first, ok := seen[key]; ok {
+			errs[field] = fmt.Sprintf("same %s as row %d", strings.ToUpper(field), first)
+			continue
+		}
+		seen[key] = row
+	}
+	return errs
+}
+
+// importRowErrors lists the field errors of a row, sorted by field.
+func importRowErrors(row int, errs map[string]string) []model.ImportRowError {
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	rowErrs := make([]model.ImportRowError, len(fields))
+	for i, field := range fields {
+		rowErrs[i] = model.ImportRowError{Row: row, Field: field, Message: errs[field]}
+	}
+	return rowErrs
+}
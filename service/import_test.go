@@ -0,0 +1,277 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"project_sdu/model"
+	"project_sdu/validation"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	if err := validation.RegisterBindings(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestImportHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  []string
+		mapping map[string]string
+		want    []string
+		errs    []string
+	}{
+		{
+			name:   "headers match columns ignoring case, spaces and dashes",
+			header: []string{"Full Name", "tanggal-lahir", " NIK ", "Catatan"},
+			want:   []string{"full_name", "tanggal_lahir", "nik", ""},
+		},
+		{
+			name:    "mapping renames a header",
+			header:  []string{"Nama Lengkap", "Jenis Kelamin"},
+			mapping: map[string]string{"Nama Lengkap": "full_name", "Jenis Kelamin": "gender"},
+			want:    []string{"full_name", "gender"},
+		},
+		{
+			name:    "mapping takes precedence over a matching header",
+			header:  []string{"full_name", "nik"},
+			mapping: map[string]string{"full_name": "", "nik": "full_name"},
+			want:    []string{"", "full_name"},
+		},
+		{
+			name:    "mapping to an empty column ignores the header",
+			header:  []string{"full_name", "Catatan"},
+			mapping: map[string]string{"Catatan": ""},
+			want:    []string{"full_name", ""},
+		},
+		{
+			name:    "two headers filling the same column",
+			header:  []string{"Nama", "full_name"},
+			mapping: map[string]string{"Nama": "full_name"},
+			errs:    []string{"mapping.full_name"},
+		},
+		{
+			name:   "the same header twice",
+			header: []string{"full_name", "Full Name"},
+			errs:   []string{"mapping.Full Name"},
+		},
+		{
+			name:    "mapping to an unknown column",
+			header:  []string{"full_name", "Hobi"},
+			mapping: map[string]string{"Hobi": "hobby"},
+			errs:    []string{"mapping.Hobi"},
+		},
+		{
+			name:    "mapping a header the file does not have",
+			header:  []string{"full_name"},
+			mapping: map[string]string{"Nama Ayah": "father_name"},
+			errs:    []string{"mapping.Nama Ayah"},
+		},
+		{
+			name:   "no full_name column",
+			header: []string{"nik", "nisn"},
+			errs:   []string{"file"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, err := importHeader(tt.header, tt.mapping)
+			if tt.errs == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(columns, tt.want) {
+					t.Errorf("columns = %q, want %q", columns, tt.want)
+				}
+				return
+			}
+
+			var fieldErrs FieldErrors
+			if !errors.As(err, &fieldErrs) {
+				t.Fatalf("error = %v, want FieldErrors", err)
+			}
+			if len(fieldErrs) != len(tt.errs) {
+				t.Errorf("errors = %v, want fields %q", fieldErrs, tt.errs)
+			}
+			for _, field := range tt.errs {
+				if _, ok := fieldErrs[field]; !ok {
+					t.Errorf("no error for %s in %v", field, fieldErrs)
+				}
+			}
+		})
+	}
+}
+
+func TestSetImportValue(t *testing.T) {
+	tests := []struct {
+		column  string
+		value   string
+		want    interface{}
+		wantErr bool
+	}{
+		{column: "full_name", value: "budi santoso", want: "budi santoso"},
+		{column: "gender", value: "L", want: model.Male},
+		{column: "gender", value: "perempuan", want: model.Female},
+		{column: "gender", value: "laki-laki", want: model.Male},
+		{column: "gender", value: "female", want: model.Female},
+		{column: "agama", value: "Kristen", want: model.Christian},
+		{column: "agama", value: "islam", want: model.Islam},
+		{column: "keadaan_ortu", value: "yatim piatu", want: model.YatimPiatu},
+		{column: "tinggal_bersama", value: "Kakek-Nenek", want: model.KakekNenek},
+		{column: "latitude", value: "-8,5833", want: -8.5833},
+		{column: "longitude", value: "116.1167", want: 116.1167},
+		{column: "latitude", value: "delapan", wantErr: true},
+		{column: "anak_ke", value: "2", want: 2},
+		{column: "anak_ke", value: "2,5", wantErr: true},
+		{column: "is_accepted", value: "Ya", want: true},
+		{column: "is_accepted", value: "tidak", want: false},
+		{column: "is_accepted", value: "mungkin", wantErr: true},
+		{column: "tanggal_lahir", value: "17 Agustus 2012", want: model.NewDate(2012, time.August, 17)},
+		{column: "tanggal_lahir", value: "17-08-2012", want: model.NewDate(2012, time.August, 17)},
+		{column: "tanggal_lahir", value: "kemarin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.column+"="+tt.value, func(t *testing.T) {
+			var req model.StudentRequest
+			f, ok := importFields[tt.column]
+			if !ok || f.parent {
+				t.Fatalf("%s is not a student import column", tt.column)
+			}
+			field := reflect.ValueOf(&req).Elem().FieldByIndex(f.index)
+
+			err := setImportValue(field, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("no error, got %v", reflect.Indirect(field).Interface())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := reflect.Indirect(field).Interface()
+			if date, ok := got.(model.Date); ok {
+				if want := tt.want.(model.Date); !date.Equal(want.Time) {
+					t.Errorf("got %v, want %v", date, want)
+				}
+				return
+			}
+			if reflect.TypeOf(got).Kind() == reflect.String {
+				got = reflect.ValueOf(got).String()
+				tt.want = reflect.ValueOf(tt.want).String()
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImportRow(t *testing.T) {
+	// Every row has the required columns unless a test blanks them
+	base := map[string]string{"full_name": "Budi Santoso", "gender": "L", "agama": "Islam"}
+
+	tests := []struct {
+		name   string
+		values map[string]string
+		errs   []string
+		check  func(t *testing.T, student *model.Student)
+	}{
+		{
+			name: "valid row with aliases and a decimal comma",
+			values: map[string]string{
+				"agama": "Kristen", "tanggal_lahir": "17 Mei 2012", "nik": "5203071705120001",
+				"latitude": "-8,5833", "father_name": "Santoso", "mother_nik": "",
+			},
+			check: func(t *testing.T, student *model.Student) {
+				if student.Gender != model.Male {
+					t.Errorf("gender = %s, want %s", student.Gender, model.Male)
+				}
+				if student.Agama == nil || *student.Agama != model.Christian {
+					t.Errorf("agama = %v, want %s", student.Agama, model.Christian)
+				}
+				if student.Latitude == nil || *student.Latitude != -8.5833 {
+					t.Errorf("latitude = %v", student.Latitude)
+				}
+				if student.Parent == nil || student.Parent.FatherName == nil || *student.Parent.FatherName != "Santoso" {
+					t.Errorf("parent = %+v", student.Parent)
+				}
+			},
+		},
+		{
+			name:   "missing full name",
+			values: map[string]string{"full_name": ""},
+			errs:   []string{"full_name"},
+		},
+		{
+			name:   "unknown enum value and a number that is not one",
+			values: map[string]string{"gender": "X", "berat_kg": "tiga puluh"},
+			errs:   []string{"gender", "berat_kg"},
+		},
+		{
+			name:   "invalid date",
+			values: map[string]string{"tanggal_lahir": "31-02-2012"},
+			errs:   []string{"tanggal_lahir"},
+		},
+		{
+			name:   "parent errors are keyed by the import column",
+			values: map[string]string{"father_nik": "123"},
+			errs:   []string{"father_nik"},
+		},
+		{
+			name:   "NIK not matching the birth date",
+			values: map[string]string{"tanggal_lahir": "2012-05-18", "nik": "5203071705120001"},
+			errs:   []string{"nik"},
+		},
+		{
+			name:   "no parent columns means no parent",
+			values: map[string]string{},
+			check: func(t *testing.T, student *model.Student) {
+				if student.Parent != nil {
+					t.Errorf("parent = %+v, want nil", student.Parent)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := map[string]string{}
+			for column, value := range base {
+				values[column] = value
+			}
+			for column, value := range tt.values {
+				values[column] = value
+			}
+
+			student, errs := parseImportRow(model.ImportRow{Number: 2, Values: values}, validation.LangEN)
+			if len(tt.errs) == 0 {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				tt.check(t, student)
+				return
+			}
+
+			if student != nil {
+				t.Errorf("student = %+v, want nil", student)
+			}
+			if len(errs) != len(tt.errs) {
+				t.Errorf("errors = %v, want fields %q", errs, tt.errs)
+			}
+			for _, field := range tt.errs {
+				if msg, ok := errs[field]; !ok || strings.TrimSpace(msg) == "" {
+					t.Errorf("no error for %s in %v", field, errs)
+				}
+			}
+		})
+	}
+}
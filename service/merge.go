@@ -0,0 +1,61 @@
+package service
+
+import (
+	"project_sdu/model"
+	"reflect"
+	"time"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	dateType = reflect.TypeOf(model.Date{})
+)
+
+// fillBlanks copies every value field that is set on src but empty on dst and
+// reports how many were copied. dst and src must point to the same struct
+// type. Associations (nested models and slices) are never touched.
+func fillBlanks(dst, src interface{}) int {
+	d := reflect.ValueOf(dst).Elem()
+	sv := reflect.ValueOf(src).Elem()
+
+	filled := 0
+	for i := 0; i < d.NumField(); i++ {
+		field := d.Type().Field(i)
+		if !field.IsExported() || !isValueField(field.Type) {
+			continue
+		}
+
+		dv, v := d.Field(i), sv.Field(i)
+		if dv.IsZero() && !v.IsZero() {
+			dv.Set(v)
+			filled++
+		}
+	}
+	return filled
+}
+
+// countFilled reports how many value fields of a struct pointer are set.
+func countFilled(value interface{}) int {
+	v := reflect.ValueOf(value).Elem()
+
+	count := 0
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.IsExported() && isValueField(field.Type) && !v.Field(i).IsZero() {
+			count++
+		}
+	}
+	return count
+}
+
+func isValueField(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem := t.Elem()
+		return elem.Kind() != reflect.Struct || elem == timeType || elem == dateType
+	case reflect.Slice, reflect.Map, reflect.Struct:
+		return t == timeType
+	default:
+		return true
+	}
+}
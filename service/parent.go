@@ -1,40 +1,56 @@
 package service
 
 import (
-	// "errors"
+	"errors"
 	"project_sdu/model"
 	"project_sdu/repository"
+	"strings"
+)
+
+var (
+	ErrMergeSameParent   = errors.New("target parent cannot be one of the sources")
+	ErrMergeNoSources    = errors.New("at least one source parent is required")
+	ErrParentHasStudents = errors.New("parent cannot be deleted because it has associated students")
 )
 
 type ParentService interface {
 	CreateParent(parent *model.Parent) error
-	GetAllParents(limit int, offset int) ([]model.Parent, error)
+	GetAllParents(page model.PageRequest) ([]model.Parent, *model.PageMeta, error)
 	GetParentByID(id int) (*model.Parent, error)
 	UpdateParent(id int, parent *model.Parent) error
+	PatchParent(id int, version int, patch Patch) (*model.Parent, error)
 	DeleteParent(id int) error
+	PreviewDeleteParent(id int) (*model.DeleteImpact, error)
+	GetTrashedParents(page model.PageRequest) ([]model.Parent, *model.PageMeta, error)
+	RestoreParent(id int) (*model.Parent, error)
+	PurgeParent(id int) error
+	FindDuplicates() ([]model.ParentDuplicateGroup, error)
+	MergeParents(targetID int, sourceIDs []int) (*model.Parent, error)
 }
 
 type parentService struct {
 	parentRepo repository.ParentRepository
+	uow        repository.UnitOfWork
 }
 
-func NewParentService(parentRepo repository.ParentRepository) ParentService {
-	return &parentService{parentRepo}
+func NewParentService(parentRepo repository.ParentRepository, uow repository.UnitOfWork) ParentService {
+	return &parentService{parentRepo, uow}
 }
 
 func (s *parentService) CreateParent(parent *model.Parent) error {
+	normalizeParentContacts(parent)
 	if err := s.parentRepo.Create(parent); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (s *parentService) GetAllParents(limit int, offset int) ([]model.Parent, error) {
-	parents, err := s.parentRepo.GetAll(limit, offset)
+func (s *parentService) GetAllParents(page model.PageRequest) ([]model.Parent, *model.PageMeta, error) {
+	parents, meta, err := s.parentRepo.GetAll(page)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return parents, nil
+	return parents, meta, nil
 }
 
 func (s *parentService) GetParentByID(id int) (*model.Parent, error) {
@@ -46,19 +62,205 @@ func (s *parentService) GetParentByID(id int) (*model.Parent, error) {
 }
 
 func (s *parentService) UpdateParent(id int, parent *model.Parent) error {
-	_, err := s.parentRepo.GetByID(id)
+	existing, err := s.parentRepo.GetByID(id)
 	if err != nil {
 		return err
 	}
+	keepUnmasked(&parent.NoHpOrtuWali, existing.NoHpOrtuWali, model.MaskPhone)
+	normalizeParentContacts(parent)
 
 	return s.parentRepo.Update(id, parent)
 }
 
+func (s *parentService) PatchParent(id int, version int, patch Patch) (*model.Parent, error) {
+	existing, err := s.parentRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req := model.NewParentRequest(existing)
+	if err := applyPatch(&req, patch, model.ParentPatchFields); err != nil {
+		return nil, err
+	}
+
+	parent := req.ToModel()
+	keepUnmasked(&parent.NoHpOrtuWali, existing.NoHpOrtuWali, model.MaskPhone)
+	normalizeParentContacts(parent)
+
+	if err := s.parentRepo.Patch(id, version, patchedColumns(parent, patch.Fields())); err != nil {
+		return nil, err
+	}
+	return s.parentRepo.GetByID(id)
+}
+
 func (s *parentService) DeleteParent(id int) error {
 	_, err := s.parentRepo.GetByID(id)
 	if err != nil {
 		return err
 	}
 
+	count, err := s.parentRepo.CountStudents(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrParentHasStudents
+	}
+
 	return s.parentRepo.Delete(id)
 }
+
+// PreviewDeleteParent reports whether the parent can be deleted: parents
+// with students go to the trash along with their last student instead.
+func (s *parentService) PreviewDeleteParent(id int) (*model.DeleteImpact, error) {
+	parent, err := s.parentRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	impact := model.NewDeleteImpact()
+	if len(parent.Students) > 0 {
+		impact.Allowed = false
+		impact.Reason = ErrParentHasStudents.Error()
+		impact.BlockingStudents = model.NewStudentSummaries(parent.Students)
+	}
+	return impact, nil
+}
+
+func (s *parentService) GetTrashedParents(page model.PageRequest) ([]model.Parent, *model.PageMeta, error) {
+	return s.parentRepo.GetTrash(page)
+}
+
+func (s *parentService) RestoreParent(id int) (*model.Parent, error) {
+	if err := s.parentRepo.Restore(id); err != nil {
+		return nil, err
+	}
+	return s.parentRepo.GetByID(id)
+}
+
+func (s *parentService) PurgeParent(id int) error {
+	return s.parentRepo.Purge(id)
+}
+
+func (s *parentService) FindDuplicates() ([]model.ParentDuplicateGroup, error) {
+	return s.parentRepo.FindDuplicateGroups()
+}
+
+// MergeParents folds duplicate parents into the target: blank fields on the
+// target are filled from the sources, their students move to the target and
+// the sources are deleted.
+func (s *parentService) MergeParents(targetID int, sourceIDs []int) (*model.Parent, error) {
+	sourceIDs = uniqueIDs(sourceIDs)
+	if len(sourceIDs) == 0 {
+		return nil, ErrMergeNoSources
+	}
+	for _, id := range sourceIDs {
+		if id == targetID {
+			return nil, ErrMergeSameParent
+		}
+	}
+
+	err := s.uow.Transaction(func(tx *repository.Tx) error {
+		parents := tx.Parents()
+
+		target, err := parents.GetByID(targetID)
+		if err != nil {
+			return err
+		}
+
+		sources, err := parents.GetByIDs(sourceIDs)
+		if err != nil {
+			return err
+		}
+		if len(sources) != len(sourceIDs) {
+			return errors.New("one or more source parents were not found")
+		}
+
+		target.Students = nil
+		for i := range sources {
+			fillBlanks(target, &sources[i])
+		}
+		if err := parents.Update(targetID, target); err != nil {
+			return err
+		}
+
+		if err := tx.Students().ReassignParent(sourceIDs, targetID); err != nil {
+			return err
+		}
+
+		return parents.DeleteMany(sourceIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.parentRepo.GetByID(targetID)
+}
+
+// findOrCreateParent reuses a parent already known by phone, email or NIK,
+// filling in details it was missing, and creates a new one otherwise.
+func findOrCreateParent(parents repository.ParentRepository, parent *model.Parent) (*model.Parent, error) {
+	normalizeParentContacts(parent)
+
+	existing, err := parents.FindMatch(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing == nil {
+		if err := parents.Create(parent); err != nil {
+			return nil, err
+		}
+		return parent, nil
+	}
+
+	if fillBlanks(existing, parent) > 0 {
+		if err := parents.Update(existing.ID, existing); err != nil {
+			return nil, err
+		}
+	}
+	return existing, nil
+}
+
+// normalizeParentContacts makes phone numbers, emails and NIKs comparable.
+func normalizeParentContacts(parent *model.Parent) {
+	if parent.NoHpOrtuWali != nil {
+		phone := NormalizePhone(*parent.NoHpOrtuWali)
+		parent.NoHpOrtuWali = &phone
+	}
+	if parent.ParentEmail != nil {
+		email := strings.ToLower(strings.TrimSpace(*parent.ParentEmail))
+		parent.ParentEmail = &email
+	}
+	for _, nik := range []**string{&parent.FatherNik, &parent.MotherNik, &parent.WaliNik} {
+		if *nik != nil {
+			trimmed := strings.TrimSpace(**nik)
+			*nik = &trimmed
+		}
+	}
+}
+
+// keepUnmasked puts the stored value back when the client sent it back still
+// masked, e.g. from a form filled with a list response. NIKs need no guard:
+// a masked NIK fails validation.
+func keepUnmasked(value **string, stored *string, mask func(*string) *string) {
+	if *value != nil && stored != nil && **value == *mask(stored) {
+		*value = stored
+	}
+}
+
+// NormalizePhone turns +62 812-3456-789 and 0812 3456 789 into 08123456789.
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+
+	digits := b.String()
+	if strings.HasPrefix(digits, "62") {
+		digits = "0" + digits[2:]
+	}
+	return digits
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"project_sdu/model"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Patch is a JSON merge patch body, keyed by JSON field name.
+type Patch map[string]json.RawMessage
+
+// Fields returns the patched JSON field names.
+func (p Patch) Fields() []string {
+	fields := make([]string, 0, len(p))
+	for field := range p {
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// Has reports whether any of the given fields is part of the patch.
+func (p Patch) Has(fields ...string) bool {
+	for _, field := range fields {
+		if _, ok := p[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPatch merges patch into req, the request DTO filled from the stored
+// row, and validates the result as a whole with its binding tags. Fields
+// outside allowed are rejected.
+func applyPatch(req interface{}, patch Patch, allowed model.PatchFields) error {
+	errs := FieldErrors{}
+	for field := range patch {
+		if !allowed[field] {
+			errs[field] = errors.New("field cannot be patched")
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	current, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(current, &doc); err != nil {
+		return err
+	}
+
+	for field, raw := range patch {
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		doc[field] = mergeValue(doc[field], value)
+	}
+
+	merged, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	// Decode into a zeroed DTO so removed fields do not keep their old value
+	target := reflect.ValueOf(req).Elem()
+	target.Set(reflect.Zero(target.Type()))
+	if err := json.Unmarshal(merged, req); err != nil {
+		return err
+	}
+
+	return binding.Validator.ValidateStruct(req)
+}
+
+// mergeValue applies one merge patch value: null removes, objects merge
+// recursively, anything else replaces.
+func mergeValue(current interface{}, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	currentObj, ok := current.(map[string]interface{})
+	if !ok {
+		currentObj = map[string]interface{}{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(currentObj, key)
+			continue
+		}
+		currentObj[key] = mergeValue(currentObj[key], value)
+	}
+	return currentObj
+}
+
+// patchedColumns picks the named JSON fields from the patched model, keyed by
+// Go field name for GORM's Updates(map). A map is used instead of a struct so
+// that nil, false and "" are written instead of skipped.
+func patchedColumns(m interface{}, fields []string) map[string]interface{} {
+	wanted := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		wanted[field] = true
+	}
+
+	columns := make(map[string]interface{}, len(fields))
+	v := reflect.ValueOf(m).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.SplitN(t.Field(i).Tag.Get("json"), ",", 2)[0]
+		if wanted[name] {
+			columns[t.Field(i).Name] = v.Field(i).Interface()
+		}
+	}
+	return columns
+}
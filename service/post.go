@@ -7,12 +7,16 @@ import (
 
 type PostService interface {
 	CreatePost(post *model.Post) error
-	GetAllPosts(limit int, page int, q string) ([]model.Post, error)
-	GetPublishedPosts(limit int, offset int) ([]model.Post, error)
+	GetAllPosts(page model.PageRequest, q string) ([]model.Post, *model.PageMeta, error)
+	GetPublishedPosts(page model.PageRequest) ([]model.Post, *model.PageMeta, error)
 	GetPostByID(id int) (*model.Post, error)
 	GetPostBySlug(slug string) (*model.Post, error)
 	UpdatePost(slug string, post *model.Post) error
+	PatchPost(slug string, version int, patch Patch) (*model.Post, error)
 	DeletePost(slug string) error
+	GetTrashedPosts(page model.PageRequest) ([]model.Post, *model.PageMeta, error)
+	RestorePost(slug string) (*model.Post, error)
+	PurgePost(slug string) error
 }
 
 type postService struct {
@@ -30,20 +34,20 @@ func (s *postService) CreatePost(post *model.Post) error {
 	return nil
 }
 
-func (s *postService) GetAllPosts(limit int, page int, q string) ([]model.Post, error) {
-	posts, err := s.postRepo.GetAll(limit, page, q)
+func (s *postService) GetAllPosts(page model.PageRequest, q string) ([]model.Post, *model.PageMeta, error) {
+	posts, meta, err := s.postRepo.GetAll(page, q)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return posts, nil
+	return posts, meta, nil
 }
 
-func (s *postService) GetPublishedPosts(limit int, offset int) ([]model.Post, error) {
-	posts, err := s.postRepo.GetPublished(limit, offset)
+func (s *postService) GetPublishedPosts(page model.PageRequest) ([]model.Post, *model.PageMeta, error) {
+	posts, meta, err := s.postRepo.GetPublished(page)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return posts, nil
+	return posts, meta, nil
 }
 
 func (s *postService) GetPostByID(id int) (*model.Post, error) {
@@ -71,6 +75,23 @@ func (s *postService) UpdatePost(slug string, post *model.Post) error {
 	return s.postRepo.Update(slug, post)
 }
 
+func (s *postService) PatchPost(slug string, version int, patch Patch) (*model.Post, error) {
+	post, err := s.postRepo.GetBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	req := model.NewPostRequest(post)
+	if err := applyPatch(&req, patch, model.PostPatchFields); err != nil {
+		return nil, err
+	}
+
+	if err := s.postRepo.Patch(slug, version, patchedColumns(req.ToModel(), patch.Fields())); err != nil {
+		return nil, err
+	}
+	return s.postRepo.GetBySlug(slug)
+}
+
 func (s *postService) DeletePost(slug string) error {
 	_, err := s.postRepo.GetBySlug(slug)
 	if err != nil {
@@ -79,3 +100,18 @@ func (s *postService) DeletePost(slug string) error {
 
 	return s.postRepo.Delete(slug)
 }
+
+func (s *postService) GetTrashedPosts(page model.PageRequest) ([]model.Post, *model.PageMeta, error) {
+	return s.postRepo.GetTrash(page)
+}
+
+func (s *postService) RestorePost(slug string) (*model.Post, error) {
+	if err := s.postRepo.Restore(slug); err != nil {
+		return nil, err
+	}
+	return s.postRepo.GetBySlug(slug)
+}
+
+func (s *postService) PurgePost(slug string) error {
+	return s.postRepo.Purge(slug)
+}
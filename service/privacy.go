@@ -0,0 +1,210 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io"
+	"project_sdu/model"
+	"project_sdu/repository"
+	"time"
+)
+
+var ErrStudentAnonymized = errors.New("student has already been anonymized")
+
+// PrivacyService answers data subject requests under UU PDP (Undang-Undang
+// Pelindungan Data Pribadi): access to everything held about an applicant,
+// and erasure.
+type PrivacyService interface {
+	ExportStudent(id int) (*model.SubjectAccessExport, error)
+	AnonymizeStudent(id int) (*model.Student, error)
+}
+
+type privacyService struct {
+	studentRepo   repository.StudentRepository
+	accessLogRepo repository.AccessLogRepository
+	uow           repository.UnitOfWork
+}
+
+func NewPrivacyService(studentRepo repository.StudentRepository, accessLogRepo repository.AccessLogRepository, uow repository.UnitOfWork) PrivacyService {
+	return &privacyService{studentRepo, accessLogRepo, uow}
+}
+
+// ExportStudent collects the student, its parent, document locations,
+// status history and the log of who viewed the unmasked record. Trashed
+// records are included, as they are still held.
+func (s *privacyService) ExportStudent(id int) (*model.SubjectAccessExport, error) {
+	student, err := s.studentRepo.GetIncludingTrashed(id)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.accessLogRepo.GetByResource("student", id)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &model.SubjectAccessExport{
+		GeneratedAt:   time.Now(),
+		Student:       model.NewUnmaskedStudentResponse(student),
+		Documents:     studentDocuments(student),
+		StatusHistory: studentStatusHistory(student),
+		AccessLog:     model.NewAccessLogResponses(entries),
+	}
+	// The parent is listed on its own rather than nested in the student
+	export.Parent, export.Student.Parent = export.Student.Parent, nil
+	return export, nil
+}
+
+func studentDocuments(student *model.Student) []model.ExportDocument {
+	documents := []model.ExportDocument{}
+	for _, doc := range []struct {
+		name     string
+		location *string
+	}{
+		{"photo", student.Photo},
+		{"kartu_keluarga", student.KartuKeluarga},
+		{"akta_kelahiran", student.AktaKelahiran},
+		{"ijazah_skl", student.IjazahSKL},
+	} {
+		if doc.location != nil && *doc.location != "" {
+			documents = append(documents, model.ExportDocument{Name: doc.name, Location: *doc.location})
+		}
+	}
+	return documents
+}
+
+func studentStatusHistory(student *model.Student) []model.StatusEvent {
+	createdAt := student.CreatedAt
+	history := []model.StatusEvent{{Status: "registered", At: &createdAt}}
+
+	if student.IsAccepted {
+		history = append(history, model.StatusEvent{Status: "accepted"})
+	}
+	if student.EnrolledAt != nil {
+		history = append(history, model.StatusEvent{Status: "enrolled", At: student.EnrolledAt})
+	}
+	if student.DeletedAt.Valid {
+		deletedAt := student.DeletedAt.Time
+		history = append(history, model.StatusEvent{Status: "deleted", At: &deletedAt})
+	}
+	if student.AnonymizedAt != nil {
+		history = append(history, model.StatusEvent{Status: "anonymized", At: student.AnonymizedAt})
+	}
+	return history
+}
+
+// WriteSubjectAccessZip writes the export as a ZIP with one JSON file per
+// section.
+func WriteSubjectAccessZip(w io.Writer, export *model.SubjectAccessExport) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"student.json", export.Student},
+		{"parent.json", export.Parent},
+		{"documents.json", export.Documents},
+		{"status_history.json", export.StatusHistory},
+		{"access_log.json", export.AccessLog},
+	}
+	for _, file := range files {
+		f, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.GeneratedAt,
+		})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// AnonymizeStudent irreversibly scrubs the personal fields of a student.
+// The row itself stays, with gender, batch, status, region down to the
+// kecamatan and distance, so dashboard counts and statistics do not change.
+// The parent is scrubbed as well when no other student belongs to it;
+// otherwise the student is only detached from it. The PPDB draft the student
+// was submitted from is deleted.
+func (s *privacyService) AnonymizeStudent(id int) (*model.Student, error) {
+	err := s.uow.Transaction(func(tx *repository.Tx) error {
+		student, err := tx.Students().GetIncludingTrashed(id)
+		if err != nil {
+			return err
+		}
+		if student.AnonymizedAt != nil {
+			return ErrStudentAnonymized
+		}
+
+		now := time.Now()
+		fields := anonymizedStudentFields(now)
+
+		if student.ParentId != nil {
+			others, err := tx.Students().CountByParentID(*student.ParentId, id)
+			if err != nil {
+				return err
+			}
+			if others == 0 {
+				if err := tx.Parents().Anonymize(*student.ParentId, anonymizedParentFields(now)); err != nil {
+					return err
+				}
+			} else {
+				fields["ParentId"] = nil
+			}
+		}
+
+		if err := tx.Drafts().DeleteByStudentID(id); err != nil {
+			return err
+		}
+		return tx.Students().Anonymize(id, fields)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.studentRepo.GetIncludingTrashed(id)
+}
+
+// AnonymizedFullName replaces the name of an anonymized student.
+const AnonymizedFullName = "Anonim"
+
+func anonymizedStudentFields(at time.Time) map[string]interface{} {
+	fields := map[string]interface{}{
+		"FullName":     AnonymizedFullName,
+		"AnonymizedAt": &at,
+	}
+	for _, name := range []string{
+		"Nisn", "Nik", "Nis", "AsalSekolah", "TempatLahir", "TanggalLahir", "Agama",
+		"KeadaanOrtu", "StatusKeluarga", "AnakKe", "DariBersaudara", "TinggalBersama",
+		"TinggalBersamaLainnya", "Kewarganegaraan", "AlamatJalan", "Rt", "Rw",
+		"DesaKelurahan", "DesaKelurahanKode", "KodePos", "Latitude", "Longitude",
+		"Phone", "Email", "Photo", "KartuKeluarga", "AktaKelahiran", "IjazahSKL",
+		"AgeOverrideReason", "BloodType", "BeratKg", "TinggiCm", "RiwayatPenyakit",
+	} {
+		fields[name] = nil
+	}
+	return fields
+}
+
+func anonymizedParentFields(at time.Time) map[string]interface{} {
+	fields := map[string]interface{}{
+		"AnonymizedAt": &at,
+	}
+	for _, name := range []string{
+		"FatherName", "FatherEducation", "FatherJob", "FatherIncome",
+		"MotherName", "MotherEducation", "MotherJob", "MotherIncome",
+		"ParentEmail", "WaliName", "AlamatOrtuWali", "NoHpOrtuWali",
+		"FatherNik", "MotherNik", "WaliNik",
+	} {
+		fields[name] = nil
+	}
+	return fields
+}
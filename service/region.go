@@ -0,0 +1,146 @@
+package service
+
+import (
+	"errors"
+	"project_sdu/model"
+	"project_sdu/repository"
+	"project_sdu/validation"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type RegionService interface {
+	GetProvinces(q string) ([]model.Region, error)
+	GetChildren(code string, q string) ([]model.Region, error)
+	GetByCode(code string) (*model.Region, error)
+	Load(regions []model.Region) error
+}
+
+type regionService struct {
+	regionRepo repository.RegionRepository
+}
+
+func NewRegionService(regionRepo repository.RegionRepository) RegionService {
+	return &regionService{regionRepo}
+}
+
+func (s *regionService) GetProvinces(q string) ([]model.Region, error) {
+	return s.regionRepo.GetByLevel(model.LevelProvinsi, q)
+}
+
+func (s *regionService) GetChildren(code string, q string) ([]model.Region, error) {
+	return s.regionRepo.GetChildren(code, q)
+}
+
+func (s *regionService) GetByCode(code string) (*model.Region, error) {
+	return s.regionRepo.GetByCode(code)
+}
+
+func (s *regionService) Load(regions []model.Region) error {
+	return s.regionRepo.Upsert(regions)
+}
+
+// studentRegionField ties a region level to the code and name columns of a student.
+type studentRegionField struct {
+	level model.RegionLevel
+	field string
+	code  func(*model.Student) **string
+	name  func(*model.Student) **string
+}
+
+var studentRegionFields = []studentRegionField{
+	{model.LevelProvinsi, "provinsi_kode",
+		func(s *model.Student) **string { return &s.ProvinsiKode },
+		func(s *model.Student) **string { return &s.Provinsi }},
+	{model.LevelKabupaten, "kabupaten_kode",
+		func(s *model.Student) **string { return &s.KabupatenKode },
+		func(s *model.Student) **string { return &s.Kabupaten }},
+	{model.LevelKecamatan, "kecamatan_kode",
+		func(s *model.Student) **string { return &s.KecamatanKode },
+		func(s *model.Student) **string { return &s.Kecamatan }},
+	{model.LevelDesa, "desa_kelurahan_kode",
+		func(s *model.Student) **string { return &s.DesaKelurahanKode },
+		func(s *model.Student) **string { return &s.DesaKelurahan }},
+}
+
+// resolveStudentRegions validates the region codes given on a student against
+// the region master and fills in ancestor codes and the region names. When
+// existing is set (update), lower-level codes that no longer fall inside the
+// new region are cleared. Students without any region code are left alone so
+// free-form addresses keep working.
+func resolveStudentRegions(regionRepo repository.RegionRepository, student *model.Student, existing *model.Student) error {
+	errs := FieldErrors{}
+	deepest := -1
+	var deepestRegion *model.Region
+
+	for i, f := range studentRegionFields {
+		code := *f.code(student)
+		if code == nil || strings.TrimSpace(*code) == "" {
+			continue
+		}
+
+		region, err := regionRepo.GetByCode(strings.TrimSpace(*code))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errs[f.field] = validation.NewFieldError(validation.MsgRegionNotFound, *code)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if region.Level != f.level {
+			errs[f.field] = validation.NewFieldError(validation.MsgRegionLevel, region.Code, strings.ToLower(string(f.level)))
+			continue
+		}
+		if deepestRegion != nil && !strings.HasPrefix(region.Code, deepestRegion.Code+".") {
+			errs[f.field] = validation.NewFieldError(validation.MsgRegionOutside, region.Name, deepestRegion.Name)
+			continue
+		}
+
+		deepest = i
+		deepestRegion = region
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	if deepestRegion == nil {
+		return nil
+	}
+
+	// Walk up from the deepest region so codes and names always agree
+	region := deepestRegion
+	for i := deepest; i >= 0; i-- {
+		f := studentRegionFields[i]
+		code, name := region.Code, region.Name
+		*f.code(student) = &code
+		*f.name(student) = &name
+
+		if region.Level == model.LevelDesa && region.PostalCode != nil && (student.KodePos == nil || *student.KodePos == "") {
+			postalCode := *region.PostalCode
+			student.KodePos = &postalCode
+		}
+
+		if i == 0 || region.ParentCode == nil {
+			break
+		}
+		parent, err := regionRepo.GetByCode(*region.ParentCode)
+		if err != nil {
+			return err
+		}
+		region = parent
+	}
+
+	if existing != nil {
+		for _, f := range studentRegionFields[deepest+1:] {
+			code := *f.code(existing)
+			if code != nil && *code != "" && !strings.HasPrefix(*code, deepestRegion.Code+".") {
+				empty, emptyName := "", ""
+				*f.code(student) = &empty
+				*f.name(student) = &emptyName
+			}
+		}
+	}
+
+	return nil
+}
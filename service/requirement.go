@@ -9,6 +9,7 @@ type RequirementService interface {
 	Create(requirement *model.Requirement) error
 	GetAll() ([]model.Requirement, error)
 	Update(id int, requirement *model.Requirement) error
+	Patch(id int, patch Patch) (*model.Requirement, error)
 	Delete(id int) error
 	GetByID(id int) (*model.Requirement, error)
 }
@@ -33,6 +34,23 @@ func (s *requirementService) Update(id int, requirement *model.Requirement) erro
 	return s.requirementRepo.Update(id, requirement)
 }
 
+func (s *requirementService) Patch(id int, patch Patch) (*model.Requirement, error) {
+	requirement, err := s.requirementRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req := model.NewRequirementRequest(requirement)
+	if err := applyPatch(&req, patch, model.RequirementPatchFields); err != nil {
+		return nil, err
+	}
+
+	if err := s.requirementRepo.Patch(id, patchedColumns(req.ToModel(), patch.Fields())); err != nil {
+		return nil, err
+	}
+	return s.requirementRepo.GetByID(id)
+}
+
 func (s *requirementService) Delete(id int) error {
 	return s.requirementRepo.Delete(id)
 }
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"project_sdu/model"
+	"project_sdu/repository"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRetentionRules removes the documents and health data of rejected
+// applicants six months after their batch ended.
+const DefaultRetentionRules = "rejected:documents:6,rejected:health:6"
+
+// retentionFields are the student fields each kind of retention data covers.
+// Personal data is removed by anonymization instead.
+var retentionFields = map[string][]string{
+	model.RetentionDocuments: {"Photo", "KartuKeluarga", "AktaKelahiran", "IjazahSKL"},
+	model.RetentionHealth:    {"BloodType", "BeratKg", "TinggiCm", "RiwayatPenyakit"},
+}
+
+// retentionDraftField is listed when the PPDB draft the student was
+// submitted from still exists. Drafts hold a copy of the whole form, so they
+// go with any retention data.
+const retentionDraftField = "PPDBDraft"
+
+type RetentionService interface {
+	Preview(at time.Time) (*model.RetentionPreview, error)
+	Apply() (*model.RetentionRunResult, error)
+	GetLog(page model.PageRequest) ([]model.RetentionPurgeLog, *model.PageMeta, error)
+}
+
+type retentionService struct {
+	studentRepo      repository.StudentRepository
+	draftRepo        repository.DraftRepository
+	retentionLogRepo repository.RetentionLogRepository
+	privacyService   PrivacyService
+	uow              repository.UnitOfWork
+}
+
+func NewRetentionService(studentRepo repository.StudentRepository, draftRepo repository.DraftRepository, retentionLogRepo repository.RetentionLogRepository, privacyService PrivacyService, uow repository.UnitOfWork) RetentionService {
+	return &retentionService{studentRepo, draftRepo, retentionLogRepo, privacyService, uow}
+}
+
+// RetentionRules reads RETENTION_RULES, a comma-separated list of
+// status:data:months, e.g. rejected:documents:6.
+func RetentionRules() ([]model.RetentionRule, error) {
+	config := os.Getenv("RETENTION_RULES")
+	if strings.TrimSpace(config) == "" {
+		config = DefaultRetentionRules
+	}
+
+	var rules []model.RetentionRule
+	for _, entry := range strings.Split(config, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("RETENTION_RULES: expected status:data:months, got %q", entry)
+		}
+
+		rule := model.RetentionRule{Status: parts[0], Data: parts[1]}
+		switch rule.Status {
+		case model.RetentionRejected, model.RetentionAccepted, model.RetentionEnrolled:
+		default:
+			return nil, fmt.Errorf("RETENTION_RULES: unknown status %q", rule.Status)
+		}
+		switch rule.Data {
+		case model.RetentionDocuments, model.RetentionHealth, model.RetentionPersonal:
+		default:
+			return nil, fmt.Errorf("RETENTION_RULES: unknown data %q", rule.Data)
+		}
+
+		months, err := strconv.Atoi(parts[2])
+		if err != nil || months < 0 {
+			return nil, fmt.Errorf("RETENTION_RULES: invalid months %q", parts[2])
+		}
+		rule.Months = months
+
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Preview lists what a run at the given time would purge.
+func (s *retentionService) Preview(at time.Time) (*model.RetentionPreview, error) {
+	rules, err := RetentionRules()
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &model.RetentionPreview{At: at, Rules: rules, Candidates: []model.RetentionCandidate{}}
+	for _, rule := range rules {
+		students, err := s.studentRepo.GetRetentionCandidates(rule, at.AddDate(0, -rule.Months, 0))
+		if err != nil {
+			return nil, err
+		}
+		if len(students) == 0 {
+			continue
+		}
+
+		ids := make([]int, 0, len(students))
+		for _, student := range students {
+			ids = append(ids, student.ID)
+		}
+		withDraft, err := s.draftRepo.GetStudentIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		hasDraft := make(map[int]bool, len(withDraft))
+		for _, id := range withDraft {
+			hasDraft[id] = true
+		}
+
+		for i := range students {
+			candidate := retentionCandidate(&students[i], rule)
+			if hasDraft[students[i].ID] && rule.Data != model.RetentionPersonal {
+				candidate.Fields = append(candidate.Fields, retentionDraftField)
+			}
+			preview.Candidates = append(preview.Candidates, candidate)
+		}
+	}
+	return preview, nil
+}
+
+func retentionCandidate(student *model.Student, rule model.RetentionRule) model.RetentionCandidate {
+	candidate := model.RetentionCandidate{
+		StudentID: student.ID,
+		FullName:  student.FullName,
+		BatchID:   student.BatchId,
+		Status:    rule.Status,
+		Data:      rule.Data,
+		Fields:    heldFields(student, rule.Data),
+	}
+	if student.Batch != nil {
+		candidate.BatchName = student.Batch.Name
+		candidate.BatchEndDate = student.Batch.EndDate
+		if student.Batch.EndDate != nil {
+			candidate.DueAt = student.Batch.EndDate.AddDate(0, rule.Months, 0)
+		}
+	}
+	return candidate
+}
+
+// heldFields lists the fields of the retention data the student still has.
+func heldFields(student *model.Student, data string) []string {
+	if data == model.RetentionPersonal {
+		return []string{model.RetentionPersonal}
+	}
+
+	var held []string
+	v := reflect.ValueOf(student).Elem()
+	for _, name := range retentionFields[data] {
+		if !v.FieldByName(name).IsNil() {
+			held = append(held, name)
+		}
+	}
+	return held
+}
+
+// Apply purges everything the rules say is due and logs each purge. A
+// student that fails is reported and the rest carry on.
+func (s *retentionService) Apply() (*model.RetentionRunResult, error) {
+	preview, err := s.Preview(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.RetentionRunResult{Failed: map[int]string{}}
+	for _, candidate := range preview.Candidates {
+		if _, failed := result.Failed[candidate.StudentID]; failed {
+			continue
+		}
+		if err := s.purge(candidate); err != nil {
+			result.Failed[candidate.StudentID] = err.Error()
+			continue
+		}
+		result.Purged++
+	}
+	return result, nil
+}
+
+func (s *retentionService) purge(candidate model.RetentionCandidate) error {
+	entry := &model.RetentionPurgeLog{
+		StudentID: candidate.StudentID,
+		BatchID:   candidate.BatchID,
+		Status:    candidate.Status,
+		Data:      candidate.Data,
+		Fields:    candidate.Fields,
+	}
+
+	if candidate.Data == model.RetentionPersonal {
+		if _, err := s.privacyService.AnonymizeStudent(candidate.StudentID); err != nil {
+			return err
+		}
+		return s.retentionLogRepo.Create(entry)
+	}
+
+	return s.uow.Transaction(func(tx *repository.Tx) error {
+		if err := tx.Students().ClearFields(candidate.StudentID, retentionFields[candidate.Data]); err != nil {
+			return err
+		}
+		if err := tx.Drafts().DeleteByStudentID(candidate.StudentID); err != nil {
+			return err
+		}
+		return tx.RetentionLogs().Create(entry)
+	})
+}
+
+func (s *retentionService) GetLog(page model.PageRequest) ([]model.RetentionPurgeLog, *model.PageMeta, error) {
+	return s.retentionLogRepo.GetAll(page)
+}
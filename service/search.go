@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"project_sdu/model"
+	"project_sdu/repository"
+	"strings"
+)
+
+// SearchLimit is how many hits of each kind the admin search returns by
+// default; MaxSearchLimit caps it.
+const (
+	SearchLimit    = 5
+	MaxSearchLimit = 20
+)
+
+type SearchService interface {
+	Search(q string, limit int) (*model.SearchResult, error)
+}
+
+type searchService struct {
+	searchRepo repository.SearchRepository
+}
+
+func NewSearchService(searchRepo repository.SearchRepository) SearchService {
+	return &searchService{searchRepo}
+}
+
+// Search looks for q in students, parents and posts at once, best matches
+// first.
+func (s *searchService) Search(q string, limit int) (*model.SearchResult, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return nil, FieldErrors{"q": errors.New("q is required")}
+	}
+	if limit < 1 {
+		limit = SearchLimit
+	}
+	limit = min(limit, MaxSearchLimit)
+
+	result := &model.SearchResult{Query: q}
+
+	var err error
+	if result.Students, err = s.searchRepo.SearchStudents(q, limit); err != nil {
+		return nil, err
+	}
+	if result.Parents, err = s.searchRepo.SearchParents(q, limit); err != nil {
+		return nil, err
+	}
+	if result.Posts, err = s.searchRepo.SearchPosts(q, limit); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -2,81 +2,268 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"os"
 	"project_sdu/model"
 	"project_sdu/repository"
+	"project_sdu/validation"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// DefaultNISPattern yields e.g. 2526001 for the third pupil of 2025/2026.
+const DefaultNISPattern = "{YY}{NEXTYY}{SEQ:3}"
+
+var (
+	ErrStudentNotAccepted   = validation.NewFieldError(validation.MsgStudentNotAccepted)
+	ErrStudentEnrolled      = validation.NewFieldError(validation.MsgStudentEnrolled)
+	ErrInvalidTahunMasuk    = validation.NewFieldError(validation.MsgInvalidTahunMasuk)
+	ErrTanggalLahirRequired = validation.NewFieldError(validation.MsgTanggalLahirRequired)
+	ErrMergeTooFewStudents  = errors.New("at least two students are required to merge")
+	ErrMergeKeepNotListed   = errors.New("keep_id must be one of student_ids")
+	ErrStudentBatchTrashed  = errors.New("the student's batch is in the trash, restore the batch first")
+	tahunMasukPattern       = regexp.MustCompile(`^(\d{4})/(\d{4})$`)
+	nisSequenceTokenPattern = regexp.MustCompile(`\{SEQ(?::(\d+))?\}`)
+)
+
+// FieldErrors is a validation failure keyed by JSON field name. The API
+// turns the errors into messages with validation.Localize.
+type FieldErrors map[string]error
+
+func (e FieldErrors) Error() string {
+	parts := make([]string, 0, len(e))
+	for field, err := range e {
+		parts = append(parts, field+": "+err.Error())
+	}
+	return strings.Join(parts, "; ")
+}
+
 type StudentService interface {
 	CreateStudent(student *model.Student) error
+	CheckStudent(student *model.Student) error
+	CreateStudents(students []*model.Student) error
 	RegisterPPDB(student *model.Student) error
 	GetStudentByID(id int) (*model.Student, error)
-	GetAllStudents(limit int, page int, q string, batchID *int, isAccepted *bool) ([]model.Student, error)
+	GetAllStudents(page model.PageRequest, filter model.StudentFilter) ([]model.Student, *model.PageMeta, error)
 	UpdateStudent(id int, student *model.Student) error
-	DeleteStudent(id int) error
+	PatchStudent(id int, version int, patch Patch) (*model.Student, error)
+	DeleteStudent(id int) (*model.DeleteImpact, error)
+	PreviewDeleteStudent(id int) (*model.DeleteImpact, error)
+	GetTrashedStudents(page model.PageRequest) ([]model.Student, *model.PageMeta, error)
+	RestoreStudent(id int) (*model.Student, error)
+	PurgeStudent(id int) error
+	EnrollStudents(ids []int, tahunMasuk string) (*EnrollResult, error)
+	GetEnrolledStudents(page model.PageRequest, q string, tahunMasuk string) ([]model.Student, *model.PageMeta, error)
+	GetAgeOverrides(batchID *int) ([]model.AgeOverrideReport, error)
+	RankByDistance(batchID int) ([]model.ZonasiRank, error)
+	FindDuplicateStudents(batchID int, threshold float64) ([]model.DuplicateCandidate, error)
+	MergeStudents(ids []int, keepID *int) (*model.Student, error)
+}
+
+// EnrollResult lists the students EnrollStudents enrolled and, by ID, why
+// the others were skipped.
+type EnrollResult struct {
+	Enrolled []model.StudentResponse
+	Failed   map[int]error
+}
+
+// AgeLimitError is returned when an applicant is older than the batch allows.
+type AgeLimitError struct {
+	Age           int
+	MaxAge        int
+	ReferenceDate time.Time
+}
+
+func (e *AgeLimitError) Error() string {
+	return e.Format(validation.LangID)
+}
+
+// Format returns the message in the given language.
+func (e *AgeLimitError) Format(lang validation.Lang) string {
+	return validation.NewFieldError(validation.MsgAgeLimit, e.Age, e.MaxAge, e.ReferenceDate.Format("02-01-2006")).Format(lang)
+}
+
+// StudentErrorFields describes an error of CreateStudent or CheckStudent as
+// field errors. It returns false for errors not caused by the submitted data.
+func StudentErrorFields(err error) (FieldErrors, bool) {
+	var (
+		fieldErrs FieldErrors
+		ageErr    *AgeLimitError
+	)
+	switch {
+	case errors.As(err, &fieldErrs):
+		return fieldErrs, true
+	case errors.Is(err, repository.ErrNIKExists):
+		return FieldErrors{"nik": repository.ErrNIKExists}, true
+	case errors.Is(err, repository.ErrNISNExists):
+		return FieldErrors{"nisn": repository.ErrNISNExists}, true
+	case errors.Is(err, ErrTanggalLahirRequired):
+		return FieldErrors{"tanggal_lahir": ErrTanggalLahirRequired}, true
+	case errors.As(err, &ageErr):
+		return FieldErrors{"tanggal_lahir": ageErr}, true
+	}
+	return nil, false
 }
 
 type studentService struct {
 	studentRepo repository.StudentRepository
 	parentRepo  repository.ParentRepository
 	batchRepo   repository.BatchRepository
+	regionRepo  repository.RegionRepository
+	uow         repository.UnitOfWork
 }
 
-func NewStudentService(studentRepo repository.StudentRepository, parentRepo repository.ParentRepository, batchRepo repository.BatchRepository) StudentService {
+func NewStudentService(studentRepo repository.StudentRepository, parentRepo repository.ParentRepository, batchRepo repository.BatchRepository, regionRepo repository.RegionRepository, uow repository.UnitOfWork) StudentService {
 	return &studentService{
 		studentRepo: studentRepo,
 		parentRepo:  parentRepo,
 		batchRepo:   batchRepo,
+		regionRepo:  regionRepo,
+		uow:         uow,
 	}
 }
 
 func (s *studentService) CreateStudent(student *model.Student) error {
-	var parentCreated bool
-	if student.Parent != nil {
-		if err := s.parentRepo.Create(student.Parent); err != nil {
-			return err
+	if err := s.prepareStudent(student); err != nil {
+		return err
+	}
+	return s.createWithParent(student, true)
+}
+
+// CheckStudent runs every check CreateStudent does without storing
+// anything, including whether the NIK or NISN is taken.
+func (s *studentService) CheckStudent(student *model.Student) error {
+	if err := s.prepareStudent(student); err != nil {
+		return err
+	}
+	return s.studentRepo.CheckIdentityTaken(student)
+}
+
+// BulkCreateError names the student a bulk create failed on.
+type BulkCreateError struct {
+	Index int
+	Err   error
+}
+
+func (e *BulkCreateError) Error() string {
+	return fmt.Sprintf("student %d: %v", e.Index, e.Err)
+}
+
+func (e *BulkCreateError) Unwrap() error {
+	return e.Err
+}
+
+// CreateStudents stores all students in one transaction, so either all of
+// them are stored or, on a *BulkCreateError, none.
+func (s *studentService) CreateStudents(students []*model.Student) error {
+	for i, student := range students {
+		if err := s.prepareStudent(student); err != nil {
+			return &BulkCreateError{Index: i, Err: err}
 		}
-		parentCreated = true
-		student.ParentId = &student.Parent.ID
+	}
+
+	return s.uow.Transaction(func(tx *repository.Tx) error {
+		for i, student := range students {
+			if err := createInTx(tx, student, true); err != nil {
+				return &BulkCreateError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// prepareStudent fills in regions, distance and batch of an applicant
+// entered by an admin, and checks the batch age limit.
+func (s *studentService) prepareStudent(student *model.Student) error {
+	if err := resolveStudentRegions(s.regionRepo, student, nil); err != nil {
+		return err
+	}
+	if err := applyDistance(student, nil); err != nil {
+		return err
 	}
 
 	// If BatchId is not provided, try to find active batch (Admin convenience, or default behavior)
+	var batch *model.Batch
 	if student.BatchId == nil || *student.BatchId == 0 {
 		activeBatch, err := s.batchRepo.GetActiveBatch()
 		if err == nil {
 			student.BatchId = &activeBatch.ID
+			batch = activeBatch
 		}
-		// If no active batch and no ID provided, we permit for Admin (it will be null)? 
+		// If no active batch and no ID provided, we permit for Admin (it will be null)?
 		// Or maybe we just proceed.
+	} else {
+		batch, _ = s.batchRepo.GetByID(*student.BatchId)
 	}
 	student.Batch = nil
 
-	if err := s.studentRepo.Create(student); err != nil {
-		if parentCreated && student.Parent != nil {
-			_ = s.parentRepo.Delete(student.Parent.ID)
-		}
+	if batch != nil {
+		return enforceAgeLimit(student, batch)
+	}
+	return nil
+}
+
+// enforceAgeLimit checks the batch age limit of an applicant entered by an
+// admin. Admins may register an applicant above the limit or without a birth
+// date, but only explicitly with AgeOverride; an applicant within the limit
+// loses an override that is no longer needed.
+func enforceAgeLimit(student *model.Student, batch *model.Batch) error {
+	err := checkAgeEligibility(student, batch)
+	var ageErr *AgeLimitError
+	switch {
+	case err == nil:
+		student.AgeOverride = false
+		student.AgeOverrideReason = nil
+	case (errors.As(err, &ageErr) || errors.Is(err, ErrTanggalLahirRequired)) && student.AgeOverride:
+	default:
 		return err
 	}
+	return nil
+}
 
+// recheckAgeLimit enforces the age limit of the student's batch again after
+// an edit, so changing the birth date or batch cannot get around it. It
+// reports failures as field errors.
+func (s *studentService) recheckAgeLimit(student *model.Student) error {
+	if student.BatchId == nil {
+		return nil
+	}
+	batch, err := s.batchRepo.GetByID(*student.BatchId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return FieldErrors{"batch_id": validation.NewFieldError(validation.MsgBatchNotFound)}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := enforceAgeLimit(student, batch); err != nil {
+		if fieldErrs, ok := StudentErrorFields(err); ok {
+			return fieldErrs
+		}
+		return err
+	}
 	return nil
 }
 
 func (s *studentService) RegisterPPDB(student *model.Student) error {
-	var parentCreated bool
-	if student.Parent != nil {
-		if err := s.parentRepo.Create(student.Parent); err != nil {
-			return err
-		}
-		parentCreated = true
-		student.ParentId = &student.Parent.ID
+	// Overrides are an admin decision, never part of the public form
+	student.AgeOverride = false
+	student.AgeOverrideReason = nil
+
+	if err := resolveStudentRegions(s.regionRepo, student, nil); err != nil {
+		return err
+	}
+	if err := applyDistance(student, nil); err != nil {
+		return err
 	}
 
 	// check if there is an active batch
 	activeBatch, err := s.batchRepo.GetActiveBatch()
 	if err != nil {
-		if parentCreated && student.Parent != nil {
-			_ = s.parentRepo.Delete(student.Parent.ID)
-		}
 		return errors.New("mohon maaf, tidak ada gelombang pendaftaran yang aktif saat ini")
 	}
 
@@ -84,41 +271,63 @@ func (s *studentService) RegisterPPDB(student *model.Student) error {
 	now := time.Now()
 
 	if activeBatch.StartDate == nil || activeBatch.EndDate == nil {
-		// If dates are null but it is active, maybe we allow it? 
+		// If dates are null but it is active, maybe we allow it?
 		// ORIGINAL ERROR was "batch has invalid..." so I will keep stricter check OR relax it if user wants to fix the data.
 		// User said: "batchnya berdasarkan yang aktif".
 		// I'll assume dates MUST be valid for PPDB.
-		if parentCreated && student.Parent != nil {
-			_ = s.parentRepo.Delete(student.Parent.ID)
-		}
 		return errors.New("konfigurasi gelombang pendaftaran tidak valid (tanggal mulai/selesai belum diatur)")
 	}
 
 	if now.Before(*activeBatch.StartDate) {
-		if parentCreated && student.Parent != nil {
-			_ = s.parentRepo.Delete(student.Parent.ID)
-		}
 		return errors.New("pendaftaran belum dibuka")
 	}
-	
+
 	if now.After(*activeBatch.EndDate) {
-		if parentCreated && student.Parent != nil {
-			_ = s.parentRepo.Delete(student.Parent.ID)
-		}
 		return errors.New("pendaftaran sudah ditutup")
 	}
 
+	if err := checkAgeEligibility(student, activeBatch); err != nil {
+		return err
+	}
+
 	student.BatchId = &activeBatch.ID
 	student.Batch = nil
 
-	if err := s.studentRepo.Create(student); err != nil {
-		if parentCreated && student.Parent != nil {
-			_ = s.parentRepo.Delete(student.Parent.ID)
+	// Anyone can submit the public form, so knowing a family's phone number
+	// must not attach the applicant to that family. Matches show up in the
+	// parent duplicate report for an admin to merge.
+	return s.createWithParent(student, false)
+}
+
+// createWithParent stores the parent (if any) and the student in a single
+// transaction, so a failing student insert never leaves an orphan parent.
+// With reuseParent, a parent already known by phone, email or NIK is reused
+// for siblings.
+func (s *studentService) createWithParent(student *model.Student, reuseParent bool) error {
+	return s.uow.Transaction(func(tx *repository.Tx) error {
+		return createInTx(tx, student, reuseParent)
+	})
+}
+
+func createInTx(tx *repository.Tx, student *model.Student, reuseParent bool) error {
+	if student.Parent != nil {
+		parent := student.Parent
+		if reuseParent {
+			var err error
+			if parent, err = findOrCreateParent(tx.Parents(), parent); err != nil {
+				return err
+			}
+		} else {
+			normalizeParentContacts(parent)
+			if err := tx.Parents().Create(parent); err != nil {
+				return err
+			}
 		}
-		return err
+		student.Parent = parent
+		student.ParentId = &parent.ID
 	}
 
-	return nil
+	return tx.Students().Create(student)
 }
 
 func (s *studentService) GetStudentByID(id int) (*model.Student, error) {
@@ -126,24 +335,542 @@ func (s *studentService) GetStudentByID(id int) (*model.Student, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	if student.ParentId != nil {
+		siblings, err := s.studentRepo.GetSiblings(student.ID, *student.ParentId)
+		if err != nil {
+			return nil, err
+		}
+		for _, sibling := range siblings {
+			item := model.Sibling{
+				ID:           sibling.ID,
+				FullName:     sibling.FullName,
+				TanggalLahir: sibling.TanggalLahir,
+				Nis:          sibling.Nis,
+				IsAccepted:   sibling.IsAccepted,
+				BatchId:      sibling.BatchId,
+			}
+			if sibling.Batch != nil {
+				item.BatchName = &sibling.Batch.Name
+			}
+			student.Siblings = append(student.Siblings, item)
+		}
+	}
+
 	return student, nil
 }
 
-func (s *studentService) GetAllStudents(limit int, page int, q string, batchID *int, isAccepted *bool) ([]model.Student, error) {
-	return s.studentRepo.GetAll(limit, page, q, batchID, isAccepted)
+func (s *studentService) GetAllStudents(page model.PageRequest, filter model.StudentFilter) ([]model.Student, *model.PageMeta, error) {
+	return s.studentRepo.GetAll(page, filter)
 }
 
 func (s *studentService) UpdateStudent(id int, student *model.Student) error {
-	if err := s.studentRepo.Update(id, student); err != nil {
+	existing, err := s.studentRepo.GetByID(id)
+	if err != nil {
 		return err
 	}
 
+	keepUnmasked(&student.Phone, existing.Phone, model.MaskPhone)
+	if student.Parent != nil && existing.Parent != nil {
+		if student.Parent.Version == 0 {
+			return FieldErrors{"parent_version": validation.NewFieldError(validation.MsgParentVersionRequired)}
+		}
+		keepUnmasked(&student.Parent.NoHpOrtuWali, existing.Parent.NoHpOrtuWali, model.MaskPhone)
+	}
+
+	if err := resolveStudentRegions(s.regionRepo, student, existing); err != nil {
+		return err
+	}
+	if err := applyDistance(student, existing); err != nil {
+		return err
+	}
+
+	if !sameDate(student.TanggalLahir, existing.TanggalLahir) || !sameID(student.BatchId, existing.BatchId) ||
+		student.AgeOverride != existing.AgeOverride {
+		if err := s.recheckAgeLimit(student); err != nil {
+			return err
+		}
+	}
+
+	return s.uow.Transaction(func(tx *repository.Tx) error {
+		return tx.Students().Update(id, student)
+	})
+}
+
+// PatchStudent applies a merge patch. Region names and the distance to the
+// school are derived again when codes or coordinates are patched.
+func (s *studentService) PatchStudent(id int, version int, patch Patch) (*model.Student, error) {
+	existing, err := s.studentRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req := model.NewStudentRequest(existing)
+	if err := applyPatch(&req, patch, model.StudentPatchFields); err != nil {
+		return nil, err
+	}
+
+	student := req.ToModel()
+	keepUnmasked(&student.Phone, existing.Phone, model.MaskPhone)
+	fields := patch.Fields()
+
+	if patch.Has("nik", "nisn", "tanggal_lahir", "gender") {
+		if errs := validation.StudentIdentity(student); len(errs) > 0 {
+			return nil, FieldErrors(errs)
+		}
+	}
+
+	if patch.Has("provinsi_kode", "kabupaten_kode", "kecamatan_kode", "desa_kelurahan_kode") {
+		if err := resolveStudentRegions(s.regionRepo, student, nil); err != nil {
+			return nil, err
+		}
+		for _, f := range studentRegionFields {
+			fields = append(fields, f.field, strings.TrimSuffix(f.field, "_kode"))
+		}
+	}
+
+	if patch.Has("latitude", "longitude") {
+		if err := applyDistance(student, nil); err != nil {
+			return nil, err
+		}
+		fields = append(fields, "distance_km")
+	}
+
+	if patch.Has("tanggal_lahir", "batch_id", "age_override") {
+		if err := s.recheckAgeLimit(student); err != nil {
+			return nil, err
+		}
+		fields = append(fields, "age_override", "age_override_reason")
+	}
+
+	if err := s.studentRepo.Patch(id, version, patchedColumns(student, fields)); err != nil {
+		return nil, err
+	}
+	return s.GetStudentByID(id)
+}
+
+// DeleteStudent moves the student to the trash, together with its parent
+// when the student was the parent's only child.
+func (s *studentService) DeleteStudent(id int) (*model.DeleteImpact, error) {
+	var impact *model.DeleteImpact
+	err := s.uow.Transaction(func(tx *repository.Tx) error {
+		var err error
+		impact, err = planStudentDelete(tx.Students(), tx.Parents(), id)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Students().Delete(id); err != nil {
+			return err
+		}
+		for _, parentID := range impact.TrashedParentIDs {
+			if err := tx.Parents().Delete(parentID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return impact, nil
+}
+
+func (s *studentService) PreviewDeleteStudent(id int) (*model.DeleteImpact, error) {
+	return planStudentDelete(s.studentRepo, s.parentRepo, id)
+}
+
+// planStudentDelete works out what deleting a student affects. Its parent
+// goes along when no sibling is left, and stays for the siblings otherwise.
+func planStudentDelete(students repository.StudentRepository, parents repository.ParentRepository, id int) (*model.DeleteImpact, error) {
+	student, err := students.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	impact := model.NewDeleteImpact()
+	impact.TrashedStudents = model.NewStudentSummaries([]model.Student{*student})
+
+	if student.Parent != nil {
+		count, err := parents.CountStudents(student.Parent.ID)
+		if err != nil {
+			return nil, err
+		}
+		if count <= 1 {
+			impact.TrashedParentIDs = append(impact.TrashedParentIDs, student.Parent.ID)
+		} else {
+			impact.KeptParentIDs = append(impact.KeptParentIDs, student.Parent.ID)
+		}
+	}
+	return impact, nil
+}
+
+func (s *studentService) GetTrashedStudents(page model.PageRequest) ([]model.Student, *model.PageMeta, error) {
+	return s.studentRepo.GetTrash(page)
+}
+
+// RestoreStudent takes the student out of the trash, and its parent too when
+// the parent was trashed along with it.
+func (s *studentService) RestoreStudent(id int) (*model.Student, error) {
+	err := s.uow.Transaction(func(tx *repository.Tx) error {
+		if err := tx.Students().Restore(id); err != nil {
+			return err
+		}
+
+		student, err := tx.Students().GetByID(id)
+		if err != nil {
+			return err
+		}
+		if student.BatchId != nil && student.Batch == nil {
+			return ErrStudentBatchTrashed
+		}
+		if student.ParentId != nil && student.Parent == nil {
+			return tx.Parents().Restore(*student.ParentId)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetStudentByID(id)
+}
+
+func (s *studentService) PurgeStudent(id int) error {
+	return s.studentRepo.Purge(id)
+}
+
+func (s *studentService) EnrollStudents(ids []int, tahunMasuk string) (*EnrollResult, error) {
+	startYear, err := parseTahunMasuk(tahunMasuk)
+	if err != nil {
+		return nil, err
+	}
+	if tahunMasuk == "" {
+		tahunMasuk = fmt.Sprintf("%d/%d", startYear, startYear+1)
+	}
+
+	pattern := os.Getenv("NIS_PATTERN")
+	if pattern == "" {
+		pattern = DefaultNISPattern
+	}
+	if err := checkNISPattern(pattern); err != nil {
+		return nil, err
+	}
+
+	result := &EnrollResult{Enrolled: []model.StudentResponse{}, Failed: map[int]error{}}
+	now := time.Now()
+
+	for _, id := range ids {
+		student, err := s.studentRepo.GetByID(id)
+		if err != nil {
+			result.Failed[id] = err
+			continue
+		}
+		if !student.IsAccepted {
+			result.Failed[id] = ErrStudentNotAccepted
+			continue
+		}
+		if student.EnrolledAt != nil {
+			result.Failed[id] = ErrStudentEnrolled
+			continue
+		}
+
+		// The sequence is taken in the same transaction that stores the NIS,
+		// so a failed enrollment does not use up a number.
+		var nis string
+		var enrollErr error
+		err = s.uow.Transaction(func(tx *repository.Tx) error {
+			seq, err := tx.Students().NextNISSequence(startYear)
+			if err != nil {
+				return err
+			}
+
+			nis = FormatNIS(pattern, startYear, seq)
+			if enrollErr = tx.Students().Enroll(id, nis, tahunMasuk, now); enrollErr != nil {
+				return enrollErr
+			}
+			return nil
+		})
+		if enrollErr != nil {
+			result.Failed[id] = enrollErr
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		student.Nis = &nis
+		student.TahunMasuk = &tahunMasuk
+		student.EnrolledAt = &now
+		result.Enrolled = append(result.Enrolled, model.NewStudentResponse(student))
+	}
+
+	return result, nil
+}
+
+func (s *studentService) GetEnrolledStudents(page model.PageRequest, q string, tahunMasuk string) ([]model.Student, *model.PageMeta, error) {
+	return s.studentRepo.GetEnrolled(page, q, tahunMasuk)
+}
+
+// uniqueIDs drops repeated IDs, keeping the first occurrence of each.
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// checkNISPattern rejects a pattern that would repeat numbers: it needs a
+// {SEQ} token, and a year token because the sequence restarts every year.
+func checkNISPattern(pattern string) error {
+	hasYear := strings.Contains(pattern, "{YYYY}") || strings.Contains(pattern, "{YY}") ||
+		strings.Contains(pattern, "{NEXTYY}")
+	if !nisSequenceTokenPattern.MatchString(pattern) || !hasYear {
+		return FieldErrors{"nis_pattern": validation.NewFieldError(validation.MsgNISPattern)}
+	}
 	return nil
 }
 
-func (s *studentService) DeleteStudent(id int) error {
-	if err := s.studentRepo.Delete(id); err != nil {
-		return err
+// FormatNIS expands a NIS pattern. Supported tokens are {YYYY} and {YY} for the
+// academic start year, {NEXTYY} for the end year and {SEQ:n} for the sequence
+// zero-padded to n digits.
+func FormatNIS(pattern string, startYear int, seq int) string {
+	replacer := strings.NewReplacer(
+		"{YYYY}", strconv.Itoa(startYear),
+		"{YY}", fmt.Sprintf("%02d", startYear%100),
+		"{NEXTYY}", fmt.Sprintf("%02d", (startYear+1)%100),
+	)
+	nis := replacer.Replace(pattern)
+
+	return nisSequenceTokenPattern.ReplaceAllStringFunc(nis, func(token string) string {
+		width := 0
+		if m := nisSequenceTokenPattern.FindStringSubmatch(token); m[1] != "" {
+			width, _ = strconv.Atoi(m[1])
+		}
+		return fmt.Sprintf("%0*d", width, seq)
+	})
+}
+
+// parseTahunMasuk returns the start year of an academic year such as 2025/2026.
+// An empty value means the academic year starting this calendar year.
+func parseTahunMasuk(tahunMasuk string) (int, error) {
+	if tahunMasuk == "" {
+		return time.Now().Year(), nil
 	}
+
+	m := tahunMasukPattern.FindStringSubmatch(tahunMasuk)
+	if m == nil {
+		return 0, ErrInvalidTahunMasuk
+	}
+
+	start, _ := strconv.Atoi(m[1])
+	end, _ := strconv.Atoi(m[2])
+	if end != start+1 {
+		return 0, ErrInvalidTahunMasuk
+	}
+
+	return start, nil
+}
+
+func (s *studentService) GetAgeOverrides(batchID *int) ([]model.AgeOverrideReport, error) {
+	students, err := s.studentRepo.GetAgeOverrides(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]model.AgeOverrideReport, 0, len(students))
+	for _, student := range students {
+		report := model.AgeOverrideReport{Student: model.NewStudentResponse(&student)}
+		if student.Batch != nil {
+			ref := student.Batch.AgeReference()
+			report.ReferenceDate = &ref
+			report.MaxAge = student.Batch.MaxAge
+			if student.TanggalLahir != nil && !student.TanggalLahir.IsZero() {
+				age := student.TanggalLahir.AgeAt(ref)
+				report.Age = &age
+			}
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+func sameDate(a, b *model.Date) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(b.Time)
+}
+
+func sameID(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// checkAgeEligibility enforces the batch maximum age, if the batch has one.
+func checkAgeEligibility(student *model.Student, batch *model.Batch) error {
+	if batch.MaxAge == nil {
+		return nil
+	}
+
+	if student.TanggalLahir == nil || student.TanggalLahir.IsZero() {
+		return ErrTanggalLahirRequired
+	}
+
+	ref := batch.AgeReference()
+	age := student.TanggalLahir.AgeAt(ref)
+	if age > *batch.MaxAge {
+		return &AgeLimitError{Age: age, MaxAge: *batch.MaxAge, ReferenceDate: ref}
+	}
+
 	return nil
 }
+
+// RankByDistance ranks a batch's applicants by distance and classifies them
+// into the batch zonasi rings. Applicants without coordinates are not ranked.
+func (s *studentService) RankByDistance(batchID int) ([]model.ZonasiRank, error) {
+	batch, err := s.batchRepo.GetByID(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := s.studentRepo.GetRankedByDistance(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	ranks := make([]model.ZonasiRank, 0, len(students))
+	for i, student := range students {
+		rank := model.ZonasiRank{
+			Rank:       i + 1,
+			Student:    model.NewStudentResponse(&student),
+			DistanceKm: *student.DistanceKm,
+		}
+		if len(batch.ZonaRadiusKm) > 0 {
+			zona := ClassifyZona(*student.DistanceKm, batch.ZonaRadiusKm)
+			score := ZonasiScore(*student.DistanceKm, batch.ZonaRadiusKm)
+			rank.Zona = &zona
+			rank.Score = &score
+		}
+		ranks = append(ranks, rank)
+	}
+
+	return ranks, nil
+}
+
+func (s *studentService) FindDuplicateStudents(batchID int, threshold float64) ([]model.DuplicateCandidate, error) {
+	students, err := s.studentRepo.GetAllByBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Anonymized records share one name and would all match each other
+	candidates := make([]model.Student, 0, len(students))
+	for _, student := range students {
+		if student.AnonymizedAt == nil {
+			candidates = append(candidates, student)
+		}
+	}
+
+	return FindDuplicates(candidates, threshold), nil
+}
+
+// MergeStudents merges duplicate applicant records into one. The kept record
+// is keepID, or else the most complete one; its blank fields (documents
+// included) are filled from the others, which are then deleted together with
+// parents no other student uses.
+func (s *studentService) MergeStudents(ids []int, keepID *int) (*model.Student, error) {
+	// A student listed twice is merged once.
+	ids = uniqueIDs(ids)
+	if len(ids) < 2 {
+		return nil, ErrMergeTooFewStudents
+	}
+
+	var keptID int
+	err := s.uow.Transaction(func(tx *repository.Tx) error {
+		studentsRepo, parentsRepo := tx.Students(), tx.Parents()
+
+		students, err := studentsRepo.GetByIDs(ids)
+		if err != nil {
+			return err
+		}
+		if len(students) != len(ids) {
+			return errors.New("one or more students were not found")
+		}
+
+		keep := -1
+		for i := range students {
+			switch {
+			case keepID != nil:
+				if students[i].ID == *keepID {
+					keep = i
+				}
+			case keep < 0 || countFilled(&students[i]) > countFilled(&students[keep]):
+				keep = i
+			}
+		}
+		if keep < 0 {
+			return ErrMergeKeepNotListed
+		}
+
+		primary := students[keep]
+		keptID = primary.ID
+
+		var duplicateParentIDs []int
+		for i := range students {
+			if i == keep {
+				continue
+			}
+			other := &students[i]
+			fillBlanks(&primary, other)
+
+			if other.Parent != nil && primary.Parent != nil && other.Parent.ID != primary.Parent.ID {
+				fillBlanks(primary.Parent, other.Parent)
+				duplicateParentIDs = append(duplicateParentIDs, other.Parent.ID)
+			}
+			if primary.Parent == nil && other.Parent != nil {
+				primary.Parent = other.Parent
+				primary.ParentId = &other.Parent.ID
+			}
+
+			// Deleted first so unique NIK/NISN can move to the kept record
+			if err := studentsRepo.DeletePermanently(other.ID); err != nil {
+				return err
+			}
+		}
+
+		if primary.Parent != nil {
+			parent := *primary.Parent
+			parent.Students = nil
+			if err := parentsRepo.Update(parent.ID, &parent); err != nil {
+				return err
+			}
+		}
+
+		primary.Parent, primary.Batch = nil, nil
+		if err := studentsRepo.Update(primary.ID, &primary); err != nil {
+			return err
+		}
+
+		if len(duplicateParentIDs) > 0 {
+			// Siblings of the removed records stay with the kept parent
+			if err := studentsRepo.ReassignParent(duplicateParentIDs, *primary.ParentId); err != nil {
+				return err
+			}
+			return parentsRepo.DeleteMany(duplicateParentIDs)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetStudentByID(keptID)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"os"
+	"project_sdu/model"
+	"project_sdu/repository"
+	"strconv"
+	"time"
+)
+
+// DefaultTrashRetentionDays is how long deleted records can still be restored.
+const DefaultTrashRetentionDays = 30
+
+type TrashService interface {
+	PurgeExpired() (*model.TrashPurgeResult, error)
+}
+
+type trashService struct {
+	studentRepo repository.StudentRepository
+	parentRepo  repository.ParentRepository
+	batchRepo   repository.BatchRepository
+	postRepo    repository.PostRepository
+}
+
+func NewTrashService(studentRepo repository.StudentRepository, parentRepo repository.ParentRepository, batchRepo repository.BatchRepository, postRepo repository.PostRepository) TrashService {
+	return &trashService{studentRepo, parentRepo, batchRepo, postRepo}
+}
+
+// PurgeExpired permanently deletes records trashed longer than
+// TRASH_RETENTION_DAYS ago. Students go first so that their parents and
+// batches are no longer referenced.
+func (s *trashService) PurgeExpired() (*model.TrashPurgeResult, error) {
+	before := time.Now().AddDate(0, 0, -trashRetentionDays())
+	result := &model.TrashPurgeResult{Before: before}
+
+	var err error
+	if result.Students, err = s.studentRepo.PurgeDeletedBefore(before); err != nil {
+		return nil, err
+	}
+	if result.Parents, err = s.parentRepo.PurgeDeletedBefore(before); err != nil {
+		return nil, err
+	}
+	if result.Batches, err = s.batchRepo.PurgeDeletedBefore(before); err != nil {
+		return nil, err
+	}
+	if result.Posts, err = s.postRepo.PurgeDeletedBefore(before); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func trashRetentionDays() int {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		return DefaultTrashRetentionDays
+	}
+	return days
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"os"
+	"project_sdu/model"
+	"project_sdu/validation"
+	"strconv"
+)
+
+const earthRadiusKm = 6371.0
+
+// ZonaLuar is the zone of applicants living beyond the last zonasi ring.
+const ZonaLuar = 0
+
+var ErrSchoolLocationNotSet = errors.New("SCHOOL_LATITUDE and SCHOOL_LONGITUDE are not configured")
+
+// HaversineKm returns the great-circle distance between two coordinates in km.
+func HaversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// SchoolLocation reads the school coordinates from SCHOOL_LATITUDE and SCHOOL_LONGITUDE.
+func SchoolLocation() (lat float64, lon float64, err error) {
+	lat, errLat := strconv.ParseFloat(os.Getenv("SCHOOL_LATITUDE"), 64)
+	lon, errLon := strconv.ParseFloat(os.Getenv("SCHOOL_LONGITUDE"), 64)
+	if errLat != nil || errLon != nil {
+		return 0, 0, ErrSchoolLocationNotSet
+	}
+	return lat, lon, nil
+}
+
+// ClassifyZona returns the 1-based zone a distance falls in, or ZonaLuar.
+// Rings are expected in ascending order.
+func ClassifyZona(distanceKm float64, rings []float64) int {
+	for i, radius := range rings {
+		if distanceKm <= radius {
+			return i + 1
+		}
+	}
+	return ZonaLuar
+}
+
+// ZonasiScore maps a distance to 0-100, where 100 is at the school gate and 0
+// is at or beyond the outermost ring. It can be weighted into selection ranking.
+func ZonasiScore(distanceKm float64, rings []float64) float64 {
+	if len(rings) == 0 {
+		return 0
+	}
+
+	outer := rings[len(rings)-1]
+	if outer <= 0 || distanceKm >= outer {
+		return 0
+	}
+	return math.Round((1-distanceKm/outer)*10000) / 100
+}
+
+// applyDistance validates the home coordinates and stores the distance to the
+// school. Coordinates missing on the update are taken from existing.
+func applyDistance(student *model.Student, existing *model.Student) error {
+	lat, lon := student.Latitude, student.Longitude
+	if existing != nil {
+		if lat == nil {
+			lat = existing.Latitude
+		}
+		if lon == nil {
+			lon = existing.Longitude
+		}
+	}
+
+	if lat == nil && lon == nil {
+		return nil
+	}
+
+	errs := FieldErrors{}
+	if lat == nil || *lat < -90 || *lat > 90 {
+		errs["latitude"] = validation.NewFieldError(validation.MsgLatitudeRange)
+	}
+	if lon == nil || *lon < -180 || *lon > 180 {
+		errs["longitude"] = validation.NewFieldError(validation.MsgLongitudeRange)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	schoolLat, schoolLon, err := SchoolLocation()
+	if err != nil {
+		// Coordinates are still stored; distances can be filled in later with
+		// the recompute-distances command once the school location is set.
+		return nil
+	}
+
+	distance := math.Round(HaversineKm(*lat, *lon, schoolLat, schoolLon)*1000) / 1000
+	student.DistanceKm = &distance
+	return nil
+}
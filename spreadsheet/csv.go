@@ -0,0 +1,47 @@
+// Package spreadsheet reads and writes the CSV and XLSX files exchanged
+// with the committee, using only the standard library.
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// utf8BOM starts CSV files saved by Excel.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ReadCSV reads all rows of a CSV file. Excel with Indonesian regional
+// settings separates values with semicolons; the separator is guessed from
+// the first line.
+func ReadCSV(r io.Reader) ([][]string, error) {
+	br := bufio.NewReader(r)
+	if start, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(start, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(br)
+	first, _ := br.Peek(br.Buffered())
+	line, _, _ := strings.Cut(string(first), "\n")
+	if strings.Count(line, ";") > strings.Count(line, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+
+	return reader.ReadAll()
+}
+
+// WriteCSV starts a CSV file with a BOM, so Excel opens it as UTF-8.
+func WriteCSV(w io.Writer) (*csv.Writer, error) {
+	if _, err := w.Write(utf8BOM); err != nil {
+		return nil, err
+	}
+	return csv.NewWriter(w), nil
+}
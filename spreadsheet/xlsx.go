@@ -0,0 +1,427 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidXLSX  = errors.New("file is not a valid XLSX workbook")
+	ErrXLSXTooLarge = errors.New("the workbook is too large")
+	ErrTooManyRows  = errors.New("the sheet has too many rows")
+)
+
+const (
+	// maxPartSize caps the uncompressed size of each XML part read, so a
+	// small upload cannot unpack into gigabytes
+	maxPartSize = 64 << 20
+	// maxColumns is the column limit of Excel, XFD
+	maxColumns = 16384
+)
+
+// ReadXLSX reads all rows of the first sheet. Row i of the result is row
+// i+1 of the sheet, so empty rows are kept as empty slices. Cells formatted
+// as dates are returned as YYYY-MM-DD. A sheet with a row past maxRows fails
+// with ErrTooManyRows before that row is allocated.
+func ReadXLSX(r io.ReaderAt, size int64, maxRows int) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidXLSX
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var strs []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if strs, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+
+	var dateStyles map[int]bool
+	if f, ok := files["xl/styles.xml"]; ok {
+		if dateStyles, err = readDateStyles(f); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, ok := files[sheetPath]
+	if !ok {
+		return nil, ErrInvalidXLSX
+	}
+	return readSheet(sheet, strs, dateStyles, maxRows)
+}
+
+// decodeXML decodes one part of the workbook. The size in the zip header is
+// only a claim, so the part is also cut off at maxPartSize while reading.
+func decodeXML(f *zip.File, v interface{}) error {
+	if f.UncompressedSize64 > maxPartSize {
+		return ErrXLSXTooLarge
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v); err != nil {
+		return ErrInvalidXLSX
+	}
+	return nil
+}
+
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	workbookFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", ErrInvalidXLSX
+	}
+	var workbook struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeXML(workbookFile, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", ErrInvalidXLSX
+	}
+
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return "", ErrInvalidXLSX
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeXML(relsFile, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", ErrInvalidXLSX
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	var sst struct {
+		Items []struct {
+			T    *string `xml:"t"`
+			Runs []struct {
+				T string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := decodeXML(f, &sst); err != nil {
+		return nil, err
+	}
+
+	strs := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		if item.T != nil {
+			strs[i] = *item.T
+			continue
+		}
+		var b strings.Builder
+		for _, run := range item.Runs {
+			b.WriteString(run.T)
+		}
+		strs[i] = b.String()
+	}
+	return strs, nil
+}
+
+// builtinDateFormats are the number format IDs Excel reserves for dates.
+var builtinDateFormats = map[int]bool{
+	14: true, 15: true, 16: true, 17: true, 18: true, 19: true, 20: true, 21: true, 22: true,
+	27: true, 30: true, 36: true, 45: true, 46: true, 47: true, 50: true, 57: true,
+}
+
+// readDateStyles finds the cell styles that show a date.
+func readDateStyles(f *zip.File) (map[int]bool, error) {
+	var styles struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if err := decodeXML(f, &styles); err != nil {
+		return nil, err
+	}
+
+	dateFormats := map[int]bool{}
+	for id := range builtinDateFormats {
+		dateFormats[id] = true
+	}
+	for _, numFmt := range styles.NumFmts {
+		if isDateFormat(numFmt.Code) {
+			dateFormats[numFmt.ID] = true
+		}
+	}
+
+	dateStyles := map[int]bool{}
+	for i, xf := range styles.CellXfs {
+		if dateFormats[xf.NumFmtID] {
+			dateStyles[i] = true
+		}
+	}
+	return dateStyles, nil
+}
+
+// isDateFormat tells whether a custom number format shows a date, ignoring
+// quoted text and colors such as [Red].
+func isDateFormat(code string) bool {
+	inQuote, inBracket := false, false
+	for _, r := range strings.ToLower(code) {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case r == '[':
+			inBracket = true
+		case r == ']':
+			inBracket = false
+		case inBracket:
+		case r == 'd' || r == 'm' || r == 'y':
+			return true
+		}
+	}
+	return false
+}
+
+// excelEpoch is day zero of the 1900 date system, as Excel counts it.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+func readSheet(f *zip.File, strs []string, dateStyles map[int]bool, maxRows int) ([][]string, error) {
+	var sheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string  `xml:"r,attr"`
+				Type   string  `xml:"t,attr"`
+				Style  int     `xml:"s,attr"`
+				Value  string  `xml:"v"`
+				Inline *string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodeXML(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, row := range sheet.Rows {
+		index := len(rows)
+		if row.R > 0 {
+			index = row.R - 1
+		}
+		if index >= maxRows {
+			return nil, ErrTooManyRows
+		}
+		for len(rows) <= index {
+			rows = append(rows, nil)
+		}
+
+		var cells []string
+		for _, cell := range row.Cells {
+			col := len(cells)
+			if cell.Ref != "" {
+				col = columnIndex(cell.Ref)
+			}
+			if col < 0 || col >= maxColumns {
+				return nil, ErrInvalidXLSX
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				i, err := strconv.Atoi(cell.Value)
+				if err != nil || i < 0 || i >= len(strs) {
+					return nil, ErrInvalidXLSX
+				}
+				cells[col] = strs[i]
+			case "inlineStr":
+				if cell.Inline != nil {
+					cells[col] = *cell.Inline
+				}
+			case "b":
+				cells[col] = map[string]string{"1": "true", "0": "false"}[cell.Value]
+			case "", "n":
+				cells[col] = cell.Value
+				if serial, err := strconv.ParseFloat(cell.Value, 64); err == nil && dateStyles[cell.Style] {
+					cells[col] = excelEpoch.AddDate(0, 0, int(math.Floor(serial))).Format("2006-01-02")
+				}
+			default:
+				cells[col] = cell.Value
+			}
+		}
+		rows[index] = cells
+	}
+	return rows, nil
+}
+
+// columnIndex turns the letters of a cell reference such as "AB12" into a
+// zero-based column index. It returns -1 for a reference without letters or
+// past the last Excel column.
+func columnIndex(ref string) int {
+	col := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		letters++
+		if col > maxColumns {
+			return -1
+		}
+	}
+	if letters == 0 {
+		return -1
+	}
+	return col - 1
+}
+
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// XLSXWriter writes a single sheet workbook row by row, so large sheets are
+// never held in memory. Every cell is text.
+type XLSXWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	rows  int
+}
+
+// NewXLSXWriter starts a workbook with one sheet. Its first columns cells
+// are formatted as text, so NIK and NISN typed into a template keep their
+// digits.
+func NewXLSXWriter(w io.Writer, sheetName string, columns int) (*XLSXWriter, error) {
+	zw := zip.NewWriter(w)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + escape(sheetName) + `" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+			`</Relationships>`},
+		// Style 1 is the built-in text format "@"
+		{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+			`<xf numFmtId="49" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>` +
+			`</styleSheet>`},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	// The sheet goes last, as it stays open while rows are written
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	header := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`
+	if columns > 0 {
+		header += fmt.Sprintf(`<cols><col min="1" max="%d" width="20" style="1" customWidth="1"/></cols>`, columns)
+	}
+	header += `<sheetData>`
+	if _, err := io.WriteString(sheet, header); err != nil {
+		return nil, err
+	}
+
+	return &XLSXWriter{zw: zw, sheet: sheet}, nil
+}
+
+func (x *XLSXWriter) WriteRow(cells []string) error {
+	x.rows++
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, x.rows)
+	for i, cell := range cells {
+		if cell == "" {
+			continue
+		}
+		fmt.Fprintf(&b, `<c r="%s%d" t="inlineStr" s="1"><is><t xml:space="preserve">%s</t></is></c>`,
+			columnName(i), x.rows, escape(cell))
+	}
+	b.WriteString(`</row>`)
+
+	_, err := io.WriteString(x.sheet, b.String())
+	return err
+}
+
+// Close finishes the sheet and the workbook; it does not close the
+// underlying writer.
+func (x *XLSXWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,131 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"project_sdu/model"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// enum is implemented by the string enums in the model package.
+type enum interface {
+	IsValid() bool
+}
+
+// RegisterBindings installs the custom binding tags on gin's validator:
+//
+//	enum     the value must be one of the constants of its model enum type
+//	notblank a string that is not only whitespace
+//	nik      a structurally valid NIK (birth date and gender are not cross-checked)
+//	nisn     a 10-digit NISN
+//
+// Field errors are reported by JSON name instead of Go field name.
+func RegisterBindings() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("validation: unexpected binding engine")
+	}
+
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return f.Name
+		}
+		return name
+	})
+
+	validators := map[string]validator.Func{
+		"enum": func(fl validator.FieldLevel) bool {
+			value, ok := fl.Field().Interface().(enum)
+			return ok && value.IsValid()
+		},
+		"notblank": func(fl validator.FieldLevel) bool {
+			return strings.TrimSpace(fl.Field().String()) != ""
+		},
+		"nik": func(fl validator.FieldLevel) bool {
+			return ValidateNIK(strings.TrimSpace(fl.Field().String()), nil, "") == nil
+		},
+		"nisn": func(fl validator.FieldLevel) bool {
+			return ValidateNISN(strings.TrimSpace(fl.Field().String())) == nil
+		},
+	}
+	for tag, fn := range validators {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BindingErrors turns an error from ShouldBindJSON into field errors keyed
+// by JSON field name, with messages in the given language.
+func BindingErrors(err error, lang Lang) map[string]string {
+	errs := make(map[string]string)
+	CollectBindingErrors(err, "", lang, errs)
+	return errs
+}
+
+// CollectBindingErrors adds the field errors of err to errs, with every key
+// prefixed, e.g. "[2]." for the third element of a list.
+func CollectBindingErrors(err error, prefix string, lang Lang, errs map[string]string) {
+	var (
+		fieldErrs validator.ValidationErrors
+		typeErr   *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &fieldErrs):
+		for _, fe := range fieldErrs {
+			field := fieldPath(fe.Namespace())
+			errs[prefix+field] = fieldMessage(lang, fe.Tag(), lastSegment(field), fe.Param())
+		}
+
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		errs[prefix+typeErr.Field] = fieldMessage(lang, "type", lastSegment(typeErr.Field), "")
+
+	case errors.Is(err, model.ErrInvalidDate):
+		errs[prefix+"tanggal_lahir"] = Message(err, lang)
+
+	default:
+		errs[prefix+"body"] = Text(lang, "invalid_json")
+	}
+}
+
+// fieldPath drops the Go type names the validator puts into a namespace, the
+// root struct and embedded structs, e.g. StudentRequest.PPDBRequest.parent.father_nik
+// becomes parent.father_nik. JSON names in this API are all snake_case.
+func fieldPath(namespace string) string {
+	var path []string
+	for _, segment := range strings.Split(namespace, ".") {
+		if segment != "" && unicode.IsUpper(rune(segment[0])) {
+			continue
+		}
+		path = append(path, segment)
+	}
+	return strings.Join(path, ".")
+}
+
+func lastSegment(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// IsBindingError reports whether err comes from decoding or validating a
+// request body, i.e. whether BindingErrors can describe it.
+func IsBindingError(err error) bool {
+	var (
+		fieldErrs validator.ValidationErrors
+		typeErr   *json.UnmarshalTypeError
+	)
+	return errors.As(err, &fieldErrs) || errors.As(err, &typeErr) || errors.Is(err, model.ErrInvalidDate)
+}
@@ -0,0 +1,153 @@
+package validation
+
+import (
+	"errors"
+	"project_sdu/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNIKLength         = errors.New("NIK harus terdiri dari 16 digit angka")
+	ErrNIKRegion         = errors.New("kode wilayah pada NIK tidak valid")
+	ErrNIKBirthDate      = errors.New("tanggal lahir pada NIK tidak valid")
+	ErrNIKSequence       = errors.New("nomor urut pada NIK tidak valid")
+	ErrNIKDateMismatch   = errors.New("tanggal lahir pada NIK tidak sesuai dengan tanggal lahir")
+	ErrNIKGenderMismatch = errors.New("jenis kelamin pada NIK tidak sesuai dengan jenis kelamin")
+	ErrNISNFormat        = errors.New("NISN harus terdiri dari 10 digit angka")
+)
+
+// provinceCodes are the two-digit Kemendagri province codes a NIK can start with.
+var provinceCodes = map[string]bool{
+	"11": true, "12": true, "13": true, "14": true, "15": true, "16": true, "17": true, "18": true, "19": true,
+	"21": true, "31": true, "32": true, "33": true, "34": true, "35": true, "36": true,
+	"51": true, "52": true, "53": true,
+	"61": true, "62": true, "63": true, "64": true, "65": true,
+	"71": true, "72": true, "73": true, "74": true, "75": true, "76": true,
+	"81": true, "82": true,
+	"91": true, "92": true, "93": true, "94": true, "95": true, "96": true, "97": true,
+}
+
+// NIKInfo is what can be read from a structurally valid NIK.
+type NIKInfo struct {
+	ProvinceCode string
+	RegencyCode  string
+	DistrictCode string
+	BirthDay     int
+	BirthMonth   int
+	BirthYear    int // two digits, the century is not encoded
+	Gender       model.Gender
+}
+
+// ParseNIK checks the structure of a NIK: 6 digit region code, birth date as
+// DDMMYY (day + 40 for females) and a non-zero 4 digit sequence.
+func ParseNIK(nik string) (*NIKInfo, error) {
+	if len(nik) != 16 || !isDigits(nik) {
+		return nil, ErrNIKLength
+	}
+
+	if !provinceCodes[nik[0:2]] || nik[2:4] == "00" || nik[4:6] == "00" {
+		return nil, ErrNIKRegion
+	}
+
+	day, _ := strconv.Atoi(nik[6:8])
+	month, _ := strconv.Atoi(nik[8:10])
+	year, _ := strconv.Atoi(nik[10:12])
+
+	gender := model.Male
+	if day > 40 {
+		gender = model.Female
+		day -= 40
+	}
+
+	if month < 1 || month > 12 || day < 1 || day > daysIn(month, year) {
+		return nil, ErrNIKBirthDate
+	}
+
+	if nik[12:16] == "0000" {
+		return nil, ErrNIKSequence
+	}
+
+	return &NIKInfo{
+		ProvinceCode: nik[0:2],
+		RegencyCode:  nik[0:4],
+		DistrictCode: nik[0:6],
+		BirthDay:     day,
+		BirthMonth:   month,
+		BirthYear:    year,
+		Gender:       gender,
+	}, nil
+}
+
+// ValidateNIK checks the NIK structure and cross-checks it against the birth
+// date and gender given on the form. Empty birth date or gender skip the
+// corresponding cross-check.
+func ValidateNIK(nik string, tanggalLahir *model.Date, gender model.Gender) error {
+	info, err := ParseNIK(nik)
+	if err != nil {
+		return err
+	}
+
+	if tanggalLahir != nil && !tanggalLahir.IsZero() {
+		if tanggalLahir.Day() != info.BirthDay ||
+			int(tanggalLahir.Month()) != info.BirthMonth ||
+			tanggalLahir.Year()%100 != info.BirthYear {
+			return ErrNIKDateMismatch
+		}
+	}
+
+	if gender != "" && gender != info.Gender {
+		return ErrNIKGenderMismatch
+	}
+
+	return nil
+}
+
+// ValidateNISN checks the NISN format: exactly 10 digits.
+func ValidateNISN(nisn string) error {
+	if len(nisn) != 10 || !isDigits(nisn) || nisn == "0000000000" {
+		return ErrNISNFormat
+	}
+	return nil
+}
+
+// StudentIdentity validates the NIK and NISN of an applicant and returns
+// field-level errors keyed by JSON field name. Missing values are not errors.
+func StudentIdentity(student *model.Student) map[string]error {
+	errs := make(map[string]error)
+
+	if student.Nik != nil && strings.TrimSpace(*student.Nik) != "" {
+		nik := strings.TrimSpace(*student.Nik)
+		student.Nik = &nik
+		if err := ValidateNIK(nik, student.TanggalLahir, student.Gender); err != nil {
+			errs["nik"] = err
+		}
+	}
+
+	if student.Nisn != nil && strings.TrimSpace(*student.Nisn) != "" {
+		nisn := strings.TrimSpace(*student.Nisn)
+		student.Nisn = &nisn
+		if err := ValidateNISN(nisn); err != nil {
+			errs["nisn"] = err
+		}
+	}
+
+	return errs
+}
+
+func daysIn(month int, twoDigitYear int) int {
+	// The century is unknown, so a leap year is assumed whenever the two
+	// digits allow it; 29 February is then accepted for both centuries.
+	year := 2000 + twoDigitYear
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
@@ -0,0 +1,303 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"project_sdu/model"
+	"strings"
+	"unicode"
+)
+
+// Lang is the language of validation messages.
+type Lang string
+
+const (
+	LangID Lang = "id"
+	LangEN Lang = "en"
+)
+
+// ParseLang picks the first supported language of an Accept-Language header,
+// e.g. "en-US,en;q=0.9,id;q=0.8" yields LangEN.
+func ParseLang(acceptLanguage string, fallback Lang) Lang {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		switch {
+		case strings.HasPrefix(tag, "id"), strings.HasPrefix(tag, "in"):
+			return LangID
+		case strings.HasPrefix(tag, "en"):
+			return LangEN
+		}
+	}
+	return fallback
+}
+
+// texts are the messages per binding tag. %[1]s is the field label, %[2]s
+// the tag parameter.
+var texts = map[Lang]map[string]string{
+	LangID: {
+		"validation_failed": "Validasi gagal",
+		"invalid_json":      "Format JSON tidak valid",
+		"age_limit":         "Mohon maaf, usia calon peserta didik tidak memenuhi syarat",
+		"age_override":      "Usia calon peserta didik melebihi batas gelombang, isi age_override untuk tetap mendaftarkan",
+		"slug_empty":        "Judul harus mengandung huruf atau angka",
+		"required":          "%[1]s wajib diisi",
+		"notblank":          "%[1]s wajib diisi",
+		"enum":              "%[1]s tidak valid",
+		"oneof":             "%[1]s harus salah satu dari: %[2]s",
+		"email":             "Format %[1]s tidak valid",
+		"min":               "%[1]s minimal %[2]s",
+		"max":               "%[1]s maksimal %[2]s",
+		"gte":               "%[1]s minimal %[2]s",
+		"lte":               "%[1]s maksimal %[2]s",
+		"gt":                "%[1]s harus lebih dari %[2]s",
+		"lt":                "%[1]s harus kurang dari %[2]s",
+		"len":               "%[1]s harus %[2]s karakter",
+		"numeric":           "%[1]s harus berupa angka",
+		"gtfield":           "%[1]s harus setelah %[2]s",
+		"nik":               "%[1]s harus 16 digit NIK yang valid",
+		"nisn":              "%[1]s harus terdiri dari 10 digit angka",
+		"type":              "Tipe data %[1]s tidak valid",
+		"default":           "%[1]s tidak valid",
+	},
+	LangEN: {
+		"validation_failed": "Validation failed",
+		"invalid_json":      "Invalid JSON format",
+		"age_limit":         "Sorry, the applicant does not meet the age requirement",
+		"age_override":      "Applicant exceeds the batch age limit, set age_override to register anyway",
+		"slug_empty":        "Title must contain letters or digits",
+		"required":          "%[1]s is required",
+		"notblank":          "%[1]s is required",
+		"enum":              "%[1]s is not a valid option",
+		"oneof":             "%[1]s must be one of: %[2]s",
+		"email":             "%[1]s must be a valid email address",
+		"min":               "%[1]s must be at least %[2]s",
+		"max":               "%[1]s must be at most %[2]s",
+		"gte":               "%[1]s must be at least %[2]s",
+		"lte":               "%[1]s must be at most %[2]s",
+		"gt":                "%[1]s must be greater than %[2]s",
+		"lt":                "%[1]s must be less than %[2]s",
+		"len":               "%[1]s must be %[2]s characters long",
+		"numeric":           "%[1]s must be a number",
+		"gtfield":           "%[1]s must be after %[2]s",
+		"nik":               "%[1]s must be a valid 16-digit NIK",
+		"nisn":              "%[1]s must be 10 digits",
+		"type":              "%[1]s has an invalid type",
+		"default":           "%[1]s is invalid",
+	},
+}
+
+// labels are human-readable field names. Unlisted fields fall back to the
+// JSON name with underscores replaced by spaces.
+var labels = map[Lang]map[string]string{
+	LangID: {
+		"full_name": "Nama lengkap", "fullname": "Nama lengkap", "gender": "Jenis kelamin",
+		"agama": "Agama", "tanggal_lahir": "Tanggal lahir", "tempat_lahir": "Tempat lahir",
+		"nik": "NIK", "nisn": "NISN", "email": "Email", "password": "Password", "phone": "Nomor HP",
+		"blood_type": "Golongan darah", "tinggal_bersama": "Tinggal bersama",
+		"status_keluarga": "Status keluarga", "keadaan_ortu": "Keadaan orang tua",
+		"father_name": "Nama ayah", "mother_name": "Nama ibu", "wali_name": "Nama wali",
+		"father_nik": "NIK ayah", "mother_nik": "NIK ibu", "wali_nik": "NIK wali",
+		"parent_email": "Email orang tua", "no_hp_ortu_wali": "Nomor HP orang tua/wali",
+		"alamat_jalan": "Alamat", "berat_kg": "Berat badan", "tinggi_cm": "Tinggi badan",
+		"kartu_keluarga": "Kartu keluarga", "akta_kelahiran": "Akta kelahiran",
+		"title": "Judul", "content": "Konten", "category": "Kategori",
+		"name": "Nama", "jalur": "Jalur", "start_date": "Tanggal mulai", "end_date": "Tanggal selesai",
+		"question": "Pertanyaan", "answer": "Jawaban", "description": "Deskripsi",
+	},
+	LangEN: {
+		"full_name": "Full name", "fullname": "Fullname", "gender": "Gender",
+		"agama": "Religion", "tanggal_lahir": "Date of birth", "tempat_lahir": "Place of birth",
+		"nik": "NIK", "nisn": "NISN", "email": "Email", "password": "Password", "phone": "Phone",
+		"blood_type": "Blood type", "tinggal_bersama": "Lives with",
+		"status_keluarga": "Family status", "keadaan_ortu": "Parents' status",
+		"father_name": "Father's name", "mother_name": "Mother's name", "wali_name": "Guardian's name",
+		"father_nik": "Father's NIK", "mother_nik": "Mother's NIK", "wali_nik": "Guardian's NIK",
+		"parent_email": "Parent email", "no_hp_ortu_wali": "Parent/guardian phone",
+		"alamat_jalan": "Address", "berat_kg": "Weight", "tinggi_cm": "Height",
+		"kartu_keluarga": "Family card", "akta_kelahiran": "Birth certificate",
+		"title": "Title", "content": "Content", "category": "Category",
+		"name": "Name", "jalur": "Admission track", "start_date": "Start date", "end_date": "End date",
+		"question": "Question", "answer": "Answer", "description": "Description",
+	},
+}
+
+// Codes of the field errors of the services and repositories. The messages
+// are in fieldMessages; those with verbs take the FieldError arguments.
+const (
+	MsgNIKExists             = "nik_exists"
+	MsgNISNExists            = "nisn_exists"
+	MsgNISExists             = "nis_exists"
+	MsgTanggalLahirRequired  = "tanggal_lahir_required"
+	MsgAgeLimit              = "age_limit"
+	MsgRegionNotFound        = "region_not_found"
+	MsgRegionLevel           = "region_level"
+	MsgRegionOutside         = "region_outside"
+	MsgLatitudeRange         = "latitude_range"
+	MsgLongitudeRange        = "longitude_range"
+	MsgDraftStepMissing      = "draft_step_missing"
+	MsgParentNameRequired    = "parent_name_required"
+	MsgBatchNotFound         = "batch_not_found"
+	MsgStudentNotAccepted    = "student_not_accepted"
+	MsgStudentEnrolled       = "student_enrolled"
+	MsgInvalidTahunMasuk     = "invalid_tahun_masuk"
+	MsgParentVersionRequired = "parent_version_required"
+	MsgNISPattern            = "nis_pattern"
+)
+
+// fieldMessages are the field error messages per code.
+var fieldMessages = map[Lang]map[string]string{
+	LangID: {
+		MsgNIKExists:             "NIK sudah terdaftar",
+		MsgNISNExists:            "NISN sudah terdaftar",
+		MsgNISExists:             "NIS sudah terdaftar",
+		MsgTanggalLahirRequired:  "tanggal lahir wajib diisi untuk pengecekan batas usia",
+		MsgAgeLimit:              "usia calon peserta didik %d tahun, melebihi batas usia maksimal %d tahun per tanggal %s",
+		MsgRegionNotFound:        "kode wilayah %s tidak ditemukan",
+		MsgRegionLevel:           "kode wilayah %s bukan kode %s",
+		MsgRegionOutside:         "%s tidak berada di %s",
+		MsgLatitudeRange:         "latitude harus di antara -90 dan 90",
+		MsgLongitudeRange:        "longitude harus di antara -180 dan 180",
+		MsgDraftStepMissing:      "Langkah ini belum diisi",
+		MsgParentNameRequired:    "Isi minimal nama ayah, ibu atau wali",
+		MsgBatchNotFound:         "gelombang tidak ditemukan",
+		MsgStudentNotAccepted:    "calon peserta didik belum diterima",
+		MsgStudentEnrolled:       "peserta didik sudah didaftarkan ulang",
+		MsgInvalidTahunMasuk:     "tahun_masuk harus seperti 2025/2026",
+		MsgParentVersionRequired: "parent_version wajib diisi saat mengubah data orang tua",
+		MsgNISPattern:            "NIS_PATTERN harus memuat {SEQ} dan token tahun seperti {YY}",
+	},
+	LangEN: {
+		MsgNIKExists:             "NIK is already registered",
+		MsgNISNExists:            "NISN is already registered",
+		MsgNISExists:             "NIS is already registered",
+		MsgTanggalLahirRequired:  "date of birth is required to check the age limit",
+		MsgAgeLimit:              "the applicant is %d years old, above the maximum age of %d on %s",
+		MsgRegionNotFound:        "region code %s was not found",
+		MsgRegionLevel:           "region code %s is not a %s code",
+		MsgRegionOutside:         "%s is not in %s",
+		MsgLatitudeRange:         "latitude must be between -90 and 90",
+		MsgLongitudeRange:        "longitude must be between -180 and 180",
+		MsgDraftStepMissing:      "This step has not been filled in",
+		MsgParentNameRequired:    "Fill in at least the father's, mother's or guardian's name",
+		MsgBatchNotFound:         "batch not found",
+		MsgStudentNotAccepted:    "student has not been accepted",
+		MsgStudentEnrolled:       "student is already enrolled",
+		MsgInvalidTahunMasuk:     "tahun_masuk must look like 2025/2026",
+		MsgParentVersionRequired: "parent_version is required when editing the parent",
+		MsgNISPattern:            "NIS_PATTERN must contain {SEQ} and a year token such as {YY}",
+	},
+}
+
+// FieldError is a field error of a service or repository: a message code
+// and the values to fill in. It is turned into text once, in the language of
+// the request.
+type FieldError struct {
+	Code string
+	Args []interface{}
+}
+
+func NewFieldError(code string, args ...interface{}) *FieldError {
+	return &FieldError{Code: code, Args: args}
+}
+
+// Error returns the message in Indonesian.
+func (e *FieldError) Error() string {
+	return e.Format(LangID)
+}
+
+// Format returns the message in the given language.
+func (e *FieldError) Format(lang Lang) string {
+	format, ok := fieldMessages[lang][e.Code]
+	if !ok {
+		format = fieldMessages[LangID][e.Code]
+	}
+	return fmt.Sprintf(format, e.Args...)
+}
+
+// translations are the English versions of the Indonesian errors of this package.
+var translations = map[error]string{
+	ErrNIKLength:         "NIK must be 16 digits",
+	ErrNIKRegion:         "the region code of the NIK is invalid",
+	ErrNIKBirthDate:      "the birth date in the NIK is invalid",
+	ErrNIKSequence:       "the sequence number of the NIK is invalid",
+	ErrNIKDateMismatch:   "the birth date in the NIK does not match tanggal_lahir",
+	ErrNIKGenderMismatch: "the gender in the NIK does not match gender",
+	ErrNISNFormat:        "NISN must be 10 digits",
+	model.ErrInvalidDate: "invalid date, use YYYY-MM-DD or DD-MM-YYYY",
+}
+
+// Message returns err in the given language: errors with a Format method,
+// such as FieldError, are formatted, and the errors of this package are
+// translated. Other errors are returned as they are.
+func Message(err error, lang Lang) string {
+	var formatter interface{ Format(lang Lang) string }
+	if errors.As(err, &formatter) {
+		return formatter.Format(lang)
+	}
+	if lang == LangEN {
+		for known, translated := range translations {
+			if errors.Is(err, known) {
+				return translated
+			}
+		}
+	}
+	return err.Error()
+}
+
+// Localize turns field errors, e.g. of StudentIdentity or of the services,
+// into messages in the given language.
+func Localize(errs map[string]error, lang Lang) map[string]string {
+	messages := make(map[string]string, len(errs))
+	for field, err := range errs {
+		messages[field] = Message(err, lang)
+	}
+	return messages
+}
+
+// Text returns a fixed message such as "validation_failed".
+func Text(lang Lang, key string) string {
+	if msg, ok := texts[lang][key]; ok {
+		return msg
+	}
+	return texts[LangEN][key]
+}
+
+func fieldMessage(lang Lang, tag string, field string, param string) string {
+	format, ok := texts[lang][tag]
+	if !ok {
+		format = texts[lang]["default"]
+	}
+	switch tag {
+	case "oneof":
+		param = strings.ReplaceAll(param, " ", ", ")
+	case "gtfield":
+		param = strings.ToLower(label(lang, snakeCase(param)))
+	}
+	return fmt.Sprintf(format, label(lang, field), param)
+}
+
+func label(lang Lang, field string) string {
+	if l, ok := labels[lang][field]; ok {
+		return l
+	}
+	l := strings.ReplaceAll(field, "_", " ")
+	if l == "" {
+		return l
+	}
+	return strings.ToUpper(l[:1]) + l[1:]
+}
+
+// snakeCase turns a Go field name such as StartDate into start_date.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}